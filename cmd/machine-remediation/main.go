@@ -2,22 +2,56 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+	osconfigv1 "github.com/openshift/api/config/v1"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1alpha1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/baremetal/remediator"
+	"kubevirt.io/machine-remediation/pkg/components"
 	"kubevirt.io/machine-remediation/pkg/controllers"
+	"kubevirt.io/machine-remediation/pkg/controllers/certificatesigningrequest"
+	"kubevirt.io/machine-remediation/pkg/controllers/externalremediation"
+	"kubevirt.io/machine-remediation/pkg/controllers/machinedisruptionbudget"
+	"kubevirt.io/machine-remediation/pkg/controllers/machinehealthcheck"
 	"kubevirt.io/machine-remediation/pkg/controllers/machineremediation"
+	"kubevirt.io/machine-remediation/pkg/controllers/machineremediationpolicy"
 	"kubevirt.io/machine-remediation/pkg/controllers/nodereboot"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	externalremediator "kubevirt.io/machine-remediation/pkg/external/remediator"
+	kubevirtremediator "kubevirt.io/machine-remediation/pkg/kubevirt/remediator"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/migration"
+	redfishremediator "kubevirt.io/machine-remediation/pkg/redfish/remediator"
+	"kubevirt.io/machine-remediation/pkg/utils/drain"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
+	"kubevirt.io/machine-remediation/pkg/utils/mapper"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+	"kubevirt.io/machine-remediation/pkg/utils/workloads"
 	"kubevirt.io/machine-remediation/pkg/version"
+	"kubevirt.io/machine-remediation/pkg/webhooks"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 )
 
 func printVersion() {
@@ -26,8 +60,192 @@ func printVersion() {
 	glog.Infof("Component version: %s", version.Get())
 }
 
+// newRemediatorsRegistry returns the registry with all remediators supported by the controller
+func newRemediatorsRegistry(infraKubeconfig string, redfishInsecure bool, externalEndpoint string, drainTimeout time.Duration, keepNode bool, etcdClient *etcd.Client, defaultTimeouts *timeouts.Defaults) (*machineremediation.Registry, error) {
+	registry := machineremediation.NewRegistry()
+	baremetal := func(mgr manager.Manager) (machineremediation.Remediator, error) {
+		// nodes are not drained before the power off when the drain timeout is not specified,
+		// unless the machine remediation policy of the machine enables the drain
+		drainer, err := drain.NewDrainer(mgr, drainTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		// nodes of powered off hosts are deleted, unless the controller should keep them
+		var releaser *workloads.Releaser
+		if keepNode {
+			releaser = workloads.NewReleaser(mgr)
+		}
+		return remediator.NewBareMetalRemediator(mgr, drainer, releaser, etcdClient, defaultTimeouts), nil
+	}
+	// the bare metal and redfish remediators start only once the cluster serves metal3 bare metal hosts
+	bareMetalHostGVK := bmov1.SchemeGroupVersion.WithKind("BareMetalHost")
+	baremetal = machineremediation.NewOptionalFactory("baremetal", baremetal, bareMetalHostGVK)
+	if err := registry.Register("baremetal", baremetal, osconfigv1.BareMetalPlatformType); err != nil {
+		return nil, err
+	}
+
+	redfish := func(mgr manager.Manager) (machineremediation.Remediator, error) {
+		return redfishremediator.NewRedfishRemediator(mgr, redfishInsecure, etcdClient, defaultTimeouts), nil
+	}
+	redfish = machineremediation.NewOptionalFactory("redfish", redfish, bareMetalHostGVK)
+	if err := registry.Register("redfish", redfish); err != nil {
+		return nil, err
+	}
+
+	kubevirt := func(mgr manager.Manager) (machineremediation.Remediator, error) {
+		// virtual machines run under the same cluster when the infra kubeconfig is not specified
+		if infraKubeconfig == "" {
+			return kubevirtremediator.NewKubeVirtRemediator(mgr, mgr.GetClient(), defaultTimeouts), nil
+		}
+
+		infraConfig, err := clientcmd.BuildConfigFromFlags("", infraKubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		infraClient, err := client.New(infraConfig, client.Options{})
+		if err != nil {
+			return nil, err
+		}
+		return kubevirtremediator.NewKubeVirtRemediator(mgr, infraClient, defaultTimeouts), nil
+	}
+	if err := registry.Register("kubevirt", kubevirt); err != nil {
+		return nil, err
+	}
+
+	external := func(mgr manager.Manager) (machineremediation.Remediator, error) {
+		if externalEndpoint == "" {
+			return nil, fmt.Errorf("the external remediator requires the --external-remediator-endpoint flag")
+		}
+		return externalremediator.NewExternalRemediator(mgr, externalEndpoint, defaultTimeouts), nil
+	}
+	if err := registry.Register("external", external); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// newEtcdClient returns the client to the etcd cluster membership API, or nil when etcd endpoints are not specified
+func newEtcdClient(endpoints string, caFile string, certFile string, keyFile string) (*etcd.Client, error) {
+	if endpoints == "" {
+		return nil, nil
+	}
+
+	tlsConfig, err := etcd.NewTLSConfig(caFile, certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return etcd.NewClient(strings.Split(endpoints, ","), tlsConfig)
+}
+
+// parseWebhookService parses the webhook service in the namespace/name format
+func parseWebhookService(webhookService string) (types.NamespacedName, error) {
+	parts := strings.Split(webhookService, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("the webhook service %q should have the namespace/name format", webhookService)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+// setupConversionWebhook serves the conversion webhook of the MachineRemediation CRD, configures the CRD
+// to use it and migrates stored objects to the storage version once the webhook server runs
+func setupConversionWebhook(mgr manager.Manager, c client.Client, service types.NamespacedName) error {
+	mgr.GetWebhookServer().Register(migration.ConversionWebhookPath, &conversion.Webhook{})
+
+	if err := migration.EnsureConversionWebhook(c, service); err != nil {
+		return err
+	}
+
+	// the migration reads objects through the conversion webhook, so retry it until the webhook server is up
+	migrate := func(stop <-chan struct{}) error {
+		return wait.PollImmediateUntil(10*time.Second, func() (bool, error) {
+			if err := migration.MigrateStorageVersion(c); err != nil {
+				glog.Warningf("Failed to migrate MachineRemediation objects to the storage version: %v", err)
+				return false, nil
+			}
+			return true, nil
+		}, stop)
+	}
+	return mgr.Add(manager.RunnableFunc(migrate))
+}
+
+// getServiceAccount returns the user name of the controller service account.
+// The controller deployment runs under the namespace of the webhook service.
+func getServiceAccount(namespace string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, components.ComponentMachineRemediation)
+}
+
+// setupAdmissionWebhooks serves the mutating and validating webhooks of MachineRemediation objects and
+// configures the API server to call them
+func setupAdmissionWebhooks(mgr manager.Manager, c client.Client, service types.NamespacedName, defaultTimeouts *timeouts.Defaults) error {
+	mgr.GetWebhookServer().Register(webhooks.MutatingWebhookPath, &webhook.Admission{
+		Handler: webhooks.NewMachineRemediationDefaulter(mgr.GetClient(), defaultTimeouts),
+	})
+	mgr.GetWebhookServer().Register(webhooks.ValidatingWebhookPath, &webhook.Admission{
+		Handler: webhooks.NewMachineRemediationValidator(mgr.GetClient(), mgr.GetAPIReader(), getServiceAccount(service.Namespace)),
+	})
+
+	if err := webhooks.EnsureMutatingWebhook(c, service); err != nil {
+		return err
+	}
+	return webhooks.EnsureValidatingWebhook(c, service)
+}
+
+// getMachineAPIFlavor returns the machine API flavor by its name, or detects it by the API server discovery
+func getMachineAPIFlavor(cfg *rest.Config, name string) (machineapi.Flavor, error) {
+	if name != "" {
+		return machineapi.ParseFlavor(name)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+	return machineapi.DetectFlavor(discoveryClient)
+}
+
+// newManagerClient returns the function that creates the manager client, like the default one the client
+// reads objects from the cache and writes them to the API server, and it serves machines of the machine API flavor
+func newManagerClient(flavor machineapi.Flavor) manager.NewClientFunc {
+	return func(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+		c, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+
+		delegatingClient := &client.DelegatingClient{
+			Reader: &client.DelegatingReader{
+				CacheReader:  cache,
+				ClientReader: c,
+			},
+			Writer:       c,
+			StatusClient: c,
+		}
+		return machineapi.NewClient(delegatingClient, cache, flavor), nil
+	}
+}
+
 func main() {
 	namespace := flag.String("namespace", "", "Namespace that the controller watches to reconcile objects. If unspecified, the controller watches for machine remediation objects across all namespaces.")
+	remediatorName := flag.String("remediator", "", "Remediator that the controller uses to remediate machines. If unspecified, the remediator is selected by the infrastructure platform of the cluster.")
+	redfishInsecure := flag.Bool("redfish-insecure", false, "Skip the verification of the BMC certificate by the redfish remediator.")
+	infraKubeconfig := flag.String("infra-kubeconfig", "", "Path to the kubeconfig of the infra cluster that runs KubeVirt virtual machines. If unspecified, the virtual machines are looked up under the same cluster.")
+	externalEndpoint := flag.String("external-remediator-endpoint", "", "URL of the external remediator that machine remediations are forwarded to by the external remediator adapter.")
+	drainTimeout := flag.Duration("drain-timeout", 0, "Time that the bare metal remediator waits for the drain of the node before the power off. If unspecified, nodes are not drained before the power off, unless the machine remediation policy enables the drain, otherwise the drain timeout extends the reboot timeout.")
+	keepNode := flag.Bool("keep-node", false, "Keep the node of the powered off bare metal host and release its workloads by the force deletion of pods and volume attachments, instead of the node deletion.")
+	etcdEndpoints := flag.String("etcd-endpoints", "", "Comma separated list of etcd endpoints that the bare metal remediator uses to remove the etcd member of the recreated control plane machine and to verify that the new member joins. If unspecified, the etcd membership is not changed by the recreate.")
+	etcdCAFile := flag.String("etcd-cacert", "", "Path to the CA bundle that verifies etcd server certificates.")
+	etcdCertFile := flag.String("etcd-cert", "", "Path to the client certificate that the controller presents to etcd.")
+	etcdKeyFile := flag.String("etcd-key", "", "Path to the key of the etcd client certificate.")
+	webhookService := flag.String("webhook-service", "", "Service in the namespace/name format that exposes the webhook server of the controller. If unspecified, the conversion, mutating and validating webhooks are not served and MachineRemediation objects are not migrated to the storage version.")
+	webhookPort := flag.Int("webhook-port", 9443, "Port that the webhook server serves at.")
+	webhookCertDir := flag.String("webhook-cert-dir", "/etc/machine-remediation/webhook-certs", "Directory that contains the tls.crt and tls.key files of the webhook server.")
+	rebootTimeout := flag.Duration("reboot-timeout", timeouts.DefaultReboot, "Time that the reboot remediation can take before it fails, unless the machine remediation specifies its own total timeout.")
+	recreateTimeout := flag.Duration("recreate-timeout", timeouts.DefaultRecreate, "Time that the recreate remediation can take before it fails, unless the machine remediation specifies its own total timeout.")
+	powerOffTimeout := flag.Duration("power-off-timeout", timeouts.DefaultPowerOff, "Time that the reboot remediation waits for the power off of the host.")
+	bootTimeout := flag.Duration("boot-timeout", timeouts.DefaultBoot, "Time that the reboot remediation waits for the power on of the host.")
+	machineAPI := flag.String("machine-api", "", "Machine API flavor of the cluster, openshift for machine.openshift.io machines or cluster-api for upstream cluster.x-k8s.io machines with Metal3Machines. If unspecified, the flavor is detected by API groups that the API server serves.")
+	nodeReadyTimeout := flag.Duration("node-ready-timeout", timeouts.DefaultNodeReady, "Time that the reboot remediation waits for the node to become ready after the power on of the host.")
 	flag.Parse()
 
 	printVersion()
@@ -38,9 +256,19 @@ func main() {
 		glog.Fatal(err)
 	}
 
+	flavor, err := getMachineAPIFlavor(cfg, *machineAPI)
+	if err != nil {
+		glog.Fatalf("Failed to get the machine API flavor: %v", err)
+	}
+
 	opts := manager.Options{
 		LeaderElection:   true,
 		LeaderElectionID: "machine-remediation",
+		Port:             *webhookPort,
+		CertDir:          *webhookCertDir,
+		NewClient:        newManagerClient(flavor),
+		// the dynamic REST mapper discovers kinds of CRDs installed after the start, like metal3 bare metal hosts
+		MapperProvider: mapper.NewDynamicRESTMapper,
 	}
 	if *namespace != "" {
 		opts.LeaderElectionNamespace = *namespace
@@ -57,23 +285,103 @@ func main() {
 	glog.Infof("Registering Components.")
 
 	// Setup Scheme for all resources
+	if err := mrv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		glog.Fatal(err)
+	}
 	if err := mrv1.AddToScheme(mgr.GetScheme()); err != nil {
 		glog.Fatal(err)
 	}
+	if err := apiextensionsv1beta1.AddToScheme(mgr.GetScheme()); err != nil {
+		glog.Fatal(err)
+	}
 	if err := mapiv1.AddToScheme(mgr.GetScheme()); err != nil {
 		glog.Fatal(err)
 	}
 	if err := bmov1.SchemeBuilder.AddToScheme(mgr.GetScheme()); err != nil {
 		glog.Fatal(err)
 	}
+	if err := osconfigv1.AddToScheme(mgr.GetScheme()); err != nil {
+		glog.Fatal(err)
+	}
+
+	// Setup field indexes of the manager cache, that controllers use to list objects by field values
+	if err := indexes.AddToManager(mgr); err != nil {
+		glog.Fatal(err)
+	}
+	if flavor == machineapi.FlavorOpenShift {
+		if err := indexes.AddMachineIndexesToManager(mgr); err != nil {
+			glog.Fatal(err)
+		}
+	}
+
+	defaultTimeouts := &timeouts.Defaults{
+		Reboot:    *rebootTimeout,
+		Recreate:  *recreateTimeout,
+		PowerOff:  *powerOffTimeout,
+		Boot:      *bootTimeout,
+		NodeReady: *nodeReadyTimeout,
+		Drain:     *drainTimeout,
+	}
+
+	etcdClient, err := newEtcdClient(*etcdEndpoints, *etcdCAFile, *etcdCertFile, *etcdKeyFile)
+	if err != nil {
+		glog.Fatalf("Failed to create etcd client: %v", err)
+	}
+
+	registry, err := newRemediatorsRegistry(*infraKubeconfig, *redfishInsecure, *externalEndpoint, *drainTimeout, *keepNode, etcdClient, defaultTimeouts)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	// The manager cache does not start until the manager starts, so use the direct client
+	// to detect the infrastructure platform
+	directClient, err := client.New(cfg, client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		glog.Fatal(err)
+	}
+	c := machineapi.NewClient(directClient, directClient, flavor)
 
-	remediator := remediator.NewBareMetalRemediator(mgr)
+	if *webhookService != "" {
+		service, err := parseWebhookService(*webhookService)
+		if err != nil {
+			glog.Fatal(err)
+		}
+		if err := setupConversionWebhook(mgr, c, service); err != nil {
+			glog.Fatalf("Failed to setup the conversion webhook: %v", err)
+		}
+		if err := setupAdmissionWebhooks(mgr, c, service, defaultTimeouts); err != nil {
+			glog.Fatalf("Failed to setup the admission webhooks: %v", err)
+		}
+	}
+
+	remediator, err := registry.NewRemediator(mgr, c, *remediatorName)
+	if err != nil {
+		glog.Fatalf("Failed to create remediator: %v", err)
+	}
 	addController := func(m manager.Manager, opts manager.Options) error {
-		return machineremediation.AddWithRemediator(m, remediator, opts)
+		return machineremediation.AddWithRemediator(m, remediator, flavor, etcdClient, opts)
+	}
+
+	// controllers that watch machines watch them under the machine API flavor
+	withFlavor := func(add func(manager.Manager, machineapi.Flavor, manager.Options) error) func(manager.Manager, manager.Options) error {
+		return func(m manager.Manager, opts manager.Options) error {
+			return add(m, flavor, opts)
+		}
 	}
 
 	// Setup all Controllers
-	if err := controllers.AddToManager(mgr, opts, addController, nodereboot.Add); err != nil {
+	addFuncs := []func(manager.Manager, manager.Options) error{
+		addController,
+		nodereboot.Add,
+		certificatesigningrequest.Add,
+		withFlavor(machineremediationpolicy.Add),
+		withFlavor(machinehealthcheck.Add),
+		withFlavor(machinedisruptionbudget.Add),
+	}
+	if flavor == machineapi.FlavorClusterAPI {
+		addFuncs = append(addFuncs, externalremediation.Add)
+	}
+	if err := controllers.AddToManager(mgr, opts, addFuncs...); err != nil {
 		glog.Fatal(err)
 	}
 
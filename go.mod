@@ -16,6 +16,7 @@ require (
 	go.uber.org/zap v1.10.0 // indirect
 	golang.org/x/tools v0.0.0-20190706070813-72ffa07ba3db // indirect
 	k8s.io/api v0.0.0-20190918195907-bd6ac527cfd2
+	k8s.io/apiextensions-apiserver v0.0.0-20190918201827-3de75813f604
 	k8s.io/apimachinery v0.0.0-20190913080033-27d36303b655
 	k8s.io/client-go v11.0.1-0.20190409021438-1a26190bd76a+incompatible
 	k8s.io/code-generator v0.0.0-20190912054826-cd179ad6a269
@@ -23,6 +24,7 @@ require (
 	sigs.k8s.io/cluster-api v0.1.4
 	sigs.k8s.io/controller-runtime v0.3.1-0.20191016212439-2df793d02076
 	sigs.k8s.io/controller-tools v0.2.2-0.20190919191502-76a25b63325a
+	sigs.k8s.io/testing_frameworks v0.1.1
 )
 
 replace (
@@ -0,0 +1,169 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	"kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// annotationV1beta1Status contains the annotation that keeps v1beta1 status fields,
+	// which v1alpha1 does not have, so the conversion from v1alpha1 back to v1beta1 does not lose them
+	annotationV1beta1Status = "machineremediation.kubevirt.io/v1beta1-status"
+	// annotationV1beta1Timeouts contains the annotation that keeps v1beta1 spec timeouts,
+	// which v1alpha1 does not have
+	annotationV1beta1Timeouts = "machineremediation.kubevirt.io/v1beta1-timeouts"
+	// annotationV1beta1Strategies contains the annotation that keeps v1beta1 spec strategies,
+	// which v1alpha1 does not have
+	annotationV1beta1Strategies = "machineremediation.kubevirt.io/v1beta1-strategies"
+	// annotationV1beta1SavedTaints contains the annotation that keeps v1beta1 spec saved taints,
+	// which v1alpha1 does not have
+	annotationV1beta1SavedTaints = "machineremediation.kubevirt.io/v1beta1-saved-taints"
+)
+
+// v1beta1Status contains v1beta1 status fields that v1alpha1 does not have
+type v1beta1Status struct {
+	ObservedGeneration   int64                                 `json:"observedGeneration,omitempty"`
+	Reason               v1beta1.RemediationReason             `json:"reason,omitempty"`
+	StateTransitionTime  *metav1.Time                          `json:"stateTransitionTime,omitempty"`
+	NodeBootID           string                                `json:"nodeBootID,omitempty"`
+	Conditions           []v1beta1.MachineRemediationCondition `json:"conditions,omitempty"`
+	Attempts             []v1beta1.RemediationAttempt          `json:"attempts,omitempty"`
+	ApprovedCertificates []v1beta1.ApprovedCertificate         `json:"approvedCertificates,omitempty"`
+}
+
+// ConvertTo converts this MachineRemediation to the hub version
+func (src *MachineRemediation) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.MachineRemediation)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec.Type = v1beta1.RemediationType(src.Spec.Type)
+	dst.Spec.MachineName = src.Spec.MachineName
+	dst.Spec.SavedLabels = src.Spec.SavedLabels
+	dst.Spec.SavedAnnotations = src.Spec.SavedAnnotations
+
+	dst.Status.State = v1beta1.RemediationState(src.Status.State)
+	dst.Status.Message = src.Status.Reason
+	dst.Status.StartTime = src.Status.StartTime
+	dst.Status.EndTime = src.Status.EndTime
+
+	// restore fields that were lost on the conversion to v1alpha1
+	if data, ok := src.Annotations[annotationV1beta1Timeouts]; ok {
+		timeouts := &v1beta1.RemediationTimeouts{}
+		if err := json.Unmarshal([]byte(data), timeouts); err != nil {
+			return err
+		}
+		dst.Spec.Timeouts = timeouts
+		delete(dst.Annotations, annotationV1beta1Timeouts)
+	}
+
+	if data, ok := src.Annotations[annotationV1beta1Strategies]; ok {
+		strategies := []v1beta1.RemediationStrategy{}
+		if err := json.Unmarshal([]byte(data), &strategies); err != nil {
+			return err
+		}
+		dst.Spec.Strategies = strategies
+		delete(dst.Annotations, annotationV1beta1Strategies)
+	}
+
+	if data, ok := src.Annotations[annotationV1beta1SavedTaints]; ok {
+		taints := []corev1.Taint{}
+		if err := json.Unmarshal([]byte(data), &taints); err != nil {
+			return err
+		}
+		dst.Spec.SavedTaints = taints
+		delete(dst.Annotations, annotationV1beta1SavedTaints)
+	}
+
+	if data, ok := src.Annotations[annotationV1beta1Status]; ok {
+		status := &v1beta1Status{}
+		if err := json.Unmarshal([]byte(data), status); err != nil {
+			return err
+		}
+		dst.Status.ObservedGeneration = status.ObservedGeneration
+		dst.Status.Reason = status.Reason
+		dst.Status.StateTransitionTime = status.StateTransitionTime
+		dst.Status.NodeBootID = status.NodeBootID
+		dst.Status.Conditions = status.Conditions
+		dst.Status.Attempts = status.Attempts
+		dst.Status.ApprovedCertificates = status.ApprovedCertificates
+		delete(dst.Annotations, annotationV1beta1Status)
+	}
+
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version to this version
+func (dst *MachineRemediation) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.MachineRemediation)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec.Type = RemediationType(src.Spec.Type)
+	dst.Spec.MachineName = src.Spec.MachineName
+	dst.Spec.SavedLabels = src.Spec.SavedLabels
+	dst.Spec.SavedAnnotations = src.Spec.SavedAnnotations
+
+	dst.Status.State = RemediationState(src.Status.State)
+	dst.Status.Reason = src.Status.Message
+	dst.Status.StartTime = src.Status.StartTime
+	dst.Status.EndTime = src.Status.EndTime
+
+	// keep fields that v1alpha1 does not have under annotations
+	if src.Spec.Timeouts != nil {
+		if err := setAnnotation(dst, annotationV1beta1Timeouts, src.Spec.Timeouts); err != nil {
+			return err
+		}
+	}
+
+	if len(src.Spec.Strategies) != 0 {
+		if err := setAnnotation(dst, annotationV1beta1Strategies, src.Spec.Strategies); err != nil {
+			return err
+		}
+	}
+
+	if len(src.Spec.SavedTaints) != 0 {
+		if err := setAnnotation(dst, annotationV1beta1SavedTaints, src.Spec.SavedTaints); err != nil {
+			return err
+		}
+	}
+
+	status := &v1beta1Status{
+		ObservedGeneration:   src.Status.ObservedGeneration,
+		Reason:               src.Status.Reason,
+		StateTransitionTime:  src.Status.StateTransitionTime,
+		NodeBootID:           src.Status.NodeBootID,
+		Conditions:           src.Status.Conditions,
+		Attempts:             src.Status.Attempts,
+		ApprovedCertificates: src.Status.ApprovedCertificates,
+	}
+	if status.ObservedGeneration == 0 && status.Reason == "" && status.StateTransitionTime == nil &&
+		status.NodeBootID == "" && len(status.Conditions) == 0 && len(status.Attempts) == 0 &&
+		len(status.ApprovedCertificates) == 0 {
+		return nil
+	}
+	return setAnnotation(dst, annotationV1beta1Status, status)
+}
+
+// setAnnotation sets the annotation of the machine remediation to the JSON representation of the value
+func setAnnotation(mr *MachineRemediation, annotation string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	if mr.Annotations == nil {
+		mr.Annotations = map[string]string{}
+	}
+	mr.Annotations[annotation] = string(data)
+	return nil
+}
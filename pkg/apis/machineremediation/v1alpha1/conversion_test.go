@@ -0,0 +1,136 @@
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConversion(t *testing.T) {
+	startTime := metav1.NewTime(time.Now().Truncate(time.Second))
+
+	hubWithConditions := &v1beta1.MachineRemediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mr",
+			Namespace:   "default",
+			Generation:  2,
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: v1beta1.MachineRemediationSpec{
+			Type:        v1beta1.RemediationTypeReboot,
+			MachineName: "machine",
+			SavedLabels: map[string]string{"label": "value"},
+			Timeouts: &v1beta1.RemediationTimeouts{
+				Boot: &metav1.Duration{Duration: 20 * time.Minute},
+			},
+			Strategies: []v1beta1.RemediationStrategy{
+				{Type: v1beta1.RemediationTypeReboot},
+				{Type: v1beta1.RemediationTypeRecreate},
+			},
+			SavedTaints: []corev1.Taint{
+				{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+			},
+		},
+		Status: v1beta1.MachineRemediationStatus{
+			ObservedGeneration:  2,
+			State:               v1beta1.RemediationStateFailed,
+			Reason:              v1beta1.RemediationReasonTimedOut,
+			Message:             "Reboot failed on timeout",
+			StartTime:           &startTime,
+			StateTransitionTime: &startTime,
+			EndTime:             &startTime,
+			NodeBootID:          "1e4fb8c3-2ad4-4d2a-9d1e-6a0b2f3c9d7e",
+			Conditions: []v1beta1.MachineRemediationCondition{
+				{
+					Type:               v1beta1.MachineRemediationConditionSucceeded,
+					Status:             corev1.ConditionFalse,
+					LastTransitionTime: startTime,
+					Reason:             v1beta1.RemediationReasonTimedOut,
+				},
+			},
+			Attempts: []v1beta1.RemediationAttempt{
+				{
+					Type:      v1beta1.RemediationTypeReboot,
+					State:     v1beta1.RemediationStateFailed,
+					Reason:    v1beta1.RemediationReasonTimedOut,
+					StartTime: &startTime,
+					EndTime:   &startTime,
+				},
+			},
+			ApprovedCertificates: []v1beta1.ApprovedCertificate{
+				{
+					Name:         "csr-8vzlp",
+					NodeName:     "node",
+					MachineName:  "machine",
+					Type:         v1beta1.CertificateTypeClient,
+					ApprovalTime: startTime,
+				},
+			},
+		},
+	}
+
+	hubWithoutConditions := &v1beta1.MachineRemediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mr",
+			Namespace: "default",
+		},
+		Spec: v1beta1.MachineRemediationSpec{
+			Type:        v1beta1.RemediationTypeRecreate,
+			MachineName: "machine",
+		},
+		Status: v1beta1.MachineRemediationStatus{
+			State:     v1beta1.RemediationStateProvisioning,
+			Message:   "Recreate in progress",
+			StartTime: &startTime,
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		hub                 *v1beta1.MachineRemediation
+		expectedReason      string
+		expectedAnnotations int
+	}{
+		{
+			name:                "with v1beta1 status fields",
+			hub:                 hubWithConditions,
+			expectedReason:      "Reboot failed on timeout",
+			expectedAnnotations: 5,
+		},
+		{
+			name:                "without v1beta1 status fields",
+			hub:                 hubWithoutConditions,
+			expectedReason:      "Recreate in progress",
+			expectedAnnotations: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		spoke := &MachineRemediation{}
+		if err := spoke.ConvertFrom(tc.hub.DeepCopy()); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if spoke.Status.Reason != tc.expectedReason {
+			t.Errorf("%s failed, expected v1alpha1 reason: %q, got: %q", tc.name, tc.expectedReason, spoke.Status.Reason)
+		}
+
+		if len(spoke.Annotations) != tc.expectedAnnotations {
+			t.Errorf("%s failed, expected %d v1alpha1 annotations, got: %v", tc.name, tc.expectedAnnotations, spoke.Annotations)
+		}
+
+		hub := &v1beta1.MachineRemediation{}
+		if err := spoke.ConvertTo(hub); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if !reflect.DeepEqual(hub, tc.hub) {
+			t.Errorf("%s failed, expected round trip object: %+v, got: %+v", tc.name, tc.hub, hub)
+		}
+	}
+}
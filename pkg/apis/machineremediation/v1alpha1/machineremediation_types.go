@@ -20,10 +20,18 @@ type RemediationState string
 const (
 	// RemediationStateStarted contains remediation state when the machine remediation object was created
 	RemediationStateStarted RemediationState = "Started"
+	// RemediationStateDraining contains remediation state when the node cordoned and its pods evicted by the controller
+	// before the host power off
+	RemediationStateDraining RemediationState = "Draining"
 	// RemediationStatePowerOff contains remediation state when the host power offed by the controller
 	RemediationStatePowerOff RemediationState = "PowerOff"
 	// RemediationStatePowerOn contains remediation state when the host power oned again by the controller
 	RemediationStatePowerOn RemediationState = "PowerOn"
+	// RemediationStateDeprovisioning contains remediation state when the host deprovisioned by the controller
+	RemediationStateDeprovisioning RemediationState = "Deprovisioning"
+	// RemediationStateProvisioning contains remediation state when the machine deleted by the controller and
+	// the host waits to be provisioned again for the new machine
+	RemediationStateProvisioning RemediationState = "Provisioning"
 	// RemediationStateSucceeded contains remediation state when the operation succeeded
 	RemediationStateSucceeded RemediationState = "Succeeded"
 	// RemediationStateFailed contains remediation state when the operation failed
@@ -64,22 +72,21 @@ type MachineRemediationSpec struct {
 	// MachineName contains the name of machine that should be remediate
 	MachineName string `json:"machineName,omitempty" valid:"required"`
 
-        // Map of string keys and values that can be used to organize and categorize
-        // (scope and select) objects. May match selectors of replication controllers
-        // and services.
-        // More info: http://kubernetes.io/docs/user-guide/labels
-        // +optional
-        SavedLabels map[string]string `json:"savedLabels,omitempty" protobuf:"bytes,11,rep,name=savedLabels"`
-
-        // Annotations is an unstructured key value map stored with a resource that may be
-        // set by external tools to store and retrieve arbitrary metadata. They are not
-        // queryable and should be preserved when modifying objects.
-        // More info: http://kubernetes.io/docs/user-guide/annotations
-        // +optional
-        SavedAnnotations map[string]string `json:"savedAnnotations,omitempty" protobuf:"bytes,12,rep,name=savedAnnotations"`
+	// Map of string keys and values that can be used to organize and categorize
+	// (scope and select) objects. May match selectors of replication controllers
+	// and services.
+	// More info: http://kubernetes.io/docs/user-guide/labels
+	// +optional
+	SavedLabels map[string]string `json:"savedLabels,omitempty" protobuf:"bytes,11,rep,name=savedLabels"`
+
+	// Annotations is an unstructured key value map stored with a resource that may be
+	// set by external tools to store and retrieve arbitrary metadata. They are not
+	// queryable and should be preserved when modifying objects.
+	// More info: http://kubernetes.io/docs/user-guide/annotations
+	// +optional
+	SavedAnnotations map[string]string `json:"savedAnnotations,omitempty" protobuf:"bytes,12,rep,name=savedAnnotations"`
 }
 
-
 // MachineRemediationStatus defines the observed status of MachineRemediation
 type MachineRemediationStatus struct {
 	State     RemediationState `json:"state,omitempty"`
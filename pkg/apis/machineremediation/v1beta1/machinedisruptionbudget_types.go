@@ -0,0 +1,68 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineDisruptionBudget is the schema for the MachineDisruptionBudget API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mdb;mdbs
+// +kubebuilder:printcolumn:name="Total",type="integer",JSONPath=".status.total"
+// +kubebuilder:printcolumn:name="CurrentHealthy",type="integer",JSONPath=".status.currentHealthy"
+// +kubebuilder:printcolumn:name="DesiredHealthy",type="integer",JSONPath=".status.desiredHealthy"
+// +kubebuilder:printcolumn:name="DisruptionsAllowed",type="integer",JSONPath=".status.disruptionsAllowed"
+// +k8s:openapi-gen=true
+type MachineDisruptionBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of MachineDisruptionBudget
+	Spec MachineDisruptionBudgetSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of MachineDisruptionBudget resource
+	Status MachineDisruptionBudgetStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineDisruptionBudgetList contains a list of MachineDisruptionBudget
+type MachineDisruptionBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineDisruptionBudget `json:"items"`
+}
+
+// MachineDisruptionBudgetSpec defines the spec of MachineDisruptionBudget
+type MachineDisruptionBudgetSpec struct {
+	// MinAvailable contains the number of selected machines that must stay healthy,
+	// mutually exclusive with MaxUnavailable
+	// +optional
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+
+	// Selector contains the label selector of machines under the budget namespace, that the budget applies to,
+	// the empty selector does not select any machine
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// MaxUnavailable contains the number of selected machines that can be unhealthy or remediated,
+	// mutually exclusive with MinAvailable
+	// +optional
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+}
+
+// MachineDisruptionBudgetStatus defines the observed status of MachineDisruptionBudget
+type MachineDisruptionBudgetStatus struct {
+	// ObservedGeneration contains the generation of the spec that the controller observed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DisruptionsAllowed contains the number of healthy machines that can be remediated at the moment
+	DisruptionsAllowed int32 `json:"disruptionsAllowed"`
+	// CurrentHealthy contains the number of selected machines with ready nodes and without the remediation in progress
+	CurrentHealthy int32 `json:"currentHealthy"`
+	// DesiredHealthy contains the minimal number of selected machines that must stay healthy
+	DesiredHealthy int32 `json:"desiredHealthy"`
+	// Total contains the number of machines that the budget selects
+	Total int32 `json:"total"`
+}
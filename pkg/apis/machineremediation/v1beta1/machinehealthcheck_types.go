@@ -0,0 +1,84 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// ConfigMapNodeUnhealthyConditions contains the name of the config map with unhealthy conditions of nodes,
+	// that machine health checks under the config map namespace use when they do not specify own conditions
+	ConfigMapNodeUnhealthyConditions = "node-unhealthy-conditions"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheck is the schema for the MachineHealthCheck API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mhc;mhcs
+// +kubebuilder:printcolumn:name="MaxUnhealthy",type="string",JSONPath=".spec.maxUnhealthy"
+// +kubebuilder:printcolumn:name="ExpectedMachines",type="integer",JSONPath=".status.expectedMachines"
+// +kubebuilder:printcolumn:name="CurrentHealthy",type="integer",JSONPath=".status.currentHealthy"
+// +k8s:openapi-gen=true
+type MachineHealthCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of MachineHealthCheck
+	Spec MachineHealthCheckSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of MachineHealthCheck resource
+	Status MachineHealthCheckStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineHealthCheckList contains a list of MachineHealthCheck
+type MachineHealthCheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineHealthCheck `json:"items"`
+}
+
+// MachineHealthCheckSpec defines the spec of MachineHealthCheck
+type MachineHealthCheckSpec struct {
+	// Selector contains the label selector of machines under the health check namespace, that the health check
+	// applies to, the empty selector does not select any machine
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// UnhealthyConditions contains node conditions that make the node unhealthy once they last longer
+	// than their timeout, when not specified, the controller uses conditions of the node-unhealthy-conditions
+	// config map under the health check namespace, or its own defaults
+	// +optional
+	UnhealthyConditions []UnhealthyCondition `json:"unhealthyConditions,omitempty"`
+
+	// MaxUnhealthy contains the number of selected machines that can be unhealthy, before the controller
+	// stops to create remediations for them, an absolute number or a percentage of selected machines,
+	// unlimited when not specified
+	// +optional
+	MaxUnhealthy *intstr.IntOrString `json:"maxUnhealthy,omitempty"`
+}
+
+// UnhealthyCondition contains the node condition type and status, that make the node unhealthy once
+// the condition has the status longer than the timeout
+type UnhealthyCondition struct {
+	// Type contains the type of the node condition
+	Type corev1.NodeConditionType `json:"type"`
+	// Status contains the status of the node condition
+	Status corev1.ConditionStatus `json:"status"`
+	// Timeout contains the time that the node condition should have the status, before the node is unhealthy
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// MachineHealthCheckStatus defines the observed status of MachineHealthCheck
+type MachineHealthCheckStatus struct {
+	// ObservedGeneration contains the generation of the spec that the controller observed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ExpectedMachines contains the number of machines that the health check selects
+	ExpectedMachines int32 `json:"expectedMachines"`
+	// CurrentHealthy contains the number of selected machines with healthy nodes
+	CurrentHealthy int32 `json:"currentHealthy"`
+}
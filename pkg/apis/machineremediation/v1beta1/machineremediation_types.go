@@ -0,0 +1,306 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemediationType contains type of the remediation
+type RemediationType string
+
+const (
+	// RemediationTypeReboot contains reboot type of the remediation
+	RemediationTypeReboot RemediationType = "reboot"
+	// RemediationTypeRecreate contains re-create type of the remediation
+	RemediationTypeRecreate RemediationType = "recreate"
+)
+
+// RemediationState contains state of the remediation
+type RemediationState string
+
+const (
+	// RemediationStatePending contains remediation state when the machine remediation waits to start,
+	// because the remediation of the machine exceeds one of machine disruption budgets, or puts
+	// the control plane at risk
+	RemediationStatePending RemediationState = "Pending"
+	// RemediationStateStarted contains remediation state when the machine remediation object was created
+	RemediationStateStarted RemediationState = "Started"
+	// RemediationStateDraining contains remediation state when the node cordoned and its pods evicted by the controller
+	// before the host power off
+	RemediationStateDraining RemediationState = "Draining"
+	// RemediationStatePowerOff contains remediation state when the host power offed by the controller
+	RemediationStatePowerOff RemediationState = "PowerOff"
+	// RemediationStatePowerOn contains remediation state when the host power oned again by the controller
+	RemediationStatePowerOn RemediationState = "PowerOn"
+	// RemediationStateEtcdMemberRemoval contains remediation state when the controller removes the etcd member
+	// of the recreated control plane machine, before the deprovisioning of the host
+	RemediationStateEtcdMemberRemoval RemediationState = "EtcdMemberRemoval"
+	// RemediationStateDeprovisioning contains remediation state when the host deprovisioned by the controller
+	RemediationStateDeprovisioning RemediationState = "Deprovisioning"
+	// RemediationStateProvisioning contains remediation state when the machine deleted by the controller and
+	// the host waits to be provisioned again for the new machine
+	RemediationStateProvisioning RemediationState = "Provisioning"
+	// RemediationStateEtcdMemberRejoin contains remediation state when the node of the recreated control plane
+	// machine is ready, and the controller waits until its etcd member joins the etcd cluster
+	RemediationStateEtcdMemberRejoin RemediationState = "EtcdMemberRejoin"
+	// RemediationStateSucceeded contains remediation state when the operation succeeded
+	RemediationStateSucceeded RemediationState = "Succeeded"
+	// RemediationStateFailed contains remediation state when the operation failed
+	RemediationStateFailed RemediationState = "Failed"
+)
+
+// RemediationReason contains the machine-readable reason of the remediation state or condition
+type RemediationReason string
+
+const (
+	// RemediationReasonInProgress contains reason when the remediation is still in progress
+	RemediationReasonInProgress RemediationReason = "InProgress"
+	// RemediationReasonSucceeded contains reason when the remediation succeeded
+	RemediationReasonSucceeded RemediationReason = "Succeeded"
+	// RemediationReasonSkippedOffline contains reason when the remediation skipped,
+	// because the host had power off state before the remediation
+	RemediationReasonSkippedOffline RemediationReason = "SkippedOffline"
+	// RemediationReasonTimedOut contains reason when the remediation failed on timeout
+	RemediationReasonTimedOut RemediationReason = "TimedOut"
+	// RemediationReasonBMCError contains reason when the baseboard management controller returned an error
+	RemediationReasonBMCError RemediationReason = "BMCError"
+	// RemediationReasonUnsupported contains reason when the remediator does not support the remediation type
+	// or the host, for example the redfish remediator of the host without a Redfish BMC
+	RemediationReasonUnsupported RemediationReason = "Unsupported"
+	// RemediationReasonUnsupportedPlatform contains reason when the remediation waits, because the cluster
+	// does not serve the API of the infrastructure platform, that the remediator requires
+	RemediationReasonUnsupportedPlatform RemediationReason = "UnsupportedPlatform"
+	// RemediationReasonNoMachineSetOwner contains reason when the machine can not be recreated,
+	// because it does not have MachineSet owner
+	RemediationReasonNoMachineSetOwner RemediationReason = "NoMachineSetOwner"
+	// RemediationReasonExternalRemediatorError contains reason when the external remediator failed the remediation
+	RemediationReasonExternalRemediatorError RemediationReason = "ExternalRemediatorError"
+	// RemediationReasonNotAllowedByPolicy contains reason when the remediation failed, because the machine
+	// remediation policy of the machine does not allow its remediation type
+	RemediationReasonNotAllowedByPolicy RemediationReason = "NotAllowedByPolicy"
+	// RemediationReasonThrottled contains reason when the remediation waits to start, because the machine
+	// remediation policy of the machine reached the limit of concurrent remediations
+	RemediationReasonThrottled RemediationReason = "Throttled"
+	// RemediationReasonDisruptionBudgetExceeded contains reason when the remediation waits to start, because
+	// the machine disruption budget of the machine does not allow more disruptions
+	RemediationReasonDisruptionBudgetExceeded RemediationReason = "DisruptionBudgetExceeded"
+	// RemediationReasonControlPlaneRemediationInProgress contains reason when the remediation of the control plane
+	// machine waits to start, because other control plane machine has the remediation in progress
+	RemediationReasonControlPlaneRemediationInProgress RemediationReason = "ControlPlaneRemediationInProgress"
+	// RemediationReasonQuorumAtRisk contains reason when the remediation of the control plane machine waits
+	// for the human approval, because the remediation would leave the etcd cluster without the quorum
+	RemediationReasonQuorumAtRisk RemediationReason = "QuorumAtRisk"
+)
+
+// MachineRemediationConditionType contains type of the machine remediation condition
+type MachineRemediationConditionType string
+
+const (
+	// MachineRemediationConditionFenced contains condition type that reports whether the host was isolated
+	// from the cluster by the power off or the deletion
+	MachineRemediationConditionFenced MachineRemediationConditionType = "Fenced"
+	// MachineRemediationConditionPoweredOn contains condition type that reports whether the host powered on
+	MachineRemediationConditionPoweredOn MachineRemediationConditionType = "PoweredOn"
+	// MachineRemediationConditionNodeReady contains condition type that reports whether the node of the remediated
+	// machine is ready
+	MachineRemediationConditionNodeReady MachineRemediationConditionType = "NodeReady"
+	// MachineRemediationConditionSucceeded contains condition type that reports whether the remediation succeeded,
+	// it has unknown status until the remediation finished
+	MachineRemediationConditionSucceeded MachineRemediationConditionType = "Succeeded"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediation is the schema for the MachineRemediation API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mr;mrs
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".spec.machineName"
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.reason"
+// +k8s:openapi-gen=true
+type MachineRemediation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of MachineRemediation
+	Spec MachineRemediationSpec `json:"spec,omitempty"`
+
+	// Most recently observed status of MachineRemediation resource
+	Status MachineRemediationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediationList contains a list of MachineRemediation
+type MachineRemediationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineRemediation `json:"items"`
+}
+
+// MachineRemediationSpec defines the spec of MachineRemediation
+type MachineRemediationSpec struct {
+	// Type contains the type of the remediation
+	Type RemediationType `json:"type,omitempty" valid:"required"`
+	// MachineName contains the name of machine that should be remediate
+	MachineName string `json:"machineName,omitempty" valid:"required"`
+
+	// Map of string keys and values that can be used to organize and categorize
+	// (scope and select) objects. May match selectors of replication controllers
+	// and services.
+	// More info: http://kubernetes.io/docs/user-guide/labels
+	// +optional
+	SavedLabels map[string]string `json:"savedLabels,omitempty" protobuf:"bytes,11,rep,name=savedLabels"`
+
+	// Annotations is an unstructured key value map stored with a resource that may be
+	// set by external tools to store and retrieve arbitrary metadata. They are not
+	// queryable and should be preserved when modifying objects.
+	// More info: http://kubernetes.io/docs/user-guide/annotations
+	// +optional
+	SavedAnnotations map[string]string `json:"savedAnnotations,omitempty" protobuf:"bytes,12,rep,name=savedAnnotations"`
+
+	// SavedTaints contains taints of the node, that the controller restores once the node is back,
+	// taints managed by the node lifecycle controller are not saved
+	// +optional
+	SavedTaints []corev1.Taint `json:"savedTaints,omitempty"`
+
+	// Timeouts contains timeouts of the remediation, the controller uses its own defaults
+	// for timeouts that are not specified
+	// +optional
+	Timeouts *RemediationTimeouts `json:"timeouts,omitempty"`
+
+	// Strategies contains the ordered list of remediation strategies, the controller moves to the next
+	// strategy when the current one fails, once specified, it replaces the type and timeouts of the spec
+	// +optional
+	Strategies []RemediationStrategy `json:"strategies,omitempty"`
+}
+
+// RemediationStrategy contains the type and timeouts of a single remediation attempt
+type RemediationStrategy struct {
+	// Type contains the type of the remediation
+	Type RemediationType `json:"type"`
+	// Timeouts contains timeouts of the remediation attempt, the controller uses its own defaults
+	// for timeouts that are not specified
+	// +optional
+	Timeouts *RemediationTimeouts `json:"timeouts,omitempty"`
+}
+
+// RemediationTimeouts contains timeouts of the whole remediation and of its phases,
+// the remediation fails once one of timeouts expires, the zero duration disables the timeout
+type RemediationTimeouts struct {
+	// Total contains the timeout of the whole remediation, measured from the remediation start time
+	// +optional
+	Total *metav1.Duration `json:"total,omitempty"`
+	// PowerOff contains the timeout of the power off confirmation, measured from the transition
+	// to the PowerOff state, it applies only to the reboot
+	// +optional
+	PowerOff *metav1.Duration `json:"powerOff,omitempty"`
+	// Boot contains the timeout of the host power on, measured from the transition to the PowerOn state,
+	// it applies only to the reboot
+	// +optional
+	Boot *metav1.Duration `json:"boot,omitempty"`
+	// NodeReady contains the timeout of the node readiness, measured from the time when the host powered on,
+	// it applies only to the reboot
+	// +optional
+	NodeReady *metav1.Duration `json:"nodeReady,omitempty"`
+}
+
+// MachineRemediationStatus defines the observed status of MachineRemediation
+type MachineRemediationStatus struct {
+	// ObservedGeneration contains the generation of the spec that the controller observed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// State contains the current state of the remediation
+	State RemediationState `json:"state,omitempty"`
+	// Reason contains the machine-readable reason of the current state
+	// +optional
+	Reason RemediationReason `json:"reason,omitempty"`
+	// Message contains the human-readable details of the current state
+	// +optional
+	Message string `json:"message,omitempty"`
+	// StartTime contains the time when the remediation started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// StateTransitionTime contains the time when the remediation moved to the current state
+	// +optional
+	StateTransitionTime *metav1.Time `json:"stateTransitionTime,omitempty"`
+	// EndTime contains the time when the remediation reached the final state
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// NodeBootID contains the boot ID of the machine node when the remediation started,
+	// the reboot succeeds only when the node reports a different boot ID
+	// +optional
+	NodeBootID string `json:"nodeBootID,omitempty"`
+	// Conditions contains the latest observations of the remediation progress
+	// +optional
+	Conditions []MachineRemediationCondition `json:"conditions,omitempty"`
+	// Attempts contains remediation attempts, one for each applied remediation strategy
+	// +optional
+	Attempts []RemediationAttempt `json:"attempts,omitempty"`
+	// ApprovedCertificates contains certificate signing requests of the remediated machine node,
+	// that the controller approved
+	// +optional
+	ApprovedCertificates []ApprovedCertificate `json:"approvedCertificates,omitempty"`
+}
+
+// CertificateType contains the type of the kubelet certificate
+type CertificateType string
+
+const (
+	// CertificateTypeClient contains the type of the kubelet client certificate
+	CertificateTypeClient CertificateType = "client"
+	// CertificateTypeServing contains the type of the kubelet serving certificate
+	CertificateTypeServing CertificateType = "serving"
+)
+
+// ApprovedCertificate contains the certificate signing request that the controller approved
+type ApprovedCertificate struct {
+	// Name contains the name of the certificate signing request
+	Name string `json:"name"`
+	// NodeName contains the name of the node that requested the certificate
+	NodeName string `json:"nodeName"`
+	// MachineName contains the name of the machine that the request matched
+	MachineName string `json:"machineName"`
+	// Type contains the type of the certificate, one of client, serving
+	Type CertificateType `json:"type"`
+	// ApprovalTime contains the time when the controller approved the request
+	ApprovalTime metav1.Time `json:"approvalTime"`
+}
+
+// RemediationAttempt contains the outcome of the remediation strategy applied to the machine
+type RemediationAttempt struct {
+	// Type contains the type of the remediation
+	Type RemediationType `json:"type"`
+	// State contains the final state of the attempt, it is empty until the attempt finished
+	// +optional
+	State RemediationState `json:"state,omitempty"`
+	// Reason contains the machine-readable reason of the final state
+	// +optional
+	Reason RemediationReason `json:"reason,omitempty"`
+	// Message contains the human-readable details of the final state
+	// +optional
+	Message string `json:"message,omitempty"`
+	// StartTime contains the time when the attempt started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EndTime contains the time when the attempt reached the final state
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+}
+
+// MachineRemediationCondition contains details of the machine remediation condition
+type MachineRemediationCondition struct {
+	// Type contains the type of the condition
+	Type MachineRemediationConditionType `json:"type"`
+	// Status contains the status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime contains the last time the condition changed the status
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason contains the machine-readable reason of the last condition transition
+	// +optional
+	Reason RemediationReason `json:"reason,omitempty"`
+	// Message contains the human-readable details of the last condition transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
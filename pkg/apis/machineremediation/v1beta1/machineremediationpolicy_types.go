@@ -0,0 +1,78 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediationPolicy is the schema for the MachineRemediationPolicy API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=mrp;mrps
+// +kubebuilder:printcolumn:name="Matched",type="integer",JSONPath=".status.matchedMachines"
+// +kubebuilder:printcolumn:name="Active",type="integer",JSONPath=".status.activeRemediations"
+// +kubebuilder:printcolumn:name="MaxConcurrent",type="string",JSONPath=".spec.maxConcurrent"
+// +k8s:openapi-gen=true
+type MachineRemediationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of MachineRemediationPolicy
+	Spec MachineRemediationPolicySpec `json:"spec,omitempty"`
+
+	// Most recently observed status of MachineRemediationPolicy resource
+	Status MachineRemediationPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediationPolicyList contains a list of MachineRemediationPolicy
+type MachineRemediationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineRemediationPolicy `json:"items"`
+}
+
+// MachineRemediationPolicySpec defines the spec of MachineRemediationPolicy
+type MachineRemediationPolicySpec struct {
+	// Selector contains the label selector of machines under the policy namespace, that the policy applies to,
+	// the empty selector does not select any machine
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// Strategies contains the ordered list of remediation strategies, that machine remediations of selected
+	// machines get on the creation, remediations with other strategy types are not allowed,
+	// the policy without strategies does not restrict remediation types
+	// +optional
+	Strategies []RemediationStrategy `json:"strategies,omitempty"`
+
+	// MaxConcurrent contains the number of selected machines that can be remediated at the same time,
+	// an absolute number or a percentage of selected machines rounded up, remediations over the limit
+	// wait until active ones finish, unlimited when not specified
+	// +optional
+	MaxConcurrent *intstr.IntOrString `json:"maxConcurrent,omitempty"`
+
+	// Drain contains the drain behavior for nodes of selected machines, the controller uses its own defaults
+	// when not specified
+	// +optional
+	Drain *DrainPolicy `json:"drain,omitempty"`
+}
+
+// DrainPolicy contains the drain behavior of the node before the power off
+type DrainPolicy struct {
+	// Timeout contains the time that the node has to be drained, before the remediation continues
+	// without the drain, the zero duration disables the drain
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+// MachineRemediationPolicyStatus defines the observed status of MachineRemediationPolicy
+type MachineRemediationPolicyStatus struct {
+	// ObservedGeneration contains the generation of the spec that the controller observed
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// MatchedMachines contains the number of machines that the policy selects
+	MatchedMachines int32 `json:"matchedMachines"`
+	// ActiveRemediations contains the number of selected machines with the remediation in progress
+	ActiveRemediations int32 `json:"activeRemediations"`
+}
@@ -0,0 +1,43 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediationTemplate is the schema for the MachineRemediationTemplate API, the upstream Cluster API
+// MachineHealthCheck references it under the remediation template and creates the MachineRemediation
+// from the template for each unhealthy machine
+// +kubebuilder:resource:shortName=mrt;mrts
+// +k8s:openapi-gen=true
+type MachineRemediationTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of MachineRemediationTemplate
+	Spec MachineRemediationTemplateSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MachineRemediationTemplateList contains a list of MachineRemediationTemplate
+type MachineRemediationTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MachineRemediationTemplate `json:"items"`
+}
+
+// MachineRemediationTemplateSpec defines the spec of MachineRemediationTemplate
+type MachineRemediationTemplateSpec struct {
+	// Template contains the machine remediation that the MachineHealthCheck creates
+	Template MachineRemediationTemplateResource `json:"template"`
+}
+
+// MachineRemediationTemplateResource contains the spec of machine remediations created from the template
+type MachineRemediationTemplateResource struct {
+	// Spec contains the spec of created machine remediations, the machine name is left empty,
+	// because the webhook fills it by the Cluster API machine that owns the created machine remediation
+	Spec MachineRemediationSpec `json:"spec"`
+}
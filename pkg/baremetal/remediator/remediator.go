@@ -5,9 +5,19 @@ import (
 	"fmt"
 	"time"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
 	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/controlplane"
+	"kubevirt.io/machine-remediation/pkg/utils/drain"
+	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+	"kubevirt.io/machine-remediation/pkg/utils/workloads"
 
 	"github.com/golang/glog"
 	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
@@ -24,27 +34,465 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-const (
-	rebootDefaultTimeout = 5
-)
-
 // BareMetalRemediator implements Remediator interface for bare metal machines
 type BareMetalRemediator struct {
 	client   client.Client
 	recorder record.EventRecorder
+	// drainer drains the node before the power off, the drain is disabled when it is nil,
+	// the machine remediation policy can override its timeout
+	drainer *drain.Drainer
+	// releaser releases workloads of the powered off node instead of the node deletion,
+	// the node is deleted when it is nil
+	releaser *workloads.Releaser
+	// etcd removes etcd members of recreated control plane machines and verifies that new members join,
+	// the etcd membership is not managed when it is nil
+	etcd *etcd.Client
+	// timeouts contains default timeouts of remediations
+	timeouts *timeouts.Defaults
 }
 
-// NewBareMetalRemediator returns new BareMetalRemediator object
-func NewBareMetalRemediator(mgr manager.Manager) *BareMetalRemediator {
+// NewBareMetalRemediator returns new BareMetalRemediator object, the drainer can be nil
+// to power off hosts without the drain of nodes, the releaser can be nil to delete nodes of powered off hosts,
+// and the etcd client can be nil to recreate control plane machines without changes of the etcd membership
+func NewBareMetalRemediator(
+	mgr manager.Manager,
+	drainer *drain.Drainer,
+	releaser *workloads.Releaser,
+	etcdClient *etcd.Client,
+	defaultTimeouts *timeouts.Defaults,
+) *BareMetalRemediator {
 	return &BareMetalRemediator{
 		client:   mgr.GetClient(),
 		recorder: mgr.GetEventRecorderFor("baremetal-remediator"),
+		drainer:  drainer,
+		releaser: releaser,
+		etcd:     etcdClient,
+		timeouts: defaultTimeouts,
 	}
 }
 
 // Recreate recreates the bare metal machine under the cluster
 func (bmr *BareMetalRemediator) Recreate(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
-	return fmt.Errorf("Not implemented yet")
+	glog.V(4).Infof("MachineRemediation %q has state %q", machineRemediation.Name, machineRemediation.Status.State)
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	now := time.Now()
+	switch machineRemediation.Status.State {
+	// initiating the recreate action
+	case mrv1.RemediationStateStarted:
+		// Get the machine from the MachineRemediation
+		key := types.NamespacedName{
+			Namespace: machineRemediation.Namespace,
+			Name:      machineRemediation.Spec.MachineName,
+		}
+		machine := &mapiv1.Machine{}
+		if err := bmr.client.Get(context.TODO(), key, machine); err != nil {
+			return err
+		}
+
+		// the machine will be re-created only when some MachineSet owns it
+		if !hasMachineSetOwner(machine) {
+			glog.Errorf("Remediation of machine %q failed, machine does not have MachineSet owner", machine.Name)
+			bmr.recorder.Eventf(
+				machineRemediation,
+				corev1.EventTypeWarning,
+				"MachineRemediationRecreateFailed",
+				"Recreate of machine %q failed because it does not have MachineSet owner",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateFailed
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonNoMachineSetOwner
+			mrCopy.Status.Message = "Recreate failed, the machine does not have MachineSet owner"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionFalse,
+				mrv1.RemediationReasonNoMachineSetOwner,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+			return bmr.client.Status().Update(context.TODO(), mrCopy)
+		}
+
+		// Get the bare metal host object
+		bmh, err := GetBareMetalHostByMachine(bmr.client, machine)
+		if err != nil {
+			return err
+		}
+
+		// save the bare metal host key under the machine remediation object,
+		// because the machine and its annotations will be deleted
+		if mrCopy.Annotations == nil {
+			mrCopy.Annotations = map[string]string{}
+		}
+		mrCopy.Annotations[consts.AnnotationBareMetalHost] = machine.Annotations[consts.AnnotationBareMetalHost]
+		if err := bmr.client.Update(context.TODO(), mrCopy); err != nil {
+			return err
+		}
+
+		// the etcd member of the control plane machine should be removed before the host loses its data,
+		// otherwise the new member can not join the etcd cluster under the same name
+		if bmr.etcd != nil && machineutils.IsControlPlane(machine) {
+			bmr.recorder.Eventf(
+				machineRemediation,
+				corev1.EventTypeNormal,
+				"MachineRemediationRecreateStarted",
+				"Recreate of machine %q has started",
+				machine.Name,
+			)
+
+			mrCopy.Status.State = mrv1.RemediationStateEtcdMemberRemoval
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+			mrCopy.Status.Message = "Removing the etcd member"
+			return bmr.client.Status().Update(context.TODO(), mrCopy)
+		}
+
+		if err := bmr.deprovision(bmh, machine.Name); err != nil {
+			return err
+		}
+
+		bmr.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeNormal,
+			"MachineRemediationRecreateStarted",
+			"Recreate of machine %q has started",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStateDeprovisioning
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Deprovisioning the host"
+		return bmr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStateEtcdMemberRemoval:
+		// failed the remediation on timeout
+		if bmr.timeouts.Expired(machineRemediation, now) != "" {
+			return bmr.failRecreateOnTimeout(mrCopy, now)
+		}
+
+		bmh, err := getBareMetalHostByKey(bmr.client, machineRemediation.Annotations[consts.AnnotationBareMetalHost])
+		if err != nil {
+			return err
+		}
+
+		machine := &mapiv1.Machine{}
+		key := types.NamespacedName{
+			Namespace: machineRemediation.Namespace,
+			Name:      machineRemediation.Spec.MachineName,
+		}
+		if err := bmr.client.Get(context.TODO(), key, machine); err != nil {
+			return err
+		}
+
+		member, err := bmr.getEtcdMember(ctx, machine)
+		if err != nil {
+			return err
+		}
+
+		if member == nil {
+			glog.Warningf("The machine %q does not have etcd member", machine.Name)
+		} else {
+			glog.V(4).Infof("Remove etcd member %s of machine %q", member.ID, machine.Name)
+			if err := bmr.etcd.MemberRemove(ctx, member.ID); err != nil {
+				return err
+			}
+
+			bmr.recorder.Eventf(
+				machineRemediation,
+				corev1.EventTypeNormal,
+				"MachineRemediationEtcdMemberRemoved",
+				"Etcd member %s of machine %q removed",
+				member.ID,
+				machine.Name,
+			)
+		}
+
+		if err := bmr.deprovision(bmh, machine.Name); err != nil {
+			return err
+		}
+
+		mrCopy.Status.State = mrv1.RemediationStateDeprovisioning
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Deprovisioning the host"
+		return bmr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStateDeprovisioning:
+		// failed the remediation on timeout
+		if bmr.timeouts.Expired(machineRemediation, now) != "" {
+			return bmr.failRecreateOnTimeout(mrCopy, now)
+		}
+
+		bmh, err := getBareMetalHostByKey(bmr.client, machineRemediation.Annotations[consts.AnnotationBareMetalHost])
+		if err != nil {
+			return err
+		}
+
+		// host still has not deprovisioned, we need to reconcile
+		if bmh.Status.Provisioning.State != bmov1.StateReady {
+			glog.Warningf("bare metal host %q still has provisioning state %q", bmh.Name, bmh.Status.Provisioning.State)
+			return nil
+		}
+
+		// delete the machine, the MachineSet will create the new one instead of it
+		machine := &mapiv1.Machine{}
+		key := types.NamespacedName{
+			Namespace: machineRemediation.Namespace,
+			Name:      machineRemediation.Spec.MachineName,
+		}
+		if err := bmr.client.Get(context.TODO(), key, machine); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			glog.Warningf("The machine %q does not exist", machineRemediation.Spec.MachineName)
+		} else {
+			glog.V(4).Infof("Delete machine %q", machine.Name)
+			if err := bmr.client.Delete(context.TODO(), machine); err != nil {
+				return err
+			}
+		}
+
+		bmr.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeNormal,
+			"MachineRemediationRecreateMachineDeleted",
+			"Machine %q deleted, waiting for the new machine",
+			machineRemediation.Spec.MachineName,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStateProvisioning
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Recreate in progress"
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionFenced,
+			corev1.ConditionTrue,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
+		return bmr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStateProvisioning:
+		// failed the remediation on timeout
+		if bmr.timeouts.Expired(machineRemediation, now) != "" {
+			return bmr.failRecreateOnTimeout(mrCopy, now)
+		}
+
+		bmh, err := getBareMetalHostByKey(bmr.client, machineRemediation.Annotations[consts.AnnotationBareMetalHost])
+		if err != nil {
+			return err
+		}
+
+		// host still was not consumed by the new machine, we need to reconcile
+		machine, err := bmr.getNewMachine(bmh, machineRemediation)
+		if err != nil {
+			return err
+		}
+		if machine == nil {
+			glog.Warningf("bare metal host %q still was not consumed by the new machine", bmh.Name)
+			return nil
+		}
+
+		node, err := nodes.GetNodeByMachine(bmr.client, machine)
+		if err != nil {
+			// we want to reconcile with delay of 10 seconds when the machine does not have node reference
+			// or node does not exist
+			if errors.IsNotFound(err) {
+				glog.Warningf("The machine %q node does not exist", machine.Name)
+				return nil
+			}
+			return err
+		}
+
+		// Node of the new machine is not Ready under the cluster yet
+		if !conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) {
+			return nil
+		}
+
+		// the new control plane machine should run the started etcd member before the remediation succeeds
+		if bmr.etcd != nil && machineutils.IsControlPlane(machine) {
+			glog.V(4).Infof("Node %q of machine %q is ready, waiting for its etcd member", node.Name, machine.Name)
+			mrCopy.Status.State = mrv1.RemediationStateEtcdMemberRejoin
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+			mrCopy.Status.Message = "Waiting for the etcd member to join the etcd cluster"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionNodeReady,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonInProgress,
+				mrCopy.Status.Message,
+			)
+			return bmr.client.Status().Update(context.TODO(), mrCopy)
+		}
+		return bmr.succeedRecreate(mrCopy, machine, now)
+
+	case mrv1.RemediationStateEtcdMemberRejoin:
+		// failed the remediation on timeout
+		if bmr.timeouts.Expired(machineRemediation, now) != "" {
+			return bmr.failRecreateOnTimeout(mrCopy, now)
+		}
+
+		bmh, err := getBareMetalHostByKey(bmr.client, machineRemediation.Annotations[consts.AnnotationBareMetalHost])
+		if err != nil {
+			return err
+		}
+
+		machine, err := bmr.getNewMachine(bmh, machineRemediation)
+		if err != nil {
+			return err
+		}
+		if machine == nil {
+			return fmt.Errorf("bare metal host %q does not have the new machine", bmh.Name)
+		}
+
+		member, err := bmr.getEtcdMember(ctx, machine)
+		if err != nil {
+			return err
+		}
+
+		// the member was not added yet, or it was added, but did not start yet
+		if member == nil || !member.IsStarted() {
+			glog.Warningf("The etcd member of machine %q still did not join the etcd cluster", machine.Name)
+			return nil
+		}
+
+		bmr.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeNormal,
+			"MachineRemediationEtcdMemberRejoined",
+			"Etcd member %s of machine %q joined the etcd cluster",
+			member.ID,
+			machine.Name,
+		)
+		return bmr.succeedRecreate(mrCopy, machine, now)
+	}
+	return nil
+}
+
+// getDrainTimeout returns the time that the machine node has to be drained before the power off,
+// the machine remediation policy of the machine overrides the default one, the zero timeout disables the drain
+func (bmr *BareMetalRemediator) getDrainTimeout(machine *mapiv1.Machine) (time.Duration, error) {
+	if bmr.drainer == nil {
+		return 0, nil
+	}
+
+	policy, err := policies.GetMachinePolicy(bmr.client, machine)
+	if err != nil {
+		return 0, err
+	}
+	if policy != nil && policy.Spec.Drain != nil {
+		return policy.Spec.Drain.Timeout.Duration, nil
+	}
+	return bmr.drainer.Timeout, nil
+}
+
+// deprovision deprovisions the bare metal host of the machine
+func (bmr *BareMetalRemediator) deprovision(bmh *bmov1.BareMetalHost, machineName string) error {
+	glog.V(4).Infof("Deprovision bare metal host %q of machine %q", bmh.Name, machineName)
+	bmhCopy := bmh.DeepCopy()
+	bmhCopy.Spec.Image = nil
+	return bmr.client.Update(context.TODO(), bmhCopy)
+}
+
+// getNewMachine returns the machine that consumes the bare metal host instead of the remediated one,
+// or nil when the host was not consumed by the new machine yet, the host consumer is the machine,
+// or the Metal3Machine of the machine under the upstream Cluster API
+func (bmr *BareMetalRemediator) getNewMachine(bmh *bmov1.BareMetalHost, machineRemediation *mrv1.MachineRemediation) (*mapiv1.Machine, error) {
+	consumerRef := bmh.Spec.ConsumerRef
+	if consumerRef == nil || consumerRef.Name == machineRemediation.Spec.MachineName {
+		return nil, nil
+	}
+
+	machine, err := machineapi.GetMachineByConsumerRef(bmr.client, consumerRef)
+	if err != nil || machine == nil {
+		return nil, err
+	}
+
+	// the upstream Cluster API host consumer is the Metal3Machine of the remediated machine
+	if machine.Name == machineRemediation.Spec.MachineName {
+		return nil, nil
+	}
+	return machine, nil
+}
+
+// getEtcdMember returns the etcd member that runs on the machine, or nil when the etcd cluster does not have it,
+// the member is looked up by names and internal addresses of the machine and its node
+func (bmr *BareMetalRemediator) getEtcdMember(ctx context.Context, machine *mapiv1.Machine) (*etcd.Member, error) {
+	members, err := bmr.etcd.MemberList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := nodes.GetNodeByMachine(bmr.client, machine)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	return controlplane.FindMember(members, machine, node), nil
+}
+
+// succeedRecreate moves the machine remediation to the succeeded state
+func (bmr *BareMetalRemediator) succeedRecreate(mrCopy *mrv1.MachineRemediation, machine *mapiv1.Machine, now time.Time) error {
+	glog.V(4).Infof("Remediation of machine %q succeeded, new machine %q", mrCopy.Spec.MachineName, machine.Name)
+	bmr.recorder.Eventf(
+		mrCopy,
+		corev1.EventTypeNormal,
+		"MachineRemediationRecreateSucceeded",
+		"Machine %q recreated as machine %q",
+		mrCopy.Spec.MachineName,
+		machine.Name,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateSucceeded
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonSucceeded
+	mrCopy.Status.Message = "Recreate succeeded"
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionNodeReady,
+		corev1.ConditionTrue,
+		mrv1.RemediationReasonSucceeded,
+		mrCopy.Status.Message,
+	)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionTrue,
+		mrv1.RemediationReasonSucceeded,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return bmr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// failRecreateOnTimeout moves the machine remediation to the failed state
+func (bmr *BareMetalRemediator) failRecreateOnTimeout(mrCopy *mrv1.MachineRemediation, now time.Time) error {
+	glog.Errorf("Remediation of machine %q failed on timeout", mrCopy.Spec.MachineName)
+	bmr.recorder.Eventf(
+		mrCopy,
+		corev1.EventTypeWarning,
+		"MachineRemediationRecreateTimedOut",
+		"Recreate of machine %q timed out",
+		mrCopy.Spec.MachineName,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateFailed
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonTimedOut
+	mrCopy.Status.Message = "Recreate failed on timeout"
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonTimedOut,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return bmr.client.Status().Update(context.TODO(), mrCopy)
 }
 
 // Reboot reboots the bare metal machine
@@ -62,7 +510,7 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 	}
 
 	// Get the bare metal host object
-	bmh, err := getBareMetalHostByMachine(bmr.client, machine)
+	bmh, err := GetBareMetalHostByMachine(bmr.client, machine)
 	if err != nil {
 		return err
 	}
@@ -73,11 +521,18 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 	// Copy the MachineRemediation object to prevent modification of the original one
 	mrCopy := machineRemediation.DeepCopy()
 
+	drainTimeout, err := bmr.getDrainTimeout(machine)
+	if err != nil {
+		return err
+	}
+	// the drain before the power off extends the total timeout, that the machine remediation does not specify
+	rebootTimeouts := bmr.timeouts.WithDrain(drainTimeout)
+
 	now := time.Now()
 	switch machineRemediation.Status.State {
 	// initiating the reboot action
 	case mrv1.RemediationStateStarted:
-		rebootInProgress := isRebootInProgress(bmh)
+		rebootInProgress := IsRebootInProgress(bmh)
 		// skip the reboot in case when the machine has power off state before the reboot action
 		// it can mean that an user power off the machine by purpose
 		if !bmh.Spec.Online && !rebootInProgress {
@@ -90,53 +545,88 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 				machine.Name,
 			)
 			mrCopy.Status.State = mrv1.RemediationStateSucceeded
-			mrCopy.Status.Reason = "Skip the reboot, the machine power off by an user"
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSkippedOffline
+			mrCopy.Status.Message = "Skip the reboot, the machine power off by an user"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSkippedOffline,
+				mrCopy.Status.Message,
+			)
 			mrCopy.Status.EndTime = &metav1.Time{Time: now}
-		} else {
-			if !rebootInProgress {
-				// set rebootInProgress annotation on the bare metal host
-				if bmhCopy.Annotations == nil {
-					bmhCopy.Annotations = map[string]string{}
-				}
-				bmhCopy.Annotations[consts.AnnotationRebootInProgress] = "true"
-			}
-
-			// power off the machine
-			glog.V(4).Infof("Power off machine %q", machine.Name)
-			bmhCopy.Spec.Online = false
-
-			if err := bmr.client.Update(context.TODO(), bmhCopy); err != nil {
-				return err
-			}
+			return bmr.client.Status().Update(context.TODO(), mrCopy)
+		}
 
+		// drain the node before the power off, when the drain enabled
+		if drainTimeout > 0 && !rebootInProgress {
 			bmr.recorder.Eventf(
 				machine,
 				corev1.EventTypeNormal,
-				"MachineRemediationRebootStarted",
-				"Reboot of machine %q has started",
+				"MachineRemediationDrainStarted",
+				"Drain of machine %q node has started",
 				machine.Name,
 			)
+			mrCopy.Status.State = mrv1.RemediationStateDraining
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+			mrCopy.Status.Message = "Draining the node"
+			return bmr.client.Status().Update(context.TODO(), mrCopy)
+		}
+		return bmr.powerOff(machine, bmhCopy, mrCopy, now)
 
-			mrCopy.Status.State = mrv1.RemediationStatePowerOff
-			mrCopy.Status.Reason = "Starts the reboot process"
+	case mrv1.RemediationStateDraining:
+		// the drain was disabled after the remediation started
+		if drainTimeout == 0 {
+			return bmr.powerOff(machine, bmhCopy, mrCopy, now)
 		}
-		return bmr.client.Status().Update(context.TODO(), mrCopy)
 
-	case mrv1.RemediationStatePowerOff:
-		// failed the remediation on timeout
-		if machineRemediation.Status.StartTime.Time.Add(rebootDefaultTimeout * time.Minute).Before(now) {
-			glog.Errorf("Remediation of machine %q failed on timeout", machine.Name)
+		// continue with the power off without waiting for the drain on the drain timeout
+		if timeouts.GetStateTransitionTime(machineRemediation).Time.Add(drainTimeout).Before(now) {
+			glog.Warningf("Drain of machine %q node timed out, continue with the power off", machine.Name)
 			bmr.recorder.Eventf(
 				machine,
 				corev1.EventTypeWarning,
-				"MachineRemediationRebootTimedOut",
-				"Remediation of machine %q timed out",
+				"MachineRemediationDrainTimedOut",
+				"Drain of machine %q node timed out, forcing power off",
 				machine.Name,
 			)
-			mrCopy.Status.State = mrv1.RemediationStateFailed
-			mrCopy.Status.Reason = "Reboot failed on timeout"
-			mrCopy.Status.EndTime = &metav1.Time{Time: now}
-			return bmr.client.Status().Update(context.TODO(), mrCopy)
+			return bmr.powerOff(machine, bmhCopy, mrCopy, now)
+		}
+
+		node, err := nodes.GetNodeByMachine(bmr.client, machine)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		// drain the node when it exists, otherwise nothing runs on it
+		if err == nil {
+			drained, err := bmr.drainer.Drain(node)
+			if err != nil {
+				return err
+			}
+
+			// node still has pods, we need to reconcile
+			if !drained {
+				glog.V(4).Infof("Node %q of machine %q still has pods to evict", node.Name, machine.Name)
+				return nil
+			}
+		}
+
+		bmr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationDrainSucceeded",
+			"Drain of machine %q node succeeded",
+			machine.Name,
+		)
+		return bmr.powerOff(machine, bmhCopy, mrCopy, now)
+
+	case mrv1.RemediationStatePowerOff:
+		// failed the remediation on timeout
+		if phase := rebootTimeouts.Expired(machineRemediation, now); phase != "" {
+			return bmr.failRebootOnTimeout(machine, mrCopy, phase, now)
 		}
 
 		// host still has state on, we need to reconcile
@@ -145,9 +635,18 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 			return nil
 		}
 
-		// delete the node to release workloads, once we are sure that host has state power off
-		if err := deleteMachineNode(bmr.client, machine); err != nil {
-			return err
+		// release workloads, once we are sure that host has state power off
+		reason := "Reboot in progress"
+		if bmr.releaser == nil {
+			// delete the node to release workloads
+			if err := nodes.DeleteNodeByMachine(bmr.client, machine); err != nil {
+				return err
+			}
+		} else {
+			reason, err = bmr.releaseWorkloads(machine)
+			if err != nil {
+				return err
+			}
 		}
 
 		// power on the machine
@@ -174,27 +673,39 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 		)
 
 		mrCopy.Status.State = mrv1.RemediationStatePowerOn
-		mrCopy.Status.Reason = "Reboot in progress"
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = reason
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionFenced,
+			corev1.ConditionTrue,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
 		return bmr.client.Status().Update(context.TODO(), mrCopy)
 
 	case mrv1.RemediationStatePowerOn:
 		// failed the remediation on timeout
-		if machineRemediation.Status.StartTime.Time.Add(rebootDefaultTimeout * time.Minute).Before(now) {
-			glog.Errorf("Remediation of machine %q failed on timeout", machine.Name)
-			bmr.recorder.Eventf(
-				machine,
-				corev1.EventTypeWarning,
-				"MachineRemediationRebootTimedOut",
-				"Remediation of machine %q timed out",
-				machine.Name,
+		if phase := rebootTimeouts.Expired(machineRemediation, now); phase != "" {
+			return bmr.failRebootOnTimeout(machine, mrCopy, phase, now)
+		}
+
+		// host booted, the node ready timeout is measured from now
+		poweredOn := conditions.MachineRemediationHasCondition(machineRemediation, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue)
+		if bmh.Status.PoweredOn && !poweredOn {
+			glog.V(4).Infof("Machine %q powered on", machine.Name)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonInProgress,
+				"Host powered on",
 			)
-			mrCopy.Status.State = mrv1.RemediationStateFailed
-			mrCopy.Status.Reason = "Reboot failed on timeout"
-			mrCopy.Status.EndTime = &metav1.Time{Time: now}
 			return bmr.client.Status().Update(context.TODO(), mrCopy)
 		}
 
-		node, err := getNodeByMachine(bmr.client, machine)
+		node, err := nodes.GetNodeByMachine(bmr.client, machine)
 		if err != nil {
 			// we want to reconcile with delay of 10 seconds when the machine does not have node reference
 			// or node does not exist
@@ -206,17 +717,21 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 		}
 
 		// Node back to Ready under the cluster
-		if conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) {
+		if isNodeBackToReady(node, machineRemediation, bmr.releaser != nil) {
 			glog.V(4).Infof("Remediation of machine %q succeeded", machine.Name)
-                        nodeCopy := node.DeepCopy()
-                        nodeCopy.ObjectMeta.Labels = machineRemediation.Spec.SavedLabels
-                        nodeCopy.ObjectMeta.Annotations = machineRemediation.Spec.SavedAnnotations
+			nodeCopy := node.DeepCopy()
+			if bmr.releaser == nil {
+				nodes.RestoreSavedMetadata(nodeCopy, machineRemediation)
+			} else if drainTimeout > 0 {
+				// the node object was kept, so it is still cordoned by the drain
+				nodeCopy.Spec.Unschedulable = false
+			}
 			delete(nodeCopy.Annotations, consts.AnnotationNodeMachineReboot)
-                        if err := bmr.client.Update(context.TODO(), nodeCopy); err != nil {
-                                return err
-                        }
+			if err := bmr.client.Update(context.TODO(), nodeCopy); err != nil {
+				return err
+			}
 
-                        glog.V(4).Infof("Reapplied labels and annotations to node %q", node.Name)
+			glog.V(4).Infof("Updated labels, annotations and taints of node %q", node.Name)
 
 			bmr.recorder.Eventf(
 				machine,
@@ -226,19 +741,37 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 				machine.Name,
 			)
 			mrCopy.Status.State = mrv1.RemediationStateSucceeded
-			mrCopy.Status.Reason = "Reboot succeeded"
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSucceeded
+			mrCopy.Status.Message = "Reboot succeeded"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionNodeReady,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
 			mrCopy.Status.EndTime = &metav1.Time{Time: now}
 			return bmr.client.Status().Update(context.TODO(), mrCopy)
 		}
 		return nil
 
-	// assumption that the reboot annotation removed because of node removal
-	case mrv1.RemediationStateSucceeded:
-		// remove machine remediation object
-		return bmr.client.Delete(context.TODO(), machineRemediation)
-
 	case mrv1.RemediationStateFailed:
-		node, err := getNodeByMachine(bmr.client, machine)
+		node, err := nodes.GetNodeByMachine(bmr.client, machine)
 		if errors.IsNotFound(err) {
 			return nil
 		}
@@ -248,19 +781,138 @@ func (bmr *BareMetalRemediator) Reboot(ctx context.Context, machineRemediation *
 		}
 
 		// remove the reboot annotation from the node, to initiate the reboot again
-		return removeNodeRebootAnnotation(bmr.client, node)
+		return nodes.RemoveRebootAnnotation(bmr.client, node)
 	}
 	return nil
 }
 
-// getBareMetalHostByMachine returns the bare metal host that linked to the machine
-func getBareMetalHostByMachine(c client.Client, machine *mapiv1.Machine) (*bmov1.BareMetalHost, error) {
+// releaseWorkloads force deletes pods and volume attachments of the machine node without the deletion of the node,
+// it returns the reason of the machine remediation that describes released workloads
+func (bmr *BareMetalRemediator) releaseWorkloads(machine *mapiv1.Machine) (string, error) {
+	node, err := nodes.GetNodeByMachine(bmr.client, machine)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Warningf("The machine %q node does not exist", machine.Name)
+			return "Reboot in progress, the node does not exist", nil
+		}
+		return "", err
+	}
+
+	deletedPods, err := bmr.releaser.DeletePods(node)
+	if err != nil {
+		return "", err
+	}
+	bmr.recorder.Eventf(
+		machine,
+		corev1.EventTypeNormal,
+		"MachineRemediationPodsDeleted",
+		"Force deleted %d pods of machine %q node %q",
+		deletedPods,
+		machine.Name,
+		node.Name,
+	)
+
+	deletedVolumeAttachments, err := bmr.releaser.DeleteVolumeAttachments(node)
+	if err != nil {
+		return "", err
+	}
+	bmr.recorder.Eventf(
+		machine,
+		corev1.EventTypeNormal,
+		"MachineRemediationVolumeAttachmentsDeleted",
+		"Deleted %d volume attachments of machine %q node %q",
+		deletedVolumeAttachments,
+		machine.Name,
+		node.Name,
+	)
+
+	return fmt.Sprintf(
+		"Reboot in progress, released %d pods and %d volume attachments of node %q",
+		deletedPods,
+		deletedVolumeAttachments,
+		node.Name,
+	), nil
+}
+
+// powerOff powers off the bare metal host and moves the machine remediation to the power off state
+func (bmr *BareMetalRemediator) powerOff(machine *mapiv1.Machine, bmhCopy *bmov1.BareMetalHost, mrCopy *mrv1.MachineRemediation, now time.Time) error {
+	if !IsRebootInProgress(bmhCopy) {
+		// set rebootInProgress annotation on the bare metal host
+		if bmhCopy.Annotations == nil {
+			bmhCopy.Annotations = map[string]string{}
+		}
+		bmhCopy.Annotations[consts.AnnotationRebootInProgress] = "true"
+	}
+
+	// power off the machine
+	glog.V(4).Infof("Power off machine %q", machine.Name)
+	bmhCopy.Spec.Online = false
+
+	if err := bmr.client.Update(context.TODO(), bmhCopy); err != nil {
+		return err
+	}
+
+	bmr.recorder.Eventf(
+		machine,
+		corev1.EventTypeNormal,
+		"MachineRemediationRebootStarted",
+		"Reboot of machine %q has started",
+		machine.Name,
+	)
+
+	mrCopy.Status.State = mrv1.RemediationStatePowerOff
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+	mrCopy.Status.Message = "Starts the reboot process"
+	return bmr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// failRebootOnTimeout moves the machine remediation to the failed state
+func (bmr *BareMetalRemediator) failRebootOnTimeout(machine *mapiv1.Machine, mrCopy *mrv1.MachineRemediation, phase timeouts.Phase, now time.Time) error {
+	glog.Errorf("Remediation of machine %q failed on %s timeout", machine.Name, phase)
+	bmr.recorder.Eventf(
+		machine,
+		corev1.EventTypeWarning,
+		"MachineRemediationRebootTimedOut",
+		"Remediation of machine %q timed out, %s timeout expired",
+		machine.Name,
+		phase,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateFailed
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonTimedOut
+	mrCopy.Status.Message = fmt.Sprintf("Reboot failed on %s timeout", phase)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonTimedOut,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return bmr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// GetBareMetalHostByMachine returns the bare metal host that linked to the machine
+func GetBareMetalHostByMachine(c client.Client, machine *mapiv1.Machine) (*bmov1.BareMetalHost, error) {
 	bmhKey, ok := machine.Annotations[consts.AnnotationBareMetalHost]
 	if !ok {
 		return nil, fmt.Errorf("machine does not have bare metal host annotation")
 	}
+	return getBareMetalHostByKey(c, bmhKey)
+}
+
+// getBareMetalHostByKey returns the bare metal host by the namespace/name key
+func getBareMetalHostByKey(c client.Client, bmhKey string) (*bmov1.BareMetalHost, error) {
+	if bmhKey == "" {
+		return nil, fmt.Errorf("empty bare metal host key")
+	}
 
 	bmhNamespace, bmhName, err := cache.SplitMetaNamespaceKey(bmhKey)
+	if err != nil {
+		return nil, err
+	}
+
 	bmh := &bmov1.BareMetalHost{}
 	key := client.ObjectKey{
 		Name:      bmhName,
@@ -274,59 +926,41 @@ func getBareMetalHostByMachine(c client.Client, machine *mapiv1.Machine) (*bmov1
 	return bmh, nil
 }
 
-// getNodeByMachine returns the node object referenced by machine
-func getNodeByMachine(c client.Client, machine *mapiv1.Machine) (*corev1.Node, error) {
-	if machine.Status.NodeRef == nil {
-		return nil, errors.NewNotFound(corev1.Resource("ObjectReference"), machine.Name)
+// hasMachineSetOwner returns true when the machine owned by some MachineSet
+func hasMachineSetOwner(machine *mapiv1.Machine) bool {
+	for _, ownerRef := range machine.OwnerReferences {
+		if ownerRef.Kind == "MachineSet" {
+			return true
+		}
 	}
+	return false
+}
 
-	node := &corev1.Node{}
-	key := client.ObjectKey{
-		Name:      machine.Status.NodeRef.Name,
-		Namespace: machine.Status.NodeRef.Namespace,
+// isNodeBackToReady returns true when the node has the ready condition and the new boot ID, when the node object was kept
+// during the remediation, the condition should become ready after the start of the remediation attempt
+func isNodeBackToReady(node *corev1.Node, machineRemediation *mrv1.MachineRemediation, nodeKept bool) bool {
+	readyCondition := conditions.GetNodeCondition(node, corev1.NodeReady)
+	if readyCondition == nil || readyCondition.Status != corev1.ConditionTrue {
+		return false
 	}
 
-	if err := c.Get(context.TODO(), key, node); err != nil {
-		return nil, err
+	// the stale ready condition of the node, that did not boot again
+	if !nodes.IsRebooted(node, machineRemediation) {
+		glog.Warningf("Node %q is ready, but still has the boot ID %q from the remediation start", node.Name, node.Status.NodeInfo.BootID)
+		return false
 	}
-	return node, nil
-}
 
-// deleteMachineNode deletes the node that mapped to specified machine
-func deleteMachineNode(c client.Client, machine *mapiv1.Machine) error {
-	node, err := getNodeByMachine(c, machine)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			glog.Warningf("The machine %q node does not exist", machine.Name)
-			return nil
-		}
-		return err
+	if nodeKept {
+		return readyCondition.LastTransitionTime.After(strategies.GetAttemptStartTime(machineRemediation).Time)
 	}
-
-	return c.Delete(context.TODO(), node)
+	return true
 }
 
-// isRebootInProgress returns true when the BareMetalHost currently is rebooting
-func isRebootInProgress(bmh *bmov1.BareMetalHost) bool {
+// IsRebootInProgress returns true when the BareMetalHost currently is rebooting
+func IsRebootInProgress(bmh *bmov1.BareMetalHost) bool {
 	rebootInProgress, ok := bmh.Annotations[consts.AnnotationRebootInProgress]
 	if !ok || rebootInProgress != "true" {
 		return false
 	}
 	return true
 }
-
-// removeNodeRebootAnnotation removes the reboot annotation from the node
-func removeNodeRebootAnnotation(c client.Client, node *corev1.Node) error {
-	nodeCopy := node.DeepCopy()
-
-	if nodeCopy.Annotations == nil {
-		return nil
-	}
-
-	if _, ok := nodeCopy.Annotations[consts.AnnotationNodeMachineReboot]; !ok {
-		return nil
-	}
-
-	delete(nodeCopy.Annotations, consts.AnnotationNodeMachineReboot)
-	return c.Update(context.TODO(), nodeCopy)
-}
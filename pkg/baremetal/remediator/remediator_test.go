@@ -2,24 +2,35 @@ package remediator
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	policyclient "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
 	"k8s.io/client-go/tools/record"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/etcd/simulator"
+	"kubevirt.io/machine-remediation/pkg/utils/drain"
 	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+	"kubevirt.io/machine-remediation/pkg/utils/workloads"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -35,6 +46,7 @@ func newFakeBareMetalRemediator(recorder record.EventRecorder, objects ...runtim
 	return &BareMetalRemediator{
 		client:   fakeClient,
 		recorder: recorder,
+		timeouts: timeouts.NewDefaults(),
 	}
 }
 
@@ -53,6 +65,7 @@ func TestRemediationReboot(t *testing.T) {
 	nodeOnline.Annotations = map[string]string{
 		consts.AnnotationNodeMachineReboot: "",
 	}
+	nodeOnline.Status.NodeInfo.BootID = "bootOnline"
 
 	bareMetalHostOnline := mrtesting.NewBareMetalHost("bareMetalHostOnline", true, true)
 	bareMetalHostOnline.Annotations[consts.AnnotationRebootInProgress] = "true"
@@ -81,6 +94,9 @@ func TestRemediationReboot(t *testing.T) {
 	bareMetalHostNotReady := mrtesting.NewBareMetalHost("bareMetalHostNotReady", true, true)
 	machineNotReady := mrtesting.NewMachine("machineNotReady", nodeNotReady.Name, bareMetalHostNotReady.Name)
 
+	bareMetalHostBooting := mrtesting.NewBareMetalHost("bareMetalHostBooting", true, false)
+	machineBooting := mrtesting.NewMachine("machineBooting", nodeNotReady.Name, bareMetalHostBooting.Name)
+
 	machineRemediationStartedOnline := mrtesting.NewMachineRemediation("machineRemediationStartedOnline", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
 	machineRemediationStartedOffline := mrtesting.NewMachineRemediation("machineRemediationStartedOffline", machineOffline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
 	machineRemediationPoweroffOnline := mrtesting.NewMachineRemediation("machineRemediationPoweroffOnline", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
@@ -89,10 +105,16 @@ func TestRemediationReboot(t *testing.T) {
 	machineRemediationPoweroffTimeout.Status.StartTime = &metav1.Time{
 		Time: machineRemediationPoweroffTimeout.Status.StartTime.Time.Add(-time.Minute * 6),
 	}
-	machineRemediationPoweron := mrtesting.NewMachineRemediation("machineRemediationPoweron", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
-	machineRemediationPoweronTimeout := mrtesting.NewMachineRemediation("machineRemediationPoweronTimeout", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
-	machineRemediationPoweronTimeout.Status.StartTime = &metav1.Time{
-		Time: machineRemediationPoweroffTimeout.Status.StartTime.Time.Add(-time.Minute * 6),
+	machineRemediationPoweron := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweron", machineOnline.Name, time.Now())
+	machineRemediationPoweronSameBoot := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweronSameBoot", machineOnline.Name, time.Now())
+	machineRemediationPoweronSameBoot.Status.NodeBootID = nodeOnline.Status.NodeInfo.BootID
+	machineRemediationPoweronNewBoot := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweronNewBoot", machineOnline.Name, time.Now())
+	machineRemediationPoweronNewBoot.Status.NodeBootID = "bootBeforeReboot"
+	machineRemediationPoweronTimeout := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweronTimeout", machineOnline.Name, time.Now().Add(-time.Minute*11))
+	machineRemediationBooting := mrtesting.NewMachineRemediation("machineRemediationBooting", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	machineRemediationBootTimeout := mrtesting.NewMachineRemediation("machineRemediationBootTimeout", machineBooting.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	machineRemediationBootTimeout.Status.StateTransitionTime = &metav1.Time{
+		Time: time.Now().Add(-time.Minute * 16),
 	}
 	machineRemediationPoweronNotReady := mrtesting.NewMachineRemediation("machineRemediationPoweronNotReady", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
 	machineRemediationSucceeded := mrtesting.NewMachineRemediation("machineRemediationSucceeded", machineOnline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
@@ -206,10 +228,42 @@ func TestRemediationReboot(t *testing.T) {
 			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
 		},
 		{
-			name:               "with machine remediation in power on state and ready node",
+			name:               "with machine remediation in power on state, ready node that did not change after the start and without recorded boot ID",
 			machineRemediation: machineRemediationPoweron,
 			bareMetalHost:      bareMetalHostOnline,
 			node:               nodeOnline,
+			expected: expectedRemediationResult{
+				state:                           mrv1.RemediationStatePowerOn,
+				hasEndTime:                      false,
+				bareMetalHostOnline:             true,
+				nodeDeleted:                     false,
+				machineRemediationDeleted:       false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state, ready node and the same boot ID",
+			machineRemediation: machineRemediationPoweronSameBoot,
+			bareMetalHost:      bareMetalHostOnline,
+			node:               nodeOnline,
+			expected: expectedRemediationResult{
+				state:                           mrv1.RemediationStatePowerOn,
+				hasEndTime:                      false,
+				bareMetalHostOnline:             true,
+				nodeDeleted:                     false,
+				machineRemediationDeleted:       false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state, ready node and new boot ID",
+			machineRemediation: machineRemediationPoweronNewBoot,
+			bareMetalHost:      bareMetalHostOnline,
+			node:               nodeOnline,
 			expected: expectedRemediationResult{
 				state:                           mrv1.RemediationStateSucceeded,
 				hasEndTime:                      true,
@@ -221,6 +275,37 @@ func TestRemediationReboot(t *testing.T) {
 			},
 			expectedEvents: []string{"MachineRemediationRebootSucceeded"},
 		},
+		{
+			name:               "with machine remediation in power on state and host that powered on",
+			machineRemediation: machineRemediationBooting,
+			bareMetalHost:      bareMetalHostOnline,
+			node:               nodeOnline,
+			expected: expectedRemediationResult{
+				state:                           mrv1.RemediationStatePowerOn,
+				hasEndTime:                      false,
+				bareMetalHostOnline:             true,
+				nodeDeleted:                     false,
+				machineRemediationDeleted:       false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state that timeouted on boot",
+			machineRemediation: machineRemediationBootTimeout,
+			bareMetalHost:      bareMetalHostBooting,
+			node:               nodeNotReady,
+			expected: expectedRemediationResult{
+				state:                     mrv1.RemediationStateFailed,
+				hasEndTime:                true,
+				bareMetalHostOnline:       true,
+				nodeDeleted:               false,
+				machineRemediationDeleted: false,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
 		{
 			name:               "with machine remediation in power on state that timeouted",
 			machineRemediation: machineRemediationPoweronTimeout,
@@ -263,7 +348,7 @@ func TestRemediationReboot(t *testing.T) {
 				hasEndTime:                      false,
 				bareMetalHostOnline:             true,
 				nodeDeleted:                     false,
-				machineRemediationDeleted:       true,
+				machineRemediationDeleted:       false,
 				rebootInProgressAnnotationExist: true,
 				nodeRebootAnnotationExist:       true,
 			},
@@ -328,6 +413,7 @@ func TestRemediationReboot(t *testing.T) {
 			machineOnline,
 			machineOffline,
 			machineNotReady,
+			machineBooting,
 			machineOfflineWithRebootAnnotation,
 			machineOnlineWithoutRebootAnnotation,
 			tc.bareMetalHost,
@@ -426,3 +512,914 @@ func TestRemediationReboot(t *testing.T) {
 		}
 	}
 }
+
+type expectedRecreateResult struct {
+	state                     mrv1.RemediationState
+	hasEndTime                bool
+	bareMetalHostImage        bool
+	machineDeleted            bool
+	machineRemediationDeleted bool
+}
+
+func TestRemediationRecreate(t *testing.T) {
+	bareMetalHostProvisioned := mrtesting.NewBareMetalHost("bareMetalHostProvisioned", true, true)
+	bareMetalHostProvisioned.Spec.Image = &bmov1.Image{URL: "http://images/rhcos.qcow2"}
+	bareMetalHostProvisioned.Status.Provisioning.State = bmov1.StateProvisioned
+
+	bareMetalHostReady := mrtesting.NewBareMetalHost("bareMetalHostReady", true, true)
+	bareMetalHostReady.Status.Provisioning.State = bmov1.StateReady
+
+	nodeProvisioned := mrtesting.NewNode("nodeProvisioned", false, "machineProvisioned")
+	machineProvisioned := mrtesting.NewMachine("machineProvisioned", nodeProvisioned.Name, bareMetalHostProvisioned.Name)
+	machineWithoutOwner := mrtesting.NewMachine("machineWithoutOwner", nodeProvisioned.Name, bareMetalHostProvisioned.Name)
+	machineWithoutOwner.OwnerReferences = nil
+	machineReady := mrtesting.NewMachine("machineReady", "", bareMetalHostReady.Name)
+
+	nodeNew := mrtesting.NewNode("nodeNew", true, "machineNew")
+	machineNew := mrtesting.NewMachine("machineNew", nodeNew.Name, bareMetalHostReady.Name)
+	nodeNewNotReady := mrtesting.NewNode("nodeNewNotReady", false, "machineNewNotReady")
+	machineNewNotReady := mrtesting.NewMachine("machineNewNotReady", nodeNewNotReady.Name, bareMetalHostReady.Name)
+
+	bareMetalHostConsumed := mrtesting.NewBareMetalHost("bareMetalHostConsumed", true, true)
+	bareMetalHostConsumed.Status.Provisioning.State = bmov1.StateProvisioned
+	bareMetalHostConsumed.Spec.ConsumerRef = &corev1.ObjectReference{
+		Kind:      "Machine",
+		Name:      machineNew.Name,
+		Namespace: machineNew.Namespace,
+	}
+
+	bareMetalHostConsumedNotReady := bareMetalHostConsumed.DeepCopy()
+	bareMetalHostConsumedNotReady.Name = "bareMetalHostConsumedNotReady"
+	bareMetalHostConsumedNotReady.Spec.ConsumerRef.Name = machineNewNotReady.Name
+
+	bareMetalHostNotConsumed := mrtesting.NewBareMetalHost("bareMetalHostNotConsumed", true, false)
+	bareMetalHostNotConsumed.Status.Provisioning.State = bmov1.StateReady
+
+	newMachineRemediation := func(name string, machineName string, state mrv1.RemediationState, bareMetalHost *bmov1.BareMetalHost) *mrv1.MachineRemediation {
+		mr := mrtesting.NewMachineRemediation(name, machineName, mrv1.RemediationTypeRecreate, state)
+		if bareMetalHost != nil {
+			mr.Annotations = map[string]string{
+				consts.AnnotationBareMetalHost: fmt.Sprintf("%s/%s", bareMetalHost.Namespace, bareMetalHost.Name),
+			}
+		}
+		return mr
+	}
+
+	machineRemediationStarted := newMachineRemediation("machineRemediationStarted", machineProvisioned.Name, mrv1.RemediationStateStarted, nil)
+	machineRemediationStartedWithoutOwner := newMachineRemediation("machineRemediationStartedWithoutOwner", machineWithoutOwner.Name, mrv1.RemediationStateStarted, nil)
+	machineRemediationDeprovisioning := newMachineRemediation("machineRemediationDeprovisioning", machineProvisioned.Name, mrv1.RemediationStateDeprovisioning, bareMetalHostProvisioned)
+	machineRemediationDeprovisioned := newMachineRemediation("machineRemediationDeprovisioned", machineReady.Name, mrv1.RemediationStateDeprovisioning, bareMetalHostReady)
+	machineRemediationDeprovisioningTimeout := newMachineRemediation("machineRemediationDeprovisioningTimeout", machineProvisioned.Name, mrv1.RemediationStateDeprovisioning, bareMetalHostProvisioned)
+	machineRemediationDeprovisioningTimeout.Status.StartTime = &metav1.Time{
+		Time: machineRemediationDeprovisioningTimeout.Status.StartTime.Time.Add(-time.Minute * 61),
+	}
+	machineRemediationProvisioningNotConsumed := newMachineRemediation("machineRemediationProvisioningNotConsumed", machineReady.Name, mrv1.RemediationStateProvisioning, bareMetalHostNotConsumed)
+	machineRemediationProvisioningNotReady := newMachineRemediation("machineRemediationProvisioningNotReady", machineReady.Name, mrv1.RemediationStateProvisioning, bareMetalHostConsumedNotReady)
+	machineRemediationProvisioning := newMachineRemediation("machineRemediationProvisioning", machineReady.Name, mrv1.RemediationStateProvisioning, bareMetalHostConsumed)
+	machineRemediationProvisioningTimeout := newMachineRemediation("machineRemediationProvisioningTimeout", machineReady.Name, mrv1.RemediationStateProvisioning, bareMetalHostNotConsumed)
+	machineRemediationProvisioningTimeout.Status.StartTime = &metav1.Time{
+		Time: machineRemediationProvisioningTimeout.Status.StartTime.Time.Add(-time.Minute * 61),
+	}
+	machineRemediationSucceeded := newMachineRemediation("machineRemediationSucceeded", machineReady.Name, mrv1.RemediationStateSucceeded, bareMetalHostConsumed)
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		bareMetalHost      *bmov1.BareMetalHost
+		machine            *mapiv1.Machine
+		expected           expectedRecreateResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation started",
+			machineRemediation: machineRemediationStarted,
+			bareMetalHost:      bareMetalHostProvisioned,
+			machine:            machineProvisioned,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateDeprovisioning,
+				hasEndTime:         false,
+				bareMetalHostImage: false,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation started and machine without MachineSet owner",
+			machineRemediation: machineRemediationStartedWithoutOwner,
+			bareMetalHost:      bareMetalHostProvisioned,
+			machine:            machineWithoutOwner,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateFailed,
+				hasEndTime:         true,
+				bareMetalHostImage: true,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateFailed"},
+		},
+		{
+			name:               "with machine remediation in deprovisioning state and provisioned host",
+			machineRemediation: machineRemediationDeprovisioning,
+			bareMetalHost:      bareMetalHostProvisioned,
+			machine:            machineProvisioned,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateDeprovisioning,
+				hasEndTime:         false,
+				bareMetalHostImage: true,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in deprovisioning state and deprovisioned host",
+			machineRemediation: machineRemediationDeprovisioned,
+			bareMetalHost:      bareMetalHostReady,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateProvisioning,
+				hasEndTime:         false,
+				bareMetalHostImage: false,
+				machineDeleted:     true,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateMachineDeleted"},
+		},
+		{
+			name:               "with machine remediation in deprovisioning state that timeouted",
+			machineRemediation: machineRemediationDeprovisioningTimeout,
+			bareMetalHost:      bareMetalHostProvisioned,
+			machine:            machineProvisioned,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateFailed,
+				hasEndTime:         true,
+				bareMetalHostImage: true,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateTimedOut"},
+		},
+		{
+			name:               "with machine remediation in provisioning state and host without consumer",
+			machineRemediation: machineRemediationProvisioningNotConsumed,
+			bareMetalHost:      bareMetalHostNotConsumed,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateProvisioning,
+				hasEndTime:         false,
+				bareMetalHostImage: false,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in provisioning state and non ready node",
+			machineRemediation: machineRemediationProvisioningNotReady,
+			bareMetalHost:      bareMetalHostConsumedNotReady,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateProvisioning,
+				hasEndTime:         false,
+				bareMetalHostImage: false,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in provisioning state and ready node",
+			machineRemediation: machineRemediationProvisioning,
+			bareMetalHost:      bareMetalHostConsumed,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateSucceeded,
+				hasEndTime:         true,
+				bareMetalHostImage: false,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateSucceeded"},
+		},
+		{
+			name:               "with machine remediation in provisioning state that timeouted",
+			machineRemediation: machineRemediationProvisioningTimeout,
+			bareMetalHost:      bareMetalHostNotConsumed,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:              mrv1.RemediationStateFailed,
+				hasEndTime:         true,
+				bareMetalHostImage: false,
+				machineDeleted:     false,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateTimedOut"},
+		},
+		{
+			name:               "with machine remediation in succeeded state",
+			machineRemediation: machineRemediationSucceeded,
+			bareMetalHost:      bareMetalHostConsumed,
+			machine:            machineReady,
+			expected: expectedRecreateResult{
+				state:                     mrv1.RemediationStateSucceeded,
+				hasEndTime:                false,
+				bareMetalHostImage:        false,
+				machineDeleted:            false,
+				machineRemediationDeleted: false,
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		bmr := newFakeBareMetalRemediator(
+			recorder,
+			nodeProvisioned,
+			nodeNew,
+			nodeNewNotReady,
+			machineNew,
+			machineNewNotReady,
+			tc.machine,
+			tc.bareMetalHost,
+			tc.machineRemediation,
+		)
+
+		err := bmr.Recreate(context.TODO(), tc.machineRemediation)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		err = bmr.client.Get(context.TODO(), key, newMachineRemediation)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineRemediationDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine remediation %s deleted: %t", tc.name, tc.machineRemediation.Name, tc.expected.machineRemediationDeleted)
+		}
+
+		if !tc.expected.machineRemediationDeleted {
+			if newMachineRemediation.Status.State != tc.expected.state {
+				t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+			}
+
+			if _, ok := newMachineRemediation.Annotations[consts.AnnotationBareMetalHost]; !ok && tc.expected.state != mrv1.RemediationStateFailed {
+				t.Errorf("%s failed, expected MachineRemediation to have %q annotation", tc.name, consts.AnnotationBareMetalHost)
+			}
+		}
+
+		if tc.expected.hasEndTime != (newMachineRemediation.Status.EndTime != nil) {
+			endTimeExpectation := ""
+			if !tc.expected.hasEndTime {
+				endTimeExpectation = "no"
+			}
+			t.Errorf("%s failed, expected %s endTime, got: %s", tc.name, endTimeExpectation, newMachineRemediation.Status.EndTime)
+		}
+
+		newBareMetalHost := &bmov1.BareMetalHost{}
+		key = types.NamespacedName{
+			Namespace: tc.bareMetalHost.Namespace,
+			Name:      tc.bareMetalHost.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newBareMetalHost); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.bareMetalHostImage != (newBareMetalHost.Spec.Image != nil) {
+			t.Errorf("%s failed, expected bare metal host image parameter: %t, got: %v", tc.name, tc.expected.bareMetalHostImage, newBareMetalHost.Spec.Image)
+		}
+
+		machine := &mapiv1.Machine{}
+		key = types.NamespacedName{
+			Namespace: tc.machine.Namespace,
+			Name:      tc.machine.Name,
+		}
+		err = bmr.client.Get(context.TODO(), key, machine)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine %s deleted: %t", tc.name, tc.machine.Name, tc.expected.machineDeleted)
+		}
+	}
+}
+
+func TestRemediationRecreateControlPlane(t *testing.T) {
+	newMasterMachine := func(name string, nodeName string, bareMetalHostName string) *mapiv1.Machine {
+		machine := mrtesting.NewMachine(name, nodeName, bareMetalHostName)
+		machine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+		return machine
+	}
+
+	bareMetalHostProvisioned := mrtesting.NewBareMetalHost("bareMetalHostProvisioned", true, true)
+	bareMetalHostProvisioned.Spec.Image = &bmov1.Image{URL: "http://images/rhcos.qcow2"}
+	bareMetalHostProvisioned.Status.Provisioning.State = bmov1.StateProvisioned
+
+	nodeMaster := mrtesting.NewNode("nodeMaster", false, "machineMaster")
+	machineMaster := newMasterMachine("machineMaster", nodeMaster.Name, bareMetalHostProvisioned.Name)
+
+	nodeMasterNew := mrtesting.NewNode("nodeMasterNew", true, "machineMasterNew")
+	machineMasterNew := newMasterMachine("machineMasterNew", nodeMasterNew.Name, bareMetalHostProvisioned.Name)
+
+	bareMetalHostConsumed := mrtesting.NewBareMetalHost("bareMetalHostConsumed", true, true)
+	bareMetalHostConsumed.Status.Provisioning.State = bmov1.StateProvisioned
+	bareMetalHostConsumed.Spec.ConsumerRef = &corev1.ObjectReference{
+		Kind:      "Machine",
+		Name:      machineMasterNew.Name,
+		Namespace: machineMasterNew.Namespace,
+	}
+
+	newMachineRemediation := func(name string, state mrv1.RemediationState, bareMetalHost *bmov1.BareMetalHost) *mrv1.MachineRemediation {
+		mr := mrtesting.NewMachineRemediation(name, machineMaster.Name, mrv1.RemediationTypeRecreate, state)
+		if bareMetalHost != nil {
+			mr.Annotations = map[string]string{
+				consts.AnnotationBareMetalHost: fmt.Sprintf("%s/%s", bareMetalHost.Namespace, bareMetalHost.Name),
+			}
+		}
+		return mr
+	}
+
+	memberMaster := simulator.NewMember(1, "etcd-member-"+nodeMaster.Name, "192.168.111.20")
+	memberOther := simulator.NewMember(2, "etcd-member-nodeOther", "192.168.111.21")
+	memberMasterNew := simulator.NewMember(3, "etcd-member-"+nodeMasterNew.Name, "192.168.111.22")
+	// the member that was added for the new node, but did not start yet
+	memberMasterNewNotStarted := etcd.Member{ID: 3, PeerURLs: []string{"https://192.168.111.22:2380"}}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		bareMetalHost      *bmov1.BareMetalHost
+		withoutEtcd        bool
+		members            []etcd.Member
+		expectedState      mrv1.RemediationState
+		expectedImage      bool
+		expectedMembers    []etcd.MemberID
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation started",
+			machineRemediation: newMachineRemediation("machineRemediationStarted", mrv1.RemediationStateStarted, nil),
+			bareMetalHost:      bareMetalHostProvisioned,
+			members:            []etcd.Member{memberMaster, memberOther},
+			expectedState:      mrv1.RemediationStateEtcdMemberRemoval,
+			expectedImage:      true,
+			expectedMembers:    []etcd.MemberID{1, 2},
+			expectedEvents:     []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation started without etcd client",
+			machineRemediation: newMachineRemediation("machineRemediationStarted", mrv1.RemediationStateStarted, nil),
+			bareMetalHost:      bareMetalHostProvisioned,
+			withoutEtcd:        true,
+			members:            []etcd.Member{memberMaster, memberOther},
+			expectedState:      mrv1.RemediationStateDeprovisioning,
+			expectedImage:      false,
+			expectedMembers:    []etcd.MemberID{1, 2},
+			expectedEvents:     []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation in etcd member removal state",
+			machineRemediation: newMachineRemediation("machineRemediationEtcdMemberRemoval", mrv1.RemediationStateEtcdMemberRemoval, bareMetalHostProvisioned),
+			bareMetalHost:      bareMetalHostProvisioned,
+			members:            []etcd.Member{memberMaster, memberOther},
+			expectedState:      mrv1.RemediationStateDeprovisioning,
+			expectedImage:      false,
+			expectedMembers:    []etcd.MemberID{2},
+			expectedEvents:     []string{"MachineRemediationEtcdMemberRemoved"},
+		},
+		{
+			name:               "with machine remediation in etcd member removal state and removed member",
+			machineRemediation: newMachineRemediation("machineRemediationEtcdMemberRemoval", mrv1.RemediationStateEtcdMemberRemoval, bareMetalHostProvisioned),
+			bareMetalHost:      bareMetalHostProvisioned,
+			members:            []etcd.Member{memberOther},
+			expectedState:      mrv1.RemediationStateDeprovisioning,
+			expectedImage:      false,
+			expectedMembers:    []etcd.MemberID{2},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with machine remediation in provisioning state and ready node",
+			machineRemediation: newMachineRemediation("machineRemediationProvisioning", mrv1.RemediationStateProvisioning, bareMetalHostConsumed),
+			bareMetalHost:      bareMetalHostConsumed,
+			members:            []etcd.Member{memberOther},
+			expectedState:      mrv1.RemediationStateEtcdMemberRejoin,
+			expectedMembers:    []etcd.MemberID{2},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with machine remediation in etcd member rejoin state and not started member",
+			machineRemediation: newMachineRemediation("machineRemediationEtcdMemberRejoin", mrv1.RemediationStateEtcdMemberRejoin, bareMetalHostConsumed),
+			bareMetalHost:      bareMetalHostConsumed,
+			members:            []etcd.Member{memberOther, memberMasterNewNotStarted},
+			expectedState:      mrv1.RemediationStateEtcdMemberRejoin,
+			expectedMembers:    []etcd.MemberID{2, 3},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with machine remediation in etcd member rejoin state and started member",
+			machineRemediation: newMachineRemediation("machineRemediationEtcdMemberRejoin", mrv1.RemediationStateEtcdMemberRejoin, bareMetalHostConsumed),
+			bareMetalHost:      bareMetalHostConsumed,
+			members:            []etcd.Member{memberOther, memberMasterNew},
+			expectedState:      mrv1.RemediationStateSucceeded,
+			expectedMembers:    []etcd.MemberID{2, 3},
+			expectedEvents:     []string{"MachineRemediationEtcdMemberRejoined", "MachineRemediationRecreateSucceeded"},
+		},
+	}
+
+	for _, tc := range testCases {
+		etcdSimulator := simulator.NewSimulator(tc.members...)
+
+		recorder := record.NewFakeRecorder(10)
+		bmr := newFakeBareMetalRemediator(
+			recorder,
+			nodeMaster,
+			machineMaster,
+			nodeMasterNew,
+			machineMasterNew,
+			tc.bareMetalHost,
+			tc.machineRemediation,
+		)
+		if !tc.withoutEtcd {
+			etcdClient, err := etcd.NewClient([]string{etcdSimulator.Endpoint()}, nil)
+			if err != nil {
+				t.Fatalf("%s failed, expected no error, got: %v", tc.name, err)
+			}
+			bmr.etcd = etcdClient
+		}
+
+		err := bmr.Recreate(context.TODO(), tc.machineRemediation)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newMachineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if newMachineRemediation.Status.State != tc.expectedState {
+			t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expectedState, newMachineRemediation.Status.State)
+		}
+
+		newBareMetalHost := &bmov1.BareMetalHost{}
+		key = types.NamespacedName{
+			Namespace: tc.bareMetalHost.Namespace,
+			Name:      tc.bareMetalHost.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newBareMetalHost); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expectedImage != (newBareMetalHost.Spec.Image != nil) {
+			t.Errorf("%s failed, expected bare metal host image parameter: %t, got: %v", tc.name, tc.expectedImage, newBareMetalHost.Spec.Image)
+		}
+
+		var members []etcd.MemberID
+		for _, member := range etcdSimulator.Members() {
+			members = append(members, member.ID)
+		}
+		if !reflect.DeepEqual(members, tc.expectedMembers) {
+			t.Errorf("%s failed, expected etcd members: %v, got: %v", tc.name, tc.expectedMembers, members)
+		}
+
+		etcdSimulator.Close()
+	}
+}
+
+// fakeEvictions deletes evicted pods, except pods with the blocked label
+type fakeEvictions struct {
+	client client.Client
+}
+
+func (f *fakeEvictions) Evictions(namespace string) policyclient.EvictionInterface {
+	return f
+}
+
+func (f *fakeEvictions) Evict(eviction *policyv1beta1.Eviction) error {
+	pod := &corev1.Pod{}
+	key := client.ObjectKey{Namespace: eviction.Namespace, Name: eviction.Name}
+	if err := f.client.Get(context.TODO(), key, pod); err != nil {
+		return err
+	}
+
+	if _, ok := pod.Labels["blocked"]; ok {
+		return errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+	}
+	return f.client.Delete(context.TODO(), pod)
+}
+
+func newPod(name string, nodeName string, blocked bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+	if blocked {
+		pod.Labels["blocked"] = ""
+	}
+	return pod
+}
+
+type expectedDrainResult struct {
+	state               mrv1.RemediationState
+	bareMetalHostOnline bool
+	nodeUnschedulable   bool
+	podsLeft            int
+}
+
+func TestRemediationRebootDrain(t *testing.T) {
+	nodeWithPods := mrtesting.NewNode("nodeWithPods", false, "machineWithPods")
+	bareMetalHostWithPods := mrtesting.NewBareMetalHost("bareMetalHostWithPods", true, true)
+	machineWithPods := mrtesting.NewMachine("machineWithPods", nodeWithPods.Name, bareMetalHostWithPods.Name)
+
+	nodeWithBlockedPod := mrtesting.NewNode("nodeWithBlockedPod", false, "machineWithBlockedPod")
+	bareMetalHostWithBlockedPod := mrtesting.NewBareMetalHost("bareMetalHostWithBlockedPod", true, true)
+	machineWithBlockedPod := mrtesting.NewMachine("machineWithBlockedPod", nodeWithBlockedPod.Name, bareMetalHostWithBlockedPod.Name)
+
+	nodeWithoutPods := mrtesting.NewNode("nodeWithoutPods", false, "machineWithoutPods")
+	bareMetalHostWithoutPods := mrtesting.NewBareMetalHost("bareMetalHostWithoutPods", true, true)
+	machineWithoutPods := mrtesting.NewMachine("machineWithoutPods", nodeWithoutPods.Name, bareMetalHostWithoutPods.Name)
+
+	machineRemediationStarted := mrtesting.NewMachineRemediation("machineRemediationStarted", machineWithPods.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
+	machineRemediationDrainingWithPods := mrtesting.NewMachineRemediation("machineRemediationDrainingWithPods", machineWithPods.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateDraining)
+	machineRemediationDrainingWithoutPods := mrtesting.NewMachineRemediation("machineRemediationDrainingWithoutPods", machineWithoutPods.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateDraining)
+	machineRemediationDrainingBlocked := mrtesting.NewMachineRemediation("machineRemediationDrainingBlocked", machineWithBlockedPod.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateDraining)
+	machineRemediationDrainingTimeout := mrtesting.NewMachineRemediation("machineRemediationDrainingTimeout", machineWithBlockedPod.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateDraining)
+	machineRemediationDrainingTimeout.Status.StartTime = &metav1.Time{
+		Time: machineRemediationDrainingTimeout.Status.StartTime.Time.Add(-time.Minute * 3),
+	}
+
+	policyWithoutDrain := mrtesting.NewMachineRemediationPolicy("policyWithoutDrain", nil)
+	policyWithoutDrain.Spec.Drain = &mrv1.DrainPolicy{}
+	policyWithLongDrain := mrtesting.NewMachineRemediationPolicy("policyWithLongDrain", nil)
+	policyWithLongDrain.Spec.Drain = &mrv1.DrainPolicy{Timeout: metav1.Duration{Duration: 10 * time.Minute}}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		bareMetalHost      *bmov1.BareMetalHost
+		node               *corev1.Node
+		policy             *mrv1.MachineRemediationPolicy
+		expected           expectedDrainResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation started and drain enabled",
+			machineRemediation: machineRemediationStarted,
+			bareMetalHost:      bareMetalHostWithPods,
+			node:               nodeWithPods,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStateDraining,
+				bareMetalHostOnline: true,
+				nodeUnschedulable:   false,
+				podsLeft:            2,
+			},
+			expectedEvents: []string{"MachineRemediationDrainStarted"},
+		},
+		{
+			name:               "with machine remediation in draining state and node with pods",
+			machineRemediation: machineRemediationDrainingWithPods,
+			bareMetalHost:      bareMetalHostWithPods,
+			node:               nodeWithPods,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStateDraining,
+				bareMetalHostOnline: true,
+				nodeUnschedulable:   true,
+				podsLeft:            0,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in draining state and node without pods",
+			machineRemediation: machineRemediationDrainingWithoutPods,
+			bareMetalHost:      bareMetalHostWithoutPods,
+			node:               nodeWithoutPods,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStatePowerOff,
+				bareMetalHostOnline: false,
+				nodeUnschedulable:   true,
+				podsLeft:            0,
+			},
+			expectedEvents: []string{"MachineRemediationDrainSucceeded", "MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation in draining state and pod blocked by disruption budget",
+			machineRemediation: machineRemediationDrainingBlocked,
+			bareMetalHost:      bareMetalHostWithBlockedPod,
+			node:               nodeWithBlockedPod,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStateDraining,
+				bareMetalHostOnline: true,
+				nodeUnschedulable:   true,
+				podsLeft:            1,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in draining state that timeouted",
+			machineRemediation: machineRemediationDrainingTimeout,
+			bareMetalHost:      bareMetalHostWithBlockedPod,
+			node:               nodeWithBlockedPod,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStatePowerOff,
+				bareMetalHostOnline: false,
+				nodeUnschedulable:   false,
+				podsLeft:            1,
+			},
+			expectedEvents: []string{"MachineRemediationDrainTimedOut", "MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation started and drain disabled by the policy",
+			machineRemediation: machineRemediationStarted,
+			bareMetalHost:      bareMetalHostWithPods,
+			node:               nodeWithPods,
+			policy:             policyWithoutDrain,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStatePowerOff,
+				bareMetalHostOnline: false,
+				nodeUnschedulable:   false,
+				podsLeft:            2,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation in draining state and drain timeout of the policy",
+			machineRemediation: machineRemediationDrainingTimeout,
+			bareMetalHost:      bareMetalHostWithBlockedPod,
+			node:               nodeWithBlockedPod,
+			policy:             policyWithLongDrain,
+			expected: expectedDrainResult{
+				state:               mrv1.RemediationStateDraining,
+				bareMetalHostOnline: true,
+				nodeUnschedulable:   true,
+				podsLeft:            1,
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		objects := []runtime.Object{
+			tc.node,
+			machineWithPods,
+			machineWithBlockedPod,
+			machineWithoutPods,
+			newPod("pod1", nodeWithPods.Name, false),
+			newPod("pod2", nodeWithPods.Name, false),
+			newPod("blocked", nodeWithBlockedPod.Name, true),
+			tc.bareMetalHost,
+			tc.machineRemediation,
+		}
+		if tc.policy != nil {
+			objects = append(objects, tc.policy)
+		}
+		bmr := newFakeBareMetalRemediator(recorder, objects...)
+		bmr.drainer = drain.NewFakeDrainer(bmr.client, &fakeEvictions{client: bmr.client}, 2*time.Minute)
+
+		err := bmr.Reboot(context.TODO(), tc.machineRemediation)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newMachineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if newMachineRemediation.Status.State != tc.expected.state {
+			t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+		}
+
+		newBareMetalHost := &bmov1.BareMetalHost{}
+		key = types.NamespacedName{
+			Namespace: tc.bareMetalHost.Namespace,
+			Name:      tc.bareMetalHost.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newBareMetalHost); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.bareMetalHostOnline != newBareMetalHost.Spec.Online {
+			t.Errorf("%s failed, expected bare metal online parameter: %t, got: %t", tc.name, tc.expected.bareMetalHostOnline, newBareMetalHost.Spec.Online)
+		}
+
+		node := &corev1.Node{}
+		key = types.NamespacedName{
+			Namespace: tc.node.Namespace,
+			Name:      tc.node.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, node); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.nodeUnschedulable != node.Spec.Unschedulable {
+			t.Errorf("%s failed, expected node unschedulable: %t, got: %t", tc.name, tc.expected.nodeUnschedulable, node.Spec.Unschedulable)
+		}
+
+		pods := &corev1.PodList{}
+		if err := bmr.client.List(context.TODO(), pods); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		podsLeft := 0
+		for _, pod := range pods.Items {
+			if pod.Spec.NodeName == tc.node.Name {
+				podsLeft++
+			}
+		}
+		if podsLeft != tc.expected.podsLeft {
+			t.Errorf("%s failed, expected %d pods left on the node, got: %d", tc.name, tc.expected.podsLeft, podsLeft)
+		}
+	}
+}
+
+type expectedReleaseResult struct {
+	state             mrv1.RemediationState
+	message           string
+	podsLeft          int
+	volumeAttachments int
+	nodeLabels        map[string]string
+}
+
+func TestRemediationRebootReleaseWorkloads(t *testing.T) {
+	nodeNotReady := mrtesting.NewNode("nodeNotReady", false, "machineNotReady")
+	nodeNotReady.Labels = map[string]string{"node": "label"}
+	nodeNotReady.Annotations[consts.AnnotationNodeMachineReboot] = ""
+	bareMetalHostOffline := mrtesting.NewBareMetalHost("bareMetalHostOffline", false, false)
+	machineNotReady := mrtesting.NewMachine("machineNotReady", nodeNotReady.Name, bareMetalHostOffline.Name)
+
+	nodeReadyBeforeStart := mrtesting.NewNode("nodeReadyBeforeStart", true, "machineReadyBeforeStart")
+	nodeReadyBeforeStart.Labels = map[string]string{"node": "label"}
+	bareMetalHostReadyBeforeStart := mrtesting.NewBareMetalHost("bareMetalHostReadyBeforeStart", true, true)
+	machineReadyBeforeStart := mrtesting.NewMachine("machineReadyBeforeStart", nodeReadyBeforeStart.Name, bareMetalHostReadyBeforeStart.Name)
+
+	nodeReady := mrtesting.NewNode("nodeReady", true, "machineReady")
+	nodeReady.Labels = map[string]string{"node": "label"}
+	nodeReady.Status.Conditions[0].LastTransitionTime = metav1.Time{Time: time.Now().Add(time.Minute)}
+	bareMetalHostReady := mrtesting.NewBareMetalHost("bareMetalHostReady", true, true)
+	machineReady := mrtesting.NewMachine("machineReady", nodeReady.Name, bareMetalHostReady.Name)
+
+	machineRemediationPoweroff := mrtesting.NewMachineRemediation("machineRemediationPoweroff", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	machineRemediationPoweronReadyBeforeStart := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweronReadyBeforeStart", machineReadyBeforeStart.Name, time.Now())
+	machineRemediationPoweronReady := mrtesting.NewPoweredOnMachineRemediation("machineRemediationPoweronReady", machineReady.Name, time.Now())
+	machineRemediationPoweronReady.Spec.SavedLabels = map[string]string{"saved": "label"}
+
+	volumeAttachment := &storagev1.VolumeAttachment{
+		TypeMeta:   metav1.TypeMeta{Kind: "VolumeAttachment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "volumeAttachment"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			NodeName: nodeNotReady.Name,
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		node               *corev1.Node
+		expected           expectedReleaseResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation in power off state and host has power off state",
+			machineRemediation: machineRemediationPoweroff,
+			node:               nodeNotReady,
+			expected: expectedReleaseResult{
+				state:             mrv1.RemediationStatePowerOn,
+				message:           `Reboot in progress, released 2 pods and 1 volume attachments of node "nodeNotReady"`,
+				podsLeft:          0,
+				volumeAttachments: 0,
+				nodeLabels:        map[string]string{"node": "label"},
+			},
+			expectedEvents: []string{
+				"MachineRemediationPodsDeleted",
+				"MachineRemediationVolumeAttachmentsDeleted",
+				"MachineRemediationRebootPoweringOn",
+			},
+		},
+		{
+			name:               "with machine remediation in power on state and node that was ready before the remediation",
+			machineRemediation: machineRemediationPoweronReadyBeforeStart,
+			node:               nodeReadyBeforeStart,
+			expected: expectedReleaseResult{
+				state:             mrv1.RemediationStatePowerOn,
+				podsLeft:          0,
+				volumeAttachments: 1,
+				nodeLabels:        map[string]string{"node": "label"},
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state and ready node",
+			machineRemediation: machineRemediationPoweronReady,
+			node:               nodeReady,
+			expected: expectedReleaseResult{
+				state:             mrv1.RemediationStateSucceeded,
+				message:           "Reboot succeeded",
+				podsLeft:          0,
+				volumeAttachments: 1,
+				nodeLabels:        map[string]string{"node": "label"},
+			},
+			expectedEvents: []string{"MachineRemediationRebootSucceeded"},
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		bmr := newFakeBareMetalRemediator(
+			recorder,
+			tc.node,
+			machineNotReady,
+			machineReadyBeforeStart,
+			machineReady,
+			bareMetalHostOffline,
+			bareMetalHostReadyBeforeStart,
+			bareMetalHostReady,
+			newPod("pod1", nodeNotReady.Name, false),
+			newPod("pod2", nodeNotReady.Name, true),
+			volumeAttachment,
+			tc.machineRemediation,
+		)
+		bmr.releaser = workloads.NewFakeReleaser(bmr.client)
+
+		err := bmr.Reboot(context.TODO(), tc.machineRemediation)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, newMachineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if newMachineRemediation.Status.State != tc.expected.state {
+			t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+		}
+
+		if newMachineRemediation.Status.Message != tc.expected.message {
+			t.Errorf("%s failed, expected MachineRemediation message: %q, got: %q", tc.name, tc.expected.message, newMachineRemediation.Status.Message)
+		}
+
+		node := &corev1.Node{}
+		key = types.NamespacedName{
+			Namespace: tc.node.Namespace,
+			Name:      tc.node.Name,
+		}
+		if err := bmr.client.Get(context.TODO(), key, node); err != nil {
+			t.Errorf("%s failed, expected node %q to exist, got: %v", tc.name, tc.node.Name, err)
+		}
+
+		if !reflect.DeepEqual(node.Labels, tc.expected.nodeLabels) {
+			t.Errorf("%s failed, expected node labels: %v, got: %v", tc.name, tc.expected.nodeLabels, node.Labels)
+		}
+
+		pods := &corev1.PodList{}
+		if err := bmr.client.List(context.TODO(), pods); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		podsLeft := 0
+		for _, pod := range pods.Items {
+			if pod.Spec.NodeName == tc.node.Name {
+				podsLeft++
+			}
+		}
+		if podsLeft != tc.expected.podsLeft {
+			t.Errorf("%s failed, expected %d pods left on the node, got: %d", tc.name, tc.expected.podsLeft, podsLeft)
+		}
+
+		volumeAttachments := &storagev1.VolumeAttachmentList{}
+		if err := bmr.client.List(context.TODO(), volumeAttachments); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if len(volumeAttachments.Items) != tc.expected.volumeAttachments {
+			t.Errorf("%s failed, expected %d volume attachments, got: %d", tc.name, tc.expected.volumeAttachments, len(volumeAttachments.Items))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package remediator
+
+import (
+	"github.com/golang/glog"
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/remediations"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Watch maps changes of bare metal hosts to machine remediations in progress of their machines
+func (bmr *BareMetalRemediator) Watch(c controller.Controller) error {
+	return c.Watch(
+		&source.Kind{Type: &bmov1.BareMetalHost{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(bmr.bareMetalHostToRequests)},
+	)
+}
+
+// NeedsPolling returns true when the remediation waits for the drain of the node or for the rejoin
+// of the etcd member, changes of bare metal hosts, machines and nodes drive all other states
+func (bmr *BareMetalRemediator) NeedsPolling(machineRemediation *mrv1.MachineRemediation) bool {
+	switch machineRemediation.Status.State {
+	case mrv1.RemediationStateDraining, mrv1.RemediationStateEtcdMemberRejoin:
+		return true
+	}
+	return false
+}
+
+// bareMetalHostToRequests maps the bare metal host to reconcile requests of machine remediations in progress
+// of the machine that consumes the host, or of the recreated machine that consumed it
+func (bmr *BareMetalRemediator) bareMetalHostToRequests(o handler.MapObject) []reconcile.Request {
+	bmh, ok := o.Object.(*bmov1.BareMetalHost)
+	if !ok {
+		return nil
+	}
+
+	// look for the machine of the host only when some remediation is in progress
+	mrs, err := remediations.GetActiveMachineRemediations(bmr.client)
+	if err != nil {
+		glog.Errorf("Failed to list machine remediations: %v", err)
+		return nil
+	}
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	var machine *mapiv1.Machine
+	if bmh.Spec.ConsumerRef != nil {
+		machine, err = machineapi.GetMachineByConsumerRef(bmr.client, bmh.Spec.ConsumerRef)
+		if err != nil && !errors.IsNotFound(err) {
+			glog.Errorf("Failed to map bare metal host %q to machine remediations: %v", bmh.Name, err)
+		}
+	}
+	return remediations.GetRequests(mrs, machine, bmh.Namespace+"/"+bmh.Name)
+}
@@ -0,0 +1,93 @@
+package remediator
+
+import (
+	"reflect"
+	"testing"
+
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+func TestBareMetalHostToRequests(t *testing.T) {
+	bareMetalHost := mrtesting.NewBareMetalHost("bareMetalHost", true, true)
+	bareMetalHost.Spec.ConsumerRef = &corev1.ObjectReference{Kind: "Machine", Namespace: consts.NamespaceOpenshiftMachineAPI, Name: "machine"}
+	machine := mrtesting.NewMachine("machine", "node", bareMetalHost.Name)
+
+	bareMetalHostRecreated := mrtesting.NewBareMetalHost("bareMetalHostRecreated", true, true)
+	bareMetalHostOther := mrtesting.NewBareMetalHost("bareMetalHostOther", true, true)
+
+	mrReboot := mrtesting.NewMachineRemediation("mrReboot", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mrRecreate := mrtesting.NewMachineRemediation("mrRecreate", "deletedMachine", mrv1.RemediationTypeRecreate, mrv1.RemediationStateProvisioning)
+	mrRecreate.Annotations = map[string]string{
+		consts.AnnotationBareMetalHost: consts.NamespaceOpenshiftMachineAPI + "/" + bareMetalHostRecreated.Name,
+	}
+
+	testCases := []struct {
+		name             string
+		bareMetalHost    *bmov1.BareMetalHost
+		expectedRequests []string
+	}{
+		{
+			name:             "with host of machine under remediation",
+			bareMetalHost:    bareMetalHost,
+			expectedRequests: []string{"mrReboot"},
+		},
+		{
+			name:             "with host of recreated machine",
+			bareMetalHost:    bareMetalHostRecreated,
+			expectedRequests: []string{"mrRecreate"},
+		},
+		{
+			name:             "with host without remediation",
+			bareMetalHost:    bareMetalHostOther,
+			expectedRequests: []string{},
+		},
+	}
+
+	bmr := newFakeBareMetalRemediator(record.NewFakeRecorder(10), bareMetalHost, bareMetalHostRecreated, bareMetalHostOther, machine, mrReboot, mrRecreate)
+	for _, tc := range testCases {
+		names := []string{}
+		for _, request := range bmr.bareMetalHostToRequests(handler.MapObject{Object: tc.bareMetalHost}) {
+			names = append(names, request.Name)
+		}
+		if !reflect.DeepEqual(names, tc.expectedRequests) {
+			t.Errorf("Test case: %s. Expected requests: %v, got: %v", tc.name, tc.expectedRequests, names)
+		}
+	}
+}
+
+func TestNeedsPolling(t *testing.T) {
+	testCases := []struct {
+		state    mrv1.RemediationState
+		expected bool
+	}{
+		{
+			state:    mrv1.RemediationStatePowerOff,
+			expected: false,
+		},
+		{
+			state:    mrv1.RemediationStateDraining,
+			expected: true,
+		},
+		{
+			state:    mrv1.RemediationStateEtcdMemberRejoin,
+			expected: true,
+		},
+	}
+
+	bmr := newFakeBareMetalRemediator(record.NewFakeRecorder(10))
+	for _, tc := range testCases {
+		mr := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, tc.state)
+		if needsPolling := bmr.NeedsPolling(mr); needsPolling != tc.expected {
+			t.Errorf("Test case: %s state. Expected: %t, got: %t", tc.state, tc.expected, needsPolling)
+		}
+	}
+}
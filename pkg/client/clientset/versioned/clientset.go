@@ -25,11 +25,13 @@ import (
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
 	machineremediationv1alpha1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1alpha1"
+	machineremediationv1beta1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1beta1"
 )
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	MachineremediationV1alpha1() machineremediationv1alpha1.MachineremediationV1alpha1Interface
+	MachineremediationV1beta1() machineremediationv1beta1.MachineremediationV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -37,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	machineremediationV1alpha1 *machineremediationv1alpha1.MachineremediationV1alpha1Client
+	machineremediationV1beta1  *machineremediationv1beta1.MachineremediationV1beta1Client
 }
 
 // MachineremediationV1alpha1 retrieves the MachineremediationV1alpha1Client
@@ -44,6 +47,11 @@ func (c *Clientset) MachineremediationV1alpha1() machineremediationv1alpha1.Mach
 	return c.machineremediationV1alpha1
 }
 
+// MachineremediationV1beta1 retrieves the MachineremediationV1beta1Client
+func (c *Clientset) MachineremediationV1beta1() machineremediationv1beta1.MachineremediationV1beta1Interface {
+	return c.machineremediationV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -69,6 +77,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.machineremediationV1beta1, err = machineremediationv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -82,6 +94,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.machineremediationV1alpha1 = machineremediationv1alpha1.NewForConfigOrDie(c)
+	cs.machineremediationV1beta1 = machineremediationv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -91,6 +104,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.machineremediationV1alpha1 = machineremediationv1alpha1.New(c)
+	cs.machineremediationV1beta1 = machineremediationv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -27,6 +27,8 @@ import (
 	clientset "kubevirt.io/machine-remediation/pkg/client/clientset/versioned"
 	machineremediationv1alpha1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1alpha1"
 	fakemachineremediationv1alpha1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1alpha1/fake"
+	machineremediationv1beta1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1beta1"
+	fakemachineremediationv1beta1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1beta1/fake"
 )
 
 // NewSimpleClientset returns a clientset that will respond with the provided objects.
@@ -80,3 +82,8 @@ var _ clientset.Interface = &Clientset{}
 func (c *Clientset) MachineremediationV1alpha1() machineremediationv1alpha1.MachineremediationV1alpha1Interface {
 	return &fakemachineremediationv1alpha1.FakeMachineremediationV1alpha1{Fake: &c.Fake}
 }
+
+// MachineremediationV1beta1 retrieves the MachineremediationV1beta1Client
+func (c *Clientset) MachineremediationV1beta1() machineremediationv1beta1.MachineremediationV1beta1Interface {
+	return &fakemachineremediationv1beta1.FakeMachineremediationV1beta1{Fake: &c.Fake}
+}
@@ -25,6 +25,7 @@ import (
 	serializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	machineremediationv1alpha1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	machineremediationv1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 )
 
 var Scheme = runtime.NewScheme()
@@ -32,6 +33,7 @@ var Codecs = serializer.NewCodecFactory(Scheme)
 var ParameterCodec = runtime.NewParameterCodec(Scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	machineremediationv1alpha1.AddToScheme,
+	machineremediationv1beta1.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -0,0 +1,140 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+)
+
+// FakeMachineDisruptionBudgets implements MachineDisruptionBudgetInterface
+type FakeMachineDisruptionBudgets struct {
+	Fake *FakeMachineremediationV1beta1
+	ns   string
+}
+
+var machinedisruptionbudgetsResource = schema.GroupVersionResource{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Resource: "machinedisruptionbudgets"}
+
+var machinedisruptionbudgetsKind = schema.GroupVersionKind{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Kind: "MachineDisruptionBudget"}
+
+// Get takes name of the machineDisruptionBudget, and returns the corresponding machineDisruptionBudget object, and an error if there is any.
+func (c *FakeMachineDisruptionBudgets) Get(name string, options v1.GetOptions) (result *v1beta1.MachineDisruptionBudget, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(machinedisruptionbudgetsResource, c.ns, name), &v1beta1.MachineDisruptionBudget{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineDisruptionBudget), err
+}
+
+// List takes label and field selectors, and returns the list of MachineDisruptionBudgets that match those selectors.
+func (c *FakeMachineDisruptionBudgets) List(opts v1.ListOptions) (result *v1beta1.MachineDisruptionBudgetList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(machinedisruptionbudgetsResource, machinedisruptionbudgetsKind, c.ns, opts), &v1beta1.MachineDisruptionBudgetList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.MachineDisruptionBudgetList{ListMeta: obj.(*v1beta1.MachineDisruptionBudgetList).ListMeta}
+	for _, item := range obj.(*v1beta1.MachineDisruptionBudgetList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested machineDisruptionBudgets.
+func (c *FakeMachineDisruptionBudgets) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(machinedisruptionbudgetsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a machineDisruptionBudget and creates it.  Returns the server's representation of the machineDisruptionBudget, and an error, if there is any.
+func (c *FakeMachineDisruptionBudgets) Create(machineDisruptionBudget *v1beta1.MachineDisruptionBudget) (result *v1beta1.MachineDisruptionBudget, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(machinedisruptionbudgetsResource, c.ns, machineDisruptionBudget), &v1beta1.MachineDisruptionBudget{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineDisruptionBudget), err
+}
+
+// Update takes the representation of a machineDisruptionBudget and updates it. Returns the server's representation of the machineDisruptionBudget, and an error, if there is any.
+func (c *FakeMachineDisruptionBudgets) Update(machineDisruptionBudget *v1beta1.MachineDisruptionBudget) (result *v1beta1.MachineDisruptionBudget, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(machinedisruptionbudgetsResource, c.ns, machineDisruptionBudget), &v1beta1.MachineDisruptionBudget{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineDisruptionBudget), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeMachineDisruptionBudgets) UpdateStatus(machineDisruptionBudget *v1beta1.MachineDisruptionBudget) (*v1beta1.MachineDisruptionBudget, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(machinedisruptionbudgetsResource, "status", c.ns, machineDisruptionBudget), &v1beta1.MachineDisruptionBudget{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineDisruptionBudget), err
+}
+
+// Delete takes name of the machineDisruptionBudget and deletes it. Returns an error if one occurs.
+func (c *FakeMachineDisruptionBudgets) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(machinedisruptionbudgetsResource, c.ns, name), &v1beta1.MachineDisruptionBudget{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMachineDisruptionBudgets) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(machinedisruptionbudgetsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.MachineDisruptionBudgetList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched machineDisruptionBudget.
+func (c *FakeMachineDisruptionBudgets) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineDisruptionBudget, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(machinedisruptionbudgetsResource, c.ns, name, pt, data, subresources...), &v1beta1.MachineDisruptionBudget{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineDisruptionBudget), err
+}
@@ -0,0 +1,56 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/typed/machineremediation/v1beta1"
+)
+
+type FakeMachineremediationV1beta1 struct {
+	*testing.Fake
+}
+
+func (c *FakeMachineremediationV1beta1) MachineDisruptionBudgets(namespace string) v1beta1.MachineDisruptionBudgetInterface {
+	return &FakeMachineDisruptionBudgets{c, namespace}
+}
+
+func (c *FakeMachineremediationV1beta1) MachineHealthChecks(namespace string) v1beta1.MachineHealthCheckInterface {
+	return &FakeMachineHealthChecks{c, namespace}
+}
+
+func (c *FakeMachineremediationV1beta1) MachineRemediations(namespace string) v1beta1.MachineRemediationInterface {
+	return &FakeMachineRemediations{c, namespace}
+}
+
+func (c *FakeMachineremediationV1beta1) MachineRemediationPolicies(namespace string) v1beta1.MachineRemediationPolicyInterface {
+	return &FakeMachineRemediationPolicies{c, namespace}
+}
+
+func (c *FakeMachineremediationV1beta1) MachineRemediationTemplates(namespace string) v1beta1.MachineRemediationTemplateInterface {
+	return &FakeMachineRemediationTemplates{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeMachineremediationV1beta1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -0,0 +1,140 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+)
+
+// FakeMachineRemediationPolicies implements MachineRemediationPolicyInterface
+type FakeMachineRemediationPolicies struct {
+	Fake *FakeMachineremediationV1beta1
+	ns   string
+}
+
+var machineremediationpoliciesResource = schema.GroupVersionResource{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Resource: "machineremediationpolicies"}
+
+var machineremediationpoliciesKind = schema.GroupVersionKind{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Kind: "MachineRemediationPolicy"}
+
+// Get takes name of the machineRemediationPolicy, and returns the corresponding machineRemediationPolicy object, and an error if there is any.
+func (c *FakeMachineRemediationPolicies) Get(name string, options v1.GetOptions) (result *v1beta1.MachineRemediationPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(machineremediationpoliciesResource, c.ns, name), &v1beta1.MachineRemediationPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of MachineRemediationPolicies that match those selectors.
+func (c *FakeMachineRemediationPolicies) List(opts v1.ListOptions) (result *v1beta1.MachineRemediationPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(machineremediationpoliciesResource, machineremediationpoliciesKind, c.ns, opts), &v1beta1.MachineRemediationPolicyList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.MachineRemediationPolicyList{ListMeta: obj.(*v1beta1.MachineRemediationPolicyList).ListMeta}
+	for _, item := range obj.(*v1beta1.MachineRemediationPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested machineRemediationPolicies.
+func (c *FakeMachineRemediationPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(machineremediationpoliciesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a machineRemediationPolicy and creates it.  Returns the server's representation of the machineRemediationPolicy, and an error, if there is any.
+func (c *FakeMachineRemediationPolicies) Create(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (result *v1beta1.MachineRemediationPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(machineremediationpoliciesResource, c.ns, machineRemediationPolicy), &v1beta1.MachineRemediationPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationPolicy), err
+}
+
+// Update takes the representation of a machineRemediationPolicy and updates it. Returns the server's representation of the machineRemediationPolicy, and an error, if there is any.
+func (c *FakeMachineRemediationPolicies) Update(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (result *v1beta1.MachineRemediationPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(machineremediationpoliciesResource, c.ns, machineRemediationPolicy), &v1beta1.MachineRemediationPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationPolicy), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeMachineRemediationPolicies) UpdateStatus(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (*v1beta1.MachineRemediationPolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(machineremediationpoliciesResource, "status", c.ns, machineRemediationPolicy), &v1beta1.MachineRemediationPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationPolicy), err
+}
+
+// Delete takes name of the machineRemediationPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeMachineRemediationPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(machineremediationpoliciesResource, c.ns, name), &v1beta1.MachineRemediationPolicy{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMachineRemediationPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(machineremediationpoliciesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.MachineRemediationPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched machineRemediationPolicy.
+func (c *FakeMachineRemediationPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(machineremediationpoliciesResource, c.ns, name, pt, data, subresources...), &v1beta1.MachineRemediationPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationPolicy), err
+}
@@ -0,0 +1,128 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+)
+
+// FakeMachineRemediationTemplates implements MachineRemediationTemplateInterface
+type FakeMachineRemediationTemplates struct {
+	Fake *FakeMachineremediationV1beta1
+	ns   string
+}
+
+var machineremediationtemplatesResource = schema.GroupVersionResource{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Resource: "machineremediationtemplates"}
+
+var machineremediationtemplatesKind = schema.GroupVersionKind{Group: "machineremediation.kubevirt.io", Version: "v1beta1", Kind: "MachineRemediationTemplate"}
+
+// Get takes name of the machineRemediationTemplate, and returns the corresponding machineRemediationTemplate object, and an error if there is any.
+func (c *FakeMachineRemediationTemplates) Get(name string, options v1.GetOptions) (result *v1beta1.MachineRemediationTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(machineremediationtemplatesResource, c.ns, name), &v1beta1.MachineRemediationTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationTemplate), err
+}
+
+// List takes label and field selectors, and returns the list of MachineRemediationTemplates that match those selectors.
+func (c *FakeMachineRemediationTemplates) List(opts v1.ListOptions) (result *v1beta1.MachineRemediationTemplateList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(machineremediationtemplatesResource, machineremediationtemplatesKind, c.ns, opts), &v1beta1.MachineRemediationTemplateList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1beta1.MachineRemediationTemplateList{ListMeta: obj.(*v1beta1.MachineRemediationTemplateList).ListMeta}
+	for _, item := range obj.(*v1beta1.MachineRemediationTemplateList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested machineRemediationTemplates.
+func (c *FakeMachineRemediationTemplates) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(machineremediationtemplatesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a machineRemediationTemplate and creates it.  Returns the server's representation of the machineRemediationTemplate, and an error, if there is any.
+func (c *FakeMachineRemediationTemplates) Create(machineRemediationTemplate *v1beta1.MachineRemediationTemplate) (result *v1beta1.MachineRemediationTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(machineremediationtemplatesResource, c.ns, machineRemediationTemplate), &v1beta1.MachineRemediationTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationTemplate), err
+}
+
+// Update takes the representation of a machineRemediationTemplate and updates it. Returns the server's representation of the machineRemediationTemplate, and an error, if there is any.
+func (c *FakeMachineRemediationTemplates) Update(machineRemediationTemplate *v1beta1.MachineRemediationTemplate) (result *v1beta1.MachineRemediationTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(machineremediationtemplatesResource, c.ns, machineRemediationTemplate), &v1beta1.MachineRemediationTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationTemplate), err
+}
+
+// Delete takes name of the machineRemediationTemplate and deletes it. Returns an error if one occurs.
+func (c *FakeMachineRemediationTemplates) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(machineremediationtemplatesResource, c.ns, name), &v1beta1.MachineRemediationTemplate{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMachineRemediationTemplates) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(machineremediationtemplatesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1beta1.MachineRemediationTemplateList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched machineRemediationTemplate.
+func (c *FakeMachineRemediationTemplates) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationTemplate, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(machineremediationtemplatesResource, c.ns, name, pt, data, subresources...), &v1beta1.MachineRemediationTemplate{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.MachineRemediationTemplate), err
+}
@@ -0,0 +1,191 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	scheme "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/scheme"
+)
+
+// MachineHealthChecksGetter has a method to return a MachineHealthCheckInterface.
+// A group's client should implement this interface.
+type MachineHealthChecksGetter interface {
+	MachineHealthChecks(namespace string) MachineHealthCheckInterface
+}
+
+// MachineHealthCheckInterface has methods to work with MachineHealthCheck resources.
+type MachineHealthCheckInterface interface {
+	Create(*v1beta1.MachineHealthCheck) (*v1beta1.MachineHealthCheck, error)
+	Update(*v1beta1.MachineHealthCheck) (*v1beta1.MachineHealthCheck, error)
+	UpdateStatus(*v1beta1.MachineHealthCheck) (*v1beta1.MachineHealthCheck, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.MachineHealthCheck, error)
+	List(opts v1.ListOptions) (*v1beta1.MachineHealthCheckList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineHealthCheck, err error)
+	MachineHealthCheckExpansion
+}
+
+// machineHealthChecks implements MachineHealthCheckInterface
+type machineHealthChecks struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMachineHealthChecks returns a MachineHealthChecks
+func newMachineHealthChecks(c *MachineremediationV1beta1Client, namespace string) *machineHealthChecks {
+	return &machineHealthChecks{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the machineHealthCheck, and returns the corresponding machineHealthCheck object, and an error if there is any.
+func (c *machineHealthChecks) Get(name string, options v1.GetOptions) (result *v1beta1.MachineHealthCheck, err error) {
+	result = &v1beta1.MachineHealthCheck{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MachineHealthChecks that match those selectors.
+func (c *machineHealthChecks) List(opts v1.ListOptions) (result *v1beta1.MachineHealthCheckList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.MachineHealthCheckList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested machineHealthChecks.
+func (c *machineHealthChecks) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a machineHealthCheck and creates it.  Returns the server's representation of the machineHealthCheck, and an error, if there is any.
+func (c *machineHealthChecks) Create(machineHealthCheck *v1beta1.MachineHealthCheck) (result *v1beta1.MachineHealthCheck, err error) {
+	result = &v1beta1.MachineHealthCheck{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		Body(machineHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a machineHealthCheck and updates it. Returns the server's representation of the machineHealthCheck, and an error, if there is any.
+func (c *machineHealthChecks) Update(machineHealthCheck *v1beta1.MachineHealthCheck) (result *v1beta1.MachineHealthCheck, err error) {
+	result = &v1beta1.MachineHealthCheck{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		Name(machineHealthCheck.Name).
+		Body(machineHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *machineHealthChecks) UpdateStatus(machineHealthCheck *v1beta1.MachineHealthCheck) (result *v1beta1.MachineHealthCheck, err error) {
+	result = &v1beta1.MachineHealthCheck{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		Name(machineHealthCheck.Name).
+		SubResource("status").
+		Body(machineHealthCheck).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the machineHealthCheck and deletes it. Returns an error if one occurs.
+func (c *machineHealthChecks) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *machineHealthChecks) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched machineHealthCheck.
+func (c *machineHealthChecks) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineHealthCheck, err error) {
+	result = &v1beta1.MachineHealthCheck{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("machinehealthchecks").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,191 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	scheme "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/scheme"
+)
+
+// MachineRemediationsGetter has a method to return a MachineRemediationInterface.
+// A group's client should implement this interface.
+type MachineRemediationsGetter interface {
+	MachineRemediations(namespace string) MachineRemediationInterface
+}
+
+// MachineRemediationInterface has methods to work with MachineRemediation resources.
+type MachineRemediationInterface interface {
+	Create(*v1beta1.MachineRemediation) (*v1beta1.MachineRemediation, error)
+	Update(*v1beta1.MachineRemediation) (*v1beta1.MachineRemediation, error)
+	UpdateStatus(*v1beta1.MachineRemediation) (*v1beta1.MachineRemediation, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.MachineRemediation, error)
+	List(opts v1.ListOptions) (*v1beta1.MachineRemediationList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediation, err error)
+	MachineRemediationExpansion
+}
+
+// machineRemediations implements MachineRemediationInterface
+type machineRemediations struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMachineRemediations returns a MachineRemediations
+func newMachineRemediations(c *MachineremediationV1beta1Client, namespace string) *machineRemediations {
+	return &machineRemediations{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the machineRemediation, and returns the corresponding machineRemediation object, and an error if there is any.
+func (c *machineRemediations) Get(name string, options v1.GetOptions) (result *v1beta1.MachineRemediation, err error) {
+	result = &v1beta1.MachineRemediation{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MachineRemediations that match those selectors.
+func (c *machineRemediations) List(opts v1.ListOptions) (result *v1beta1.MachineRemediationList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.MachineRemediationList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested machineRemediations.
+func (c *machineRemediations) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a machineRemediation and creates it.  Returns the server's representation of the machineRemediation, and an error, if there is any.
+func (c *machineRemediations) Create(machineRemediation *v1beta1.MachineRemediation) (result *v1beta1.MachineRemediation, err error) {
+	result = &v1beta1.MachineRemediation{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		Body(machineRemediation).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a machineRemediation and updates it. Returns the server's representation of the machineRemediation, and an error, if there is any.
+func (c *machineRemediations) Update(machineRemediation *v1beta1.MachineRemediation) (result *v1beta1.MachineRemediation, err error) {
+	result = &v1beta1.MachineRemediation{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		Name(machineRemediation.Name).
+		Body(machineRemediation).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *machineRemediations) UpdateStatus(machineRemediation *v1beta1.MachineRemediation) (result *v1beta1.MachineRemediation, err error) {
+	result = &v1beta1.MachineRemediation{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		Name(machineRemediation.Name).
+		SubResource("status").
+		Body(machineRemediation).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the machineRemediation and deletes it. Returns an error if one occurs.
+func (c *machineRemediations) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *machineRemediations) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediations").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched machineRemediation.
+func (c *machineRemediations) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediation, err error) {
+	result = &v1beta1.MachineRemediation{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("machineremediations").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,191 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	scheme "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/scheme"
+)
+
+// MachineRemediationPoliciesGetter has a method to return a MachineRemediationPolicyInterface.
+// A group's client should implement this interface.
+type MachineRemediationPoliciesGetter interface {
+	MachineRemediationPolicies(namespace string) MachineRemediationPolicyInterface
+}
+
+// MachineRemediationPolicyInterface has methods to work with MachineRemediationPolicy resources.
+type MachineRemediationPolicyInterface interface {
+	Create(*v1beta1.MachineRemediationPolicy) (*v1beta1.MachineRemediationPolicy, error)
+	Update(*v1beta1.MachineRemediationPolicy) (*v1beta1.MachineRemediationPolicy, error)
+	UpdateStatus(*v1beta1.MachineRemediationPolicy) (*v1beta1.MachineRemediationPolicy, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.MachineRemediationPolicy, error)
+	List(opts v1.ListOptions) (*v1beta1.MachineRemediationPolicyList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationPolicy, err error)
+	MachineRemediationPolicyExpansion
+}
+
+// machineRemediationPolicies implements MachineRemediationPolicyInterface
+type machineRemediationPolicies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMachineRemediationPolicies returns a MachineRemediationPolicies
+func newMachineRemediationPolicies(c *MachineremediationV1beta1Client, namespace string) *machineRemediationPolicies {
+	return &machineRemediationPolicies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the machineRemediationPolicy, and returns the corresponding machineRemediationPolicy object, and an error if there is any.
+func (c *machineRemediationPolicies) Get(name string, options v1.GetOptions) (result *v1beta1.MachineRemediationPolicy, err error) {
+	result = &v1beta1.MachineRemediationPolicy{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MachineRemediationPolicies that match those selectors.
+func (c *machineRemediationPolicies) List(opts v1.ListOptions) (result *v1beta1.MachineRemediationPolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.MachineRemediationPolicyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested machineRemediationPolicies.
+func (c *machineRemediationPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a machineRemediationPolicy and creates it.  Returns the server's representation of the machineRemediationPolicy, and an error, if there is any.
+func (c *machineRemediationPolicies) Create(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (result *v1beta1.MachineRemediationPolicy, err error) {
+	result = &v1beta1.MachineRemediationPolicy{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		Body(machineRemediationPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a machineRemediationPolicy and updates it. Returns the server's representation of the machineRemediationPolicy, and an error, if there is any.
+func (c *machineRemediationPolicies) Update(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (result *v1beta1.MachineRemediationPolicy, err error) {
+	result = &v1beta1.MachineRemediationPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		Name(machineRemediationPolicy.Name).
+		Body(machineRemediationPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *machineRemediationPolicies) UpdateStatus(machineRemediationPolicy *v1beta1.MachineRemediationPolicy) (result *v1beta1.MachineRemediationPolicy, err error) {
+	result = &v1beta1.MachineRemediationPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		Name(machineRemediationPolicy.Name).
+		SubResource("status").
+		Body(machineRemediationPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the machineRemediationPolicy and deletes it. Returns an error if one occurs.
+func (c *machineRemediationPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *machineRemediationPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched machineRemediationPolicy.
+func (c *machineRemediationPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationPolicy, err error) {
+	result = &v1beta1.MachineRemediationPolicy{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("machineremediationpolicies").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,174 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2019 Red Hat, Inc.
+ *
+ */
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1beta1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	scheme "kubevirt.io/machine-remediation/pkg/client/clientset/versioned/scheme"
+)
+
+// MachineRemediationTemplatesGetter has a method to return a MachineRemediationTemplateInterface.
+// A group's client should implement this interface.
+type MachineRemediationTemplatesGetter interface {
+	MachineRemediationTemplates(namespace string) MachineRemediationTemplateInterface
+}
+
+// MachineRemediationTemplateInterface has methods to work with MachineRemediationTemplate resources.
+type MachineRemediationTemplateInterface interface {
+	Create(*v1beta1.MachineRemediationTemplate) (*v1beta1.MachineRemediationTemplate, error)
+	Update(*v1beta1.MachineRemediationTemplate) (*v1beta1.MachineRemediationTemplate, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1beta1.MachineRemediationTemplate, error)
+	List(opts v1.ListOptions) (*v1beta1.MachineRemediationTemplateList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationTemplate, err error)
+	MachineRemediationTemplateExpansion
+}
+
+// machineRemediationTemplates implements MachineRemediationTemplateInterface
+type machineRemediationTemplates struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMachineRemediationTemplates returns a MachineRemediationTemplates
+func newMachineRemediationTemplates(c *MachineremediationV1beta1Client, namespace string) *machineRemediationTemplates {
+	return &machineRemediationTemplates{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the machineRemediationTemplate, and returns the corresponding machineRemediationTemplate object, and an error if there is any.
+func (c *machineRemediationTemplates) Get(name string, options v1.GetOptions) (result *v1beta1.MachineRemediationTemplate, err error) {
+	result = &v1beta1.MachineRemediationTemplate{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MachineRemediationTemplates that match those selectors.
+func (c *machineRemediationTemplates) List(opts v1.ListOptions) (result *v1beta1.MachineRemediationTemplateList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1beta1.MachineRemediationTemplateList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested machineRemediationTemplates.
+func (c *machineRemediationTemplates) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a machineRemediationTemplate and creates it.  Returns the server's representation of the machineRemediationTemplate, and an error, if there is any.
+func (c *machineRemediationTemplates) Create(machineRemediationTemplate *v1beta1.MachineRemediationTemplate) (result *v1beta1.MachineRemediationTemplate, err error) {
+	result = &v1beta1.MachineRemediationTemplate{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		Body(machineRemediationTemplate).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a machineRemediationTemplate and updates it. Returns the server's representation of the machineRemediationTemplate, and an error, if there is any.
+func (c *machineRemediationTemplates) Update(machineRemediationTemplate *v1beta1.MachineRemediationTemplate) (result *v1beta1.MachineRemediationTemplate, err error) {
+	result = &v1beta1.MachineRemediationTemplate{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		Name(machineRemediationTemplate.Name).
+		Body(machineRemediationTemplate).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the machineRemediationTemplate and deletes it. Returns an error if one occurs.
+func (c *machineRemediationTemplates) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *machineRemediationTemplates) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched machineRemediationTemplate.
+func (c *machineRemediationTemplates) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1beta1.MachineRemediationTemplate, err error) {
+	result = &v1beta1.MachineRemediationTemplate{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("machineremediationtemplates").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -9,7 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 )
 
 // DeploymentData contains all needed data to create new deployment object
@@ -21,6 +21,15 @@ type DeploymentData struct {
 	Verbosity  string
 }
 
+const (
+	// webhookPort contains the port that the webhook server of the controller serves at
+	webhookPort = 9443
+	// webhookCertDir contains the directory that the webhook server certificate is mounted to
+	webhookCertDir = "/etc/machine-remediation/webhook-certs"
+	// webhookCertVolume contains the name of the volume with the webhook server certificate
+	webhookCertVolume = "webhook-certs"
+)
+
 // NewDeployment returns new deployment object
 func NewDeployment(data *DeploymentData) *appsv1.Deployment {
 	template := newPodTemplateSpec(data)
@@ -107,6 +116,16 @@ func newPodTemplateSpec(data *DeploymentData) *corev1.PodTemplateSpec {
 			},
 			Containers:   containers,
 			NodeSelector: map[string]string{"node-role.kubernetes.io/master": ""},
+			Volumes: []corev1.Volume{
+				{
+					Name: webhookCertVolume,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: webhookCertSecretName(data.Name),
+						},
+					},
+				},
+			},
 			SecurityContext: &corev1.PodSecurityContext{
 				RunAsNonRoot: pointer.BoolPtr(true),
 			},
@@ -127,6 +146,9 @@ func newContainers(data *DeploymentData) []corev1.Container {
 		"--logtostderr=true",
 		fmt.Sprintf("--v=%s", data.Verbosity),
 		fmt.Sprintf("--namespace=%s", data.Namespace),
+		fmt.Sprintf("--webhook-service=%s/%s", data.Namespace, webhookServiceName(data.Name)),
+		fmt.Sprintf("--webhook-port=%d", webhookPort),
+		fmt.Sprintf("--webhook-cert-dir=%s", webhookCertDir),
 	}
 
 	containers := []corev1.Container{
@@ -137,6 +159,20 @@ func newContainers(data *DeploymentData) []corev1.Container {
 			Args:            args,
 			Resources:       resources,
 			ImagePullPolicy: data.PullPolicy,
+			Ports: []corev1.ContainerPort{
+				{
+					Name:          "webhook-server",
+					ContainerPort: webhookPort,
+					Protocol:      corev1.ProtocolTCP,
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      webhookCertVolume,
+					MountPath: webhookCertDir,
+					ReadOnly:  true,
+				},
+			},
 		},
 	}
 	return containers
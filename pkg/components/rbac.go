@@ -5,7 +5,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 )
 
 var (
@@ -25,6 +25,44 @@ var (
 					"watch",
 				},
 			},
+			{
+				APIGroups: []string{
+					"cluster.x-k8s.io",
+				},
+				Resources: []string{
+					"machines",
+				},
+				Verbs: []string{
+					"delete",
+					"get",
+					"list",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"cluster.x-k8s.io",
+				},
+				Resources: []string{
+					"machines/status",
+				},
+				Verbs: []string{
+					"update",
+				},
+			},
+			{
+				APIGroups: []string{
+					"infrastructure.cluster.x-k8s.io",
+				},
+				Resources: []string{
+					"metal3machines",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
 			{
 				APIGroups: []string{
 					"machineremediation.kubevirt.io",
@@ -42,6 +80,62 @@ var (
 					"watch",
 				},
 			},
+			{
+				APIGroups: []string{
+					"machineremediation.kubevirt.io",
+				},
+				Resources: []string{
+					"machineremediations",
+				},
+				Verbs: []string{
+					"remediate-control-plane",
+				},
+			},
+			{
+				APIGroups: []string{
+					"machineremediation.kubevirt.io",
+				},
+				Resources: []string{
+					"machineremediationpolicies",
+					"machineremediationpolicies/status",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"update",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"machineremediation.kubevirt.io",
+				},
+				Resources: []string{
+					"machinehealthchecks",
+					"machinehealthchecks/status",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"update",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"machineremediation.kubevirt.io",
+				},
+				Resources: []string{
+					"machinedisruptionbudgets",
+					"machinedisruptionbudgets/status",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"update",
+					"watch",
+				},
+			},
 			{
 				APIGroups: []string{
 					"",
@@ -53,9 +147,47 @@ var (
 					"delete",
 					"get",
 					"list",
+					"update",
 					"watch",
 				},
 			},
+			{
+				APIGroups: []string{
+					"",
+				},
+				Resources: []string{
+					"pods",
+				},
+				Verbs: []string{
+					"delete",
+					"get",
+					"list",
+				},
+			},
+			{
+				APIGroups: []string{
+					"",
+				},
+				Resources: []string{
+					"pods/eviction",
+				},
+				Verbs: []string{
+					"create",
+				},
+			},
+			{
+				APIGroups: []string{
+					"storage.k8s.io",
+				},
+				Resources: []string{
+					"volumeattachments",
+				},
+				Verbs: []string{
+					"delete",
+					"get",
+					"list",
+				},
+			},
 			{
 				APIGroups: []string{
 					"metal3.io",
@@ -70,6 +202,61 @@ var (
 					"watch",
 				},
 			},
+			{
+				APIGroups: []string{
+					"kubevirt.io",
+				},
+				Resources: []string{
+					"virtualmachines",
+				},
+				Verbs: []string{
+					"create",
+					"delete",
+					"get",
+					"list",
+					"update",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"kubevirt.io",
+				},
+				Resources: []string{
+					"virtualmachineinstances",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"config.openshift.io",
+				},
+				Resources: []string{
+					"infrastructures",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"",
+				},
+				Resources: []string{
+					"secrets",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
 			{
 				APIGroups: []string{
 					"",
@@ -81,6 +268,85 @@ var (
 					rbacv1.VerbAll,
 				},
 			},
+			{
+				APIGroups: []string{
+					"apiextensions.k8s.io",
+				},
+				Resources: []string{
+					"customresourcedefinitions",
+					"customresourcedefinitions/status",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"update",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"admissionregistration.k8s.io",
+				},
+				Resources: []string{
+					"mutatingwebhookconfigurations",
+					"validatingwebhookconfigurations",
+				},
+				Verbs: []string{
+					"create",
+					"get",
+					"update",
+				},
+			},
+			{
+				APIGroups: []string{
+					"certificates.k8s.io",
+				},
+				Resources: []string{
+					"certificatesigningrequests",
+				},
+				Verbs: []string{
+					"get",
+					"list",
+					"watch",
+				},
+			},
+			{
+				APIGroups: []string{
+					"certificates.k8s.io",
+				},
+				Resources: []string{
+					"certificatesigningrequests/approval",
+				},
+				Verbs: []string{
+					"update",
+				},
+			},
+			{
+				APIGroups: []string{
+					"certificates.k8s.io",
+				},
+				Resources: []string{
+					"signers",
+				},
+				ResourceNames: []string{
+					"kubernetes.io/kube-apiserver-client-kubelet",
+					"kubernetes.io/kubelet-serving",
+				},
+				Verbs: []string{
+					"approve",
+				},
+			},
+			{
+				APIGroups: []string{
+					"authorization.k8s.io",
+				},
+				Resources: []string{
+					"subjectaccessreviews",
+				},
+				Verbs: []string{
+					"create",
+				},
+			},
 			{
 				APIGroups: []string{
 					"",
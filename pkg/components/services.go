@@ -0,0 +1,58 @@
+package components
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+)
+
+const (
+	// annotationServingCertSecretName contains the annotation that makes the OpenShift service CA operator
+	// to generate the serving certificate of the service under the secret with the specified name
+	annotationServingCertSecretName = "service.beta.openshift.io/serving-cert-secret-name"
+)
+
+// NewWebhookService returns new service object that exposes the webhook server of the component
+func NewWebhookService(name string, namespace string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      webhookServiceName(name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				mrv1.SchemeGroupVersion.Group: name,
+			},
+			Annotations: map[string]string{
+				annotationServingCertSecretName: webhookCertSecretName(name),
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				mrv1.SchemeGroupVersion.Group: name,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "https",
+					Port:       443,
+					TargetPort: intstr.FromInt(webhookPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// webhookServiceName returns the name of the service that exposes the webhook server of the component
+func webhookServiceName(name string) string {
+	return name + "-webhook"
+}
+
+// webhookCertSecretName returns the name of the secret with the webhook server certificate of the component
+func webhookCertSecretName(name string) string {
+	return name + "-webhook-cert"
+}
@@ -3,21 +3,53 @@ package consts
 const (
 	// AnnotationBareMetalHost contains the annotation key for bare metal host
 	AnnotationBareMetalHost = "metal3.io/BareMetalHost"
+	// AnnotationForcedPowerOff contains the annotation key, that indicates that the host was forcibly powered off,
+	// the value contains the start time of the remediation attempt that forced the power off
+	AnnotationForcedPowerOff = "machineremediation.kubevirt.io/forcedPowerOff"
+	// AnnotationKubeVirtVirtualMachine contains the annotation key for KubeVirt virtual machine
+	AnnotationKubeVirtVirtualMachine = "kubevirt.io/VirtualMachine"
 	// AnnotationMachine contains the annotation key for machine
 	AnnotationMachine = "machine.openshift.io/machine"
+	// AnnotationClusterAPIMachine contains the annotation key for the name of the upstream Cluster API machine of the node
+	AnnotationClusterAPIMachine = "cluster.x-k8s.io/machine"
+	// AnnotationClusterAPINamespace contains the annotation key for the namespace of the upstream Cluster API machine of the node
+	AnnotationClusterAPINamespace = "cluster.x-k8s.io/cluster-namespace"
 	// AnnotationNodeMachineReboot contains machine reboot annotation key, once nodereboot controller will detect it,
 	// it will create the MachineRemediation object
 	AnnotationNodeMachineReboot = "healthchecking.openshift.io/machine-remediation-reboot"
+	// AnnotationQuorumRiskApproved contains the annotation key, that allows the remediation of the control plane
+	// machine, even when the remediation puts the etcd quorum at risk
+	AnnotationQuorumRiskApproved = "machineremediation.kubevirt.io/quorum-risk-approved"
+	// AnnotationRequestedBy contains the annotation key, that stores the user that created the machine remediation
+	AnnotationRequestedBy = "machineremediation.kubevirt.io/requested-by"
 	// AnnotationRebootInProgress contains the annotation key, that indicates that reboot in the progress
 	AnnotationRebootInProgress = "machineremediation.kubevirt.io/rebootInProgress"
+	// AnnotationSavedVirtualMachine contains the annotation key, that stores the virtual machine that should be recreated
+	AnnotationSavedVirtualMachine = "machineremediation.kubevirt.io/savedVirtualMachine"
 	//MachineRoleLabel contains machine role label
 	MachineRoleLabel = "machine.openshift.io/cluster-api-machine-role"
+	// MachineRoleMaster contains the value of the machine role label for control plane machines
+	MachineRoleMaster = "master"
+	// EtcdPodLabelKey contains the label key of etcd member pods
+	EtcdPodLabelKey = "k8s-app"
+	// EtcdPodLabelValue contains the label value of etcd member pods
+	EtcdPodLabelValue = "etcd"
+	// FinalizerExternalRemediation contains the finalizer that prevents the deletion of the machine remediation,
+	// until the external remediator cancels it
+	FinalizerExternalRemediation = "machineremediation.kubevirt.io/external-remediation"
+	// InfrastructureName contains the name of the cluster infrastructure object
+	InfrastructureName = "cluster"
 	// MasterMachineHealthCheck contains the MachineHealthCheck name for master nodes
 	MasterMachineHealthCheck = "masters"
 	// MasterMachineDisruptionBudget contains the MachineDisruptionBudget name for master nodes
 	MasterMachineDisruptionBudget = "masters"
+	// NamespaceOpenshiftEtcd contains namespace name of etcd member pods under the OpenShift cluster
+	NamespaceOpenshiftEtcd = "openshift-etcd"
 	// NamespaceOpenshiftMachineAPI contains namespace name for the machine-api componenets under the OpenShift cluster
 	NamespaceOpenshiftMachineAPI = "openshift-machine-api"
+	// NodeBootstrapperUser contains the user that requests kubelet client certificates of new nodes
+	// under the OpenShift cluster
+	NodeBootstrapperUser = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
 	//NodeMasterRoleLabel contains node master role label
 	NodeMasterRoleLabel = "node-role.kubernetes.io/master"
 )
@@ -0,0 +1,507 @@
+package certificatesigningrequest
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	certificatesclient "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// nodeUserPrefix contains the prefix of users and common names of kubelet certificates
+	nodeUserPrefix = "system:node:"
+	// nodeGroup contains the group of kubelet users and the organization of kubelet certificates
+	nodeGroup = "system:nodes"
+
+	// reasonApproved contains the reason of the approval condition of certificate signing requests
+	reasonApproved = "MachineRemediationApproved"
+	// reasonDenied contains the reason of the denial condition of certificate signing requests
+	reasonDenied = "MachineRemediationDenied"
+)
+
+var (
+	clientUsages  = sets.NewString(string(certificatesv1beta1.UsageDigitalSignature), string(certificatesv1beta1.UsageKeyEncipherment), string(certificatesv1beta1.UsageClientAuth))
+	servingUsages = sets.NewString(string(certificatesv1beta1.UsageDigitalSignature), string(certificatesv1beta1.UsageKeyEncipherment), string(certificatesv1beta1.UsageServerAuth))
+)
+
+var _ reconcile.Reconciler = &ReconcileCertificateSigningRequest{}
+
+// approver updates the approval of certificate signing requests
+type approver interface {
+	UpdateApproval(csr *certificatesv1beta1.CertificateSigningRequest) (*certificatesv1beta1.CertificateSigningRequest, error)
+}
+
+// ReconcileCertificateSigningRequest reconciles a CertificateSigningRequest object
+type ReconcileCertificateSigningRequest struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client    client.Client
+	recorder  record.EventRecorder
+	approver  approver
+	namespace string
+}
+
+// remediatedMachine contains the machine under active remediation
+type remediatedMachine struct {
+	machine            *mapiv1.Machine
+	machineRemediation *mrv1.MachineRemediation
+}
+
+// isNewMachine returns true when the machine is the new machine, that consumes the bare metal host of the recreated one
+func (rm *remediatedMachine) isNewMachine() bool {
+	return rm.machine.Name != rm.machineRemediation.Spec.MachineName
+}
+
+// Add creates a new CertificateSigningRequest Controller and adds it to the Manager.
+// The Manager will set fields on the Controller and start it when the Manager is started.
+func Add(mgr manager.Manager, opts manager.Options) error {
+	r, err := newReconciler(mgr, opts)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileCertificateSigningRequest, error) {
+	certificatesClient, err := certificatesclient.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileCertificateSigningRequest{
+		client:    mgr.GetClient(),
+		recorder:  mgr.GetEventRecorderFor("certificatesigningrequest-controller"),
+		approver:  certificatesClient.CertificateSigningRequests(),
+		namespace: opts.Namespace,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileCertificateSigningRequest) error {
+	// Create a new controller
+	c, err := controller.New("certificatesigningrequest-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &certificatesv1beta1.CertificateSigningRequest{}}, &handler.EnqueueRequestForObject{})
+}
+
+// Reconcile approves kubelet certificate signing requests of machines under active remediation,
+// so the node of the rebooted or recreated machine can join the cluster. Requests that claim the node
+// of the remediated machine, but do not match the machine, are denied, other requests are left to other approvers.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileCertificateSigningRequest) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	glog.V(4).Infof("Reconciling certificate signing request %s", request.Name)
+
+	// Get certificate signing request from request
+	csr := &certificatesv1beta1.CertificateSigningRequest{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, csr); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if isFinished(csr) {
+		return reconcile.Result{}, nil
+	}
+
+	certificateRequest, err := parseCertificateRequest(csr)
+	if err != nil {
+		glog.V(4).Infof("Skipping certificate signing request %q: %v", csr.Name, err)
+		return reconcile.Result{}, nil
+	}
+
+	// only kubelet certificates can be approved
+	if !strings.HasPrefix(certificateRequest.Subject.CommonName, nodeUserPrefix) {
+		return reconcile.Result{}, nil
+	}
+	nodeName := strings.TrimPrefix(certificateRequest.Subject.CommonName, nodeUserPrefix)
+
+	remediated, err := r.getRemediatedMachine(nodeName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the node is not under remediation, the certificate signing request is out of the controller scope
+	if remediated == nil {
+		glog.V(4).Infof("Node %q of certificate signing request %q is not under remediation", nodeName, csr.Name)
+		return reconcile.Result{}, nil
+	}
+
+	// the request created before the attempt started can belong to the node before the remediation
+	mr := remediated.machineRemediation
+	if startTime := strategies.GetAttemptStartTime(mr); startTime != nil && csr.CreationTimestamp.Before(startTime) {
+		glog.V(4).Infof("Certificate signing request %q was created before the remediation %q started", csr.Name, mr.Name)
+		return reconcile.Result{}, nil
+	}
+
+	certificateType, err := getCertificateType(csr)
+	if err != nil {
+		return reconcile.Result{}, r.deny(csr, remediated, err.Error())
+	}
+
+	// the machine gets addresses of the new host a bit later than the kubelet requests the serving certificate
+	if certificateType == mrv1.CertificateTypeServing && len(remediated.machine.Status.Addresses) == 0 {
+		glog.Warningf("The machine %q does not have addresses, waiting for them to verify the certificate signing request %q", remediated.machine.Name, csr.Name)
+		return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	if err := validateRequest(csr, certificateRequest, certificateType, nodeName, remediated); err != nil {
+		return reconcile.Result{}, r.deny(csr, remediated, err.Error())
+	}
+
+	// the node bootstrapper requests the client certificate only for the new machine of the recreate,
+	// the rebooted node keeps its client certificate
+	if certificateType == mrv1.CertificateTypeClient && csr.Spec.Username == consts.NodeBootstrapperUser {
+		if strategies.GetCurrentStrategy(mr).Type != mrv1.RemediationTypeRecreate || !remediated.isNewMachine() {
+			glog.V(4).Infof("Certificate signing request %q of the node bootstrapper does not belong to the new machine of the recreate", csr.Name)
+			return reconcile.Result{}, nil
+		}
+
+		unchanged, err := r.isNodeUnchanged(nodeName, mr)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if unchanged {
+			return reconcile.Result{}, r.deny(csr, remediated, fmt.Sprintf("the node %q already exists and did not change since the remediation %q started", nodeName, mr.Name))
+		}
+	}
+	return reconcile.Result{}, r.approve(csr, remediated, certificateType, nodeName)
+}
+
+// isNodeUnchanged returns true when the node with the name exists and did not change since the remediation attempt
+// started, it still has the boot ID recorded at the start, or it was created before the start without the recorded boot ID
+func (r *ReconcileCertificateSigningRequest) isNodeUnchanged(nodeName string, mr *mrv1.MachineRemediation) (bool, error) {
+	node := &corev1.Node{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if mr.Status.NodeBootID != "" {
+		return node.Status.NodeInfo.BootID == mr.Status.NodeBootID, nil
+	}
+	startTime := strategies.GetAttemptStartTime(mr)
+	return startTime != nil && node.CreationTimestamp.Before(startTime), nil
+}
+
+// getRemediatedMachine returns the machine under active remediation with the node, or nil when the node does not
+// belong to any remediated machine, the recreated bare metal machine is replaced by the new machine that consumes the host
+func (r *ReconcileCertificateSigningRequest) getRemediatedMachine(nodeName string) (*remediatedMachine, error) {
+	mrs := &mrv1.MachineRemediationList{}
+	if err := r.client.List(context.TODO(), mrs, client.InNamespace(r.namespace)); err != nil {
+		return nil, err
+	}
+
+	for i := range mrs.Items {
+		mr := &mrs.Items[i]
+		if !policies.IsActive(mr) {
+			continue
+		}
+
+		machines, err := r.getMachines(mr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, machine := range machines {
+			if hasNodeName(machine, nodeName) {
+				return &remediatedMachine{machine: machine, machineRemediation: mr}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// getMachines returns the remediated machine and the new machine that consumes the bare metal host of the remediated one
+func (r *ReconcileCertificateSigningRequest) getMachines(mr *mrv1.MachineRemediation) ([]*mapiv1.Machine, error) {
+	var machines []*mapiv1.Machine
+
+	machine := &mapiv1.Machine{}
+	key := types.NamespacedName{Namespace: mr.Namespace, Name: mr.Spec.MachineName}
+	if err := r.client.Get(context.TODO(), key, machine); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	} else {
+		machines = append(machines, machine)
+	}
+
+	bmhKey, ok := mr.Annotations[consts.AnnotationBareMetalHost]
+	if !ok {
+		return machines, nil
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(bmhKey)
+	if err != nil {
+		return nil, err
+	}
+
+	bmh := &bmov1.BareMetalHost{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, bmh); err != nil {
+		if errors.IsNotFound(err) {
+			return machines, nil
+		}
+		return nil, err
+	}
+
+	consumerRef := bmh.Spec.ConsumerRef
+	if consumerRef == nil || consumerRef.Name == mr.Spec.MachineName {
+		return machines, nil
+	}
+
+	newMachine, err := machineapi.GetMachineByConsumerRef(r.client, consumerRef)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return machines, nil
+		}
+		return nil, err
+	}
+	if newMachine == nil || newMachine.Name == mr.Spec.MachineName {
+		return machines, nil
+	}
+	return append(machines, newMachine), nil
+}
+
+// approve records the approval under the machine remediation and approves the certificate signing request
+func (r *ReconcileCertificateSigningRequest) approve(
+	csr *certificatesv1beta1.CertificateSigningRequest,
+	remediated *remediatedMachine,
+	certificateType mrv1.CertificateType,
+	nodeName string,
+) error {
+	mr := remediated.machineRemediation
+	now := time.Now()
+
+	// record the approval before the approval itself, so the failed update of the machine remediation
+	// does not leave the approved request without the record
+	if !hasApprovedCertificate(mr, csr.Name) {
+		// Copy the MachineRemediation object to prevent modification of the original one
+		mrCopy := mr.DeepCopy()
+		mrCopy.Status.ApprovedCertificates = append(mrCopy.Status.ApprovedCertificates, mrv1.ApprovedCertificate{
+			Name:         csr.Name,
+			NodeName:     nodeName,
+			MachineName:  remediated.machine.Name,
+			Type:         certificateType,
+			ApprovalTime: metav1.Time{Time: now},
+		})
+		if err := r.client.Status().Update(context.TODO(), mrCopy); err != nil {
+			return err
+		}
+	}
+
+	message := fmt.Sprintf("Kubelet %s certificate of node %q approved for machine %q under remediation", certificateType, nodeName, remediated.machine.Name)
+	if err := r.updateApproval(csr, certificatesv1beta1.CertificateApproved, reasonApproved, message, now); err != nil {
+		return err
+	}
+
+	glog.Infof("Certificate signing request %q approved: %s", csr.Name, message)
+	r.recorder.Eventf(
+		mr,
+		corev1.EventTypeNormal,
+		"MachineRemediationCertificateApproved",
+		"Certificate signing request %q approved: %s",
+		csr.Name,
+		message,
+	)
+	return nil
+}
+
+// deny denies the certificate signing request, that claims the node of the remediated machine, but does not match it
+func (r *ReconcileCertificateSigningRequest) deny(csr *certificatesv1beta1.CertificateSigningRequest, remediated *remediatedMachine, message string) error {
+	if err := r.updateApproval(csr, certificatesv1beta1.CertificateDenied, reasonDenied, message, time.Now()); err != nil {
+		return err
+	}
+
+	glog.Warningf("Certificate signing request %q denied: %s", csr.Name, message)
+	r.recorder.Eventf(
+		remediated.machineRemediation,
+		corev1.EventTypeWarning,
+		"MachineRemediationCertificateDenied",
+		"Certificate signing request %q denied: %s",
+		csr.Name,
+		message,
+	)
+	return nil
+}
+
+func (r *ReconcileCertificateSigningRequest) updateApproval(
+	csr *certificatesv1beta1.CertificateSigningRequest,
+	conditionType certificatesv1beta1.RequestConditionType,
+	reason string,
+	message string,
+	now time.Time,
+) error {
+	// Copy the CertificateSigningRequest object to prevent modification of the original one
+	csrCopy := csr.DeepCopy()
+	csrCopy.Status.Conditions = append(csrCopy.Status.Conditions, certificatesv1beta1.CertificateSigningRequestCondition{
+		Type:           conditionType,
+		Reason:         reason,
+		Message:        message,
+		LastUpdateTime: metav1.Time{Time: now},
+	})
+	_, err := r.approver.UpdateApproval(csrCopy)
+	return err
+}
+
+// validateRequest returns an error when the certificate signing request does not match the remediated machine
+func validateRequest(
+	csr *certificatesv1beta1.CertificateSigningRequest,
+	certificateRequest *x509.CertificateRequest,
+	certificateType mrv1.CertificateType,
+	nodeName string,
+	remediated *remediatedMachine,
+) error {
+	if len(certificateRequest.Subject.Organization) != 1 || certificateRequest.Subject.Organization[0] != nodeGroup {
+		return fmt.Errorf("the request organization %v is not %q", certificateRequest.Subject.Organization, nodeGroup)
+	}
+
+	if len(certificateRequest.EmailAddresses) != 0 || len(certificateRequest.URIs) != 0 {
+		return fmt.Errorf("the request has email or URI subject alternative names")
+	}
+
+	nodeUser := nodeUserPrefix + nodeName
+	switch certificateType {
+	case mrv1.CertificateTypeClient:
+		if csr.Spec.Username != consts.NodeBootstrapperUser && csr.Spec.Username != nodeUser {
+			return fmt.Errorf("the client certificate of node %q was requested by the user %q", nodeName, csr.Spec.Username)
+		}
+
+		if len(certificateRequest.DNSNames) != 0 || len(certificateRequest.IPAddresses) != 0 {
+			return fmt.Errorf("the client certificate request has subject alternative names")
+		}
+
+	case mrv1.CertificateTypeServing:
+		if csr.Spec.Username != nodeUser {
+			return fmt.Errorf("the serving certificate of node %q was requested by the user %q", nodeName, csr.Spec.Username)
+		}
+
+		if len(certificateRequest.DNSNames) == 0 && len(certificateRequest.IPAddresses) == 0 {
+			return fmt.Errorf("the serving certificate request does not have subject alternative names")
+		}
+
+		hostNames, ips := getMachineAddresses(remediated.machine)
+		hostNames.Insert(nodeName)
+		for _, dnsName := range certificateRequest.DNSNames {
+			if !hostNames.Has(dnsName) {
+				return fmt.Errorf("the DNS name %q does not belong to machine %q", dnsName, remediated.machine.Name)
+			}
+		}
+		for _, ip := range certificateRequest.IPAddresses {
+			if !ips.Has(ip.String()) {
+				return fmt.Errorf("the IP address %q does not belong to machine %q", ip.String(), remediated.machine.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// getCertificateType returns the type of the kubelet certificate by usages of the certificate signing request
+func getCertificateType(csr *certificatesv1beta1.CertificateSigningRequest) (mrv1.CertificateType, error) {
+	usages := sets.NewString()
+	for _, usage := range csr.Spec.Usages {
+		usages.Insert(string(usage))
+	}
+
+	if clientUsages.IsSuperset(usages) && usages.Has(string(certificatesv1beta1.UsageClientAuth)) {
+		return mrv1.CertificateTypeClient, nil
+	}
+	if servingUsages.IsSuperset(usages) && usages.Has(string(certificatesv1beta1.UsageServerAuth)) {
+		return mrv1.CertificateTypeServing, nil
+	}
+	return "", fmt.Errorf("the request has usages %v, that do not match kubelet certificates", usages.List())
+}
+
+// getMachineAddresses returns host names and IP addresses of the machine
+func getMachineAddresses(machine *mapiv1.Machine) (sets.String, sets.String) {
+	hostNames := sets.NewString()
+	ips := sets.NewString()
+	for _, address := range machine.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeHostName, corev1.NodeInternalDNS, corev1.NodeExternalDNS:
+			hostNames.Insert(address.Address)
+		case corev1.NodeInternalIP, corev1.NodeExternalIP:
+			if ip := net.ParseIP(address.Address); ip != nil {
+				ips.Insert(ip.String())
+			}
+		}
+	}
+	return hostNames, ips
+}
+
+// hasNodeName returns true when the machine references the node, or has the host name of the node
+// under its addresses, the new machine does not reference its node until the node registers
+func hasNodeName(machine *mapiv1.Machine, nodeName string) bool {
+	if machine.Status.NodeRef != nil && machine.Status.NodeRef.Name == nodeName {
+		return true
+	}
+
+	hostNames, _ := getMachineAddresses(machine)
+	return hostNames.Has(nodeName)
+}
+
+func hasApprovedCertificate(mr *mrv1.MachineRemediation, name string) bool {
+	for _, approved := range mr.Status.ApprovedCertificates {
+		if approved.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isFinished(csr *certificatesv1beta1.CertificateSigningRequest) bool {
+	for _, c := range csr.Status.Conditions {
+		if c.Type == certificatesv1beta1.CertificateApproved || c.Type == certificatesv1beta1.CertificateDenied {
+			return true
+		}
+	}
+	return false
+}
+
+func parseCertificateRequest(csr *certificatesv1beta1.CertificateSigningRequest) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("the request does not have PEM encoded certificate request")
+	}
+	return x509.ParseCertificateRequest(block.Bytes)
+}
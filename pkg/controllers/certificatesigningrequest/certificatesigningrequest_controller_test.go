@@ -0,0 +1,316 @@
+package certificatesigningrequest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	// Add types to scheme
+	bmov1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+// fakeApprover updates the approval of certificate signing requests under the fake client
+type fakeApprover struct {
+	client client.Client
+}
+
+func (f *fakeApprover) UpdateApproval(csr *certificatesv1beta1.CertificateSigningRequest) (*certificatesv1beta1.CertificateSigningRequest, error) {
+	if err := f.client.Update(context.TODO(), csr); err != nil {
+		return nil, err
+	}
+	return csr, nil
+}
+
+// newFakeReconciler returns a new reconcile.Reconciler with a fake client
+func newFakeReconciler(recorder record.EventRecorder, initObjects ...runtime.Object) *ReconcileCertificateSigningRequest {
+	fakeClient := fake.NewFakeClient(initObjects...)
+	return &ReconcileCertificateSigningRequest{
+		client:    fakeClient,
+		recorder:  recorder,
+		approver:  &fakeApprover{client: fakeClient},
+		namespace: consts.NamespaceOpenshiftMachineAPI,
+	}
+}
+
+func newCSR(name string, username string, nodeName string, dnsNames []string, ips []string, usages ...certificatesv1beta1.KeyUsage) *certificatesv1beta1.CertificateSigningRequest {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   nodeUserPrefix + nodeName,
+			Organization: []string{nodeGroup},
+		},
+		DNSNames: dnsNames,
+	}
+	for _, ip := range ips {
+		template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		panic(err)
+	}
+
+	return &certificatesv1beta1.CertificateSigningRequest{
+		TypeMeta: metav1.TypeMeta{Kind: "CertificateSigningRequest"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: certificatesv1beta1.CertificateSigningRequestSpec{
+			Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+			Username: username,
+			Usages:   usages,
+		},
+	}
+}
+
+func newClientCSR(name string, nodeName string) *certificatesv1beta1.CertificateSigningRequest {
+	return newCSR(
+		name,
+		consts.NodeBootstrapperUser,
+		nodeName,
+		nil,
+		nil,
+		certificatesv1beta1.UsageDigitalSignature,
+		certificatesv1beta1.UsageKeyEncipherment,
+		certificatesv1beta1.UsageClientAuth,
+	)
+}
+
+func newServingCSR(name string, nodeName string, ips ...string) *certificatesv1beta1.CertificateSigningRequest {
+	return newCSR(
+		name,
+		nodeUserPrefix+nodeName,
+		nodeName,
+		[]string{nodeName},
+		ips,
+		certificatesv1beta1.UsageDigitalSignature,
+		certificatesv1beta1.UsageKeyEncipherment,
+		certificatesv1beta1.UsageServerAuth,
+	)
+}
+
+func TestReconcile(t *testing.T) {
+	// the rebooted machine keeps its node
+	machineRebooted := mrtesting.NewMachine("machineRebooted", "nodeRebooted", "")
+	machineRebooted.Status.Addresses = []corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "192.168.111.20"},
+		{Type: corev1.NodeHostName, Address: "nodeRebooted"},
+	}
+	mrRebooted := mrtesting.NewMachineRemediation("mrRebooted", machineRebooted.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	mrRebooted.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+
+	// the recreated machine is replaced by the new machine, that consumes the host and does not have the node yet
+	machineNew := mrtesting.NewMachine("machineNew", "", "bareMetalHost")
+	machineNew.Status.Addresses = []corev1.NodeAddress{
+		{Type: corev1.NodeInternalIP, Address: "192.168.111.21"},
+		{Type: corev1.NodeHostName, Address: "nodeRecreated"},
+	}
+	bareMetalHost := mrtesting.NewBareMetalHost("bareMetalHost", true, true)
+	bareMetalHost.Spec.ConsumerRef = &corev1.ObjectReference{
+		Kind:      "Machine",
+		Name:      machineNew.Name,
+		Namespace: machineNew.Namespace,
+	}
+	mrRecreated := mrtesting.NewMachineRemediation("mrRecreated", "machineRecreated", mrv1.RemediationTypeRecreate, mrv1.RemediationStateProvisioning)
+	mrRecreated.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	mrRecreated.Annotations = map[string]string{
+		consts.AnnotationBareMetalHost: fmt.Sprintf("%s/%s", bareMetalHost.Namespace, bareMetalHost.Name),
+	}
+	mrRecreated.Status.NodeBootID = "bootBeforeRecreate"
+
+	// the node of the recreated machine, that was not deleted yet
+	nodeUnchanged := mrtesting.NewNode("nodeRecreated", false, "machineRecreated")
+	nodeUnchanged.Status.NodeInfo.BootID = "bootBeforeRecreate"
+
+	// the machine without addresses
+	machineWithoutAddresses := mrtesting.NewMachine("machineWithoutAddresses", "nodeWithoutAddresses", "")
+	mrWithoutAddresses := mrtesting.NewMachineRemediation("mrWithoutAddresses", machineWithoutAddresses.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	mrWithoutAddresses.Status.StartTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+
+	// the machine with the finished remediation
+	machineSucceeded := mrtesting.NewMachine("machineSucceeded", "nodeSucceeded", "")
+	mrSucceeded := mrtesting.NewMachineRemediation("mrSucceeded", machineSucceeded.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+
+	csrBeforeRemediation := newClientCSR("csrBeforeRemediation", "nodeRecreated")
+	csrBeforeRemediation.CreationTimestamp = metav1.Time{Time: time.Now().Add(-time.Hour)}
+
+	csrApproved := newClientCSR("csrApproved", "nodeRecreated")
+	csrApproved.Status.Conditions = []certificatesv1beta1.CertificateSigningRequestCondition{
+		{Type: certificatesv1beta1.CertificateApproved},
+	}
+
+	csrWrongUser := newClientCSR("csrWrongUser", "nodeRecreated")
+	csrWrongUser.Spec.Username = "system:serviceaccount:default:default"
+
+	testsCases := []struct {
+		name                 string
+		csr                  *certificatesv1beta1.CertificateSigningRequest
+		expectedCondition    certificatesv1beta1.RequestConditionType
+		expectedRecord       string
+		extraObjects         []runtime.Object
+		expectedRequeueAfter time.Duration
+		expectedEvents       []string
+	}{
+		{
+			name:              "with client certificate of recreated node",
+			csr:               newClientCSR("csrClient", "nodeRecreated"),
+			expectedCondition: certificatesv1beta1.CertificateApproved,
+			expectedRecord:    mrRecreated.Name,
+			expectedEvents:    []string{"MachineRemediationCertificateApproved"},
+		},
+		{
+			name:              "with serving certificate of recreated node",
+			csr:               newServingCSR("csrServing", "nodeRecreated", "192.168.111.21"),
+			expectedCondition: certificatesv1beta1.CertificateApproved,
+			expectedRecord:    mrRecreated.Name,
+			expectedEvents:    []string{"MachineRemediationCertificateApproved"},
+		},
+		{
+			name:              "with serving certificate of rebooted node",
+			csr:               newServingCSR("csrServing", "nodeRebooted", "192.168.111.20"),
+			expectedCondition: certificatesv1beta1.CertificateApproved,
+			expectedRecord:    mrRebooted.Name,
+			expectedEvents:    []string{"MachineRemediationCertificateApproved"},
+		},
+		{
+			name:              "with serving certificate with address of other machine",
+			csr:               newServingCSR("csrServing", "nodeRecreated", "192.168.111.21", "192.168.111.20"),
+			expectedCondition: certificatesv1beta1.CertificateDenied,
+			expectedEvents:    []string{"MachineRemediationCertificateDenied"},
+		},
+		{
+			name:              "with client certificate requested by other user",
+			csr:               csrWrongUser,
+			expectedCondition: certificatesv1beta1.CertificateDenied,
+			expectedEvents:    []string{"MachineRemediationCertificateDenied"},
+		},
+		{
+			name:           "with client certificate requested before remediation",
+			csr:            csrBeforeRemediation,
+			expectedEvents: []string{},
+		},
+		{
+			name:           "with bootstrap client certificate of rebooted node",
+			csr:            newClientCSR("csrClient", "nodeRebooted"),
+			expectedEvents: []string{},
+		},
+		{
+			name:              "with bootstrap client certificate of unchanged node",
+			csr:               newClientCSR("csrClient", "nodeRecreated"),
+			extraObjects:      []runtime.Object{nodeUnchanged},
+			expectedCondition: certificatesv1beta1.CertificateDenied,
+			expectedEvents:    []string{"MachineRemediationCertificateDenied"},
+		},
+		{
+			name:                 "with serving certificate of machine without addresses",
+			csr:                  newServingCSR("csrServing", "nodeWithoutAddresses", "192.168.111.22"),
+			expectedRequeueAfter: 10 * time.Second,
+			expectedEvents:       []string{},
+		},
+		{
+			name:           "with client certificate of node without remediation",
+			csr:            newClientCSR("csrClient", "nodeWorker"),
+			expectedEvents: []string{},
+		},
+		{
+			name:           "with client certificate of node with succeeded remediation",
+			csr:            newClientCSR("csrClient", "nodeSucceeded"),
+			expectedEvents: []string{},
+		},
+		{
+			name:              "with approved certificate",
+			csr:               csrApproved,
+			expectedCondition: certificatesv1beta1.CertificateApproved,
+			expectedEvents:    []string{},
+		},
+	}
+
+	for _, tc := range testsCases {
+		recorder := record.NewFakeRecorder(10)
+		objects := []runtime.Object{
+			machineRebooted,
+			mrRebooted,
+			machineNew,
+			bareMetalHost,
+			mrRecreated,
+			machineWithoutAddresses,
+			mrWithoutAddresses,
+			machineSucceeded,
+			mrSucceeded,
+			tc.csr,
+		}
+		r := newFakeReconciler(recorder, append(objects, tc.extraObjects...)...)
+
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Name: tc.csr.Name}}
+		result, err := r.Reconcile(request)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if result.RequeueAfter != tc.expectedRequeueAfter {
+			t.Errorf("Test case: %s. Expected RequeueAfter: %v, got: %v", tc.name, tc.expectedRequeueAfter, result.RequeueAfter)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		csr := &certificatesv1beta1.CertificateSigningRequest{}
+		if err := r.client.Get(context.TODO(), request.NamespacedName, csr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		var condition certificatesv1beta1.RequestConditionType
+		if len(csr.Status.Conditions) != 0 {
+			condition = csr.Status.Conditions[len(csr.Status.Conditions)-1].Type
+		}
+		if condition != tc.expectedCondition {
+			t.Errorf("Test case: %s. Expected condition: %q, got: %q", tc.name, tc.expectedCondition, condition)
+		}
+
+		mrs := &mrv1.MachineRemediationList{}
+		if err := r.client.List(context.TODO(), mrs); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		for _, mr := range mrs.Items {
+			recorded := hasApprovedCertificate(&mr, tc.csr.Name)
+			if recorded != (mr.Name == tc.expectedRecord) {
+				t.Errorf("Test case: %s. Expected approval recorded under %q, got: %q with %v", tc.name, tc.expectedRecord, mr.Name, mr.Status.ApprovedCertificates)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package externalremediation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// ConditionOwnerRemediated contains the Cluster API machine condition type, that the false status
+	// marks the machine for the remediation by its owner, the owner MachineSet replaces the machine
+	ConditionOwnerRemediated = "OwnerRemediated"
+	// ReasonWaitingForRemediation contains the reason of the owner remediated condition, when the machine
+	// waits for the remediation by its owner
+	ReasonWaitingForRemediation = "WaitingForRemediation"
+	// conditionSeverityWarning contains the severity of the false owner remediated condition
+	conditionSeverityWarning = "Warning"
+)
+
+var _ reconcile.Reconciler = &ReconcileExternalRemediation{}
+
+// ReconcileExternalRemediation reconciles machine remediations created by the upstream Cluster API
+// MachineHealthCheck from the MachineRemediationTemplate
+type ReconcileExternalRemediation struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client   client.Client
+	recorder record.EventRecorder
+}
+
+// Add creates a new ExternalRemediation Controller and adds it to the Manager.
+// The Manager will set fields on the Controller and start it when the Manager is started.
+func Add(mgr manager.Manager, opts manager.Options) error {
+	r, err := newReconciler(mgr, opts)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r)
+}
+
+func newReconciler(mgr manager.Manager, opts manager.Options) (reconcile.Reconciler, error) {
+	return &ReconcileExternalRemediation{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor("externalremediation-controller"),
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("externalremediation-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &mrv1.MachineRemediation{}}, &handler.EnqueueRequestForObject{}, machineOwnerPredicate)
+}
+
+// machineOwnerPredicate passes only events of machine remediations owned by Cluster API machines,
+// other machine remediations are not created by the MachineHealthCheck
+var machineOwnerPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return machineapi.GetMachineOwner(e.Meta) != nil
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return machineapi.GetMachineOwner(e.MetaNew) != nil
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// Reconcile binds the machine remediation created by the MachineHealthCheck to its Cluster API machine owner,
+// when the webhook did not do it, and returns the machine of the failed remediation to its owner,
+// under the external remediation contract the MachineHealthCheck deletes the machine remediation
+// once the machine is healthy again, so the succeeded remediation does not require any action.
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileExternalRemediation) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	glog.V(4).Infof("Reconciling external remediation %s/%s\n", request.Namespace, request.Name)
+
+	// Get MachineRemediation from request
+	mr := &mrv1.MachineRemediation{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, mr); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	owner := machineapi.GetMachineOwner(mr)
+	if owner == nil || mr.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	if mr.Spec.MachineName == "" {
+		glog.Infof("Bind external remediation %s to machine %q", mr.Name, owner.Name)
+
+		// Copy the machine remediation object to prevent modification of the original one
+		mrCopy := mr.DeepCopy()
+		mrCopy.Spec.MachineName = owner.Name
+		return reconcile.Result{}, r.client.Update(context.TODO(), mrCopy)
+	}
+
+	if mr.Status.State != mrv1.RemediationStateFailed {
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, r.returnToOwner(mr)
+}
+
+// returnToOwner sets the false owner remediated condition of the Cluster API machine of the failed remediation,
+// so the owner MachineSet replaces the machine
+func (r *ReconcileExternalRemediation) returnToOwner(mr *mrv1.MachineRemediation) error {
+	machine := &unstructured.Unstructured{}
+	machine.SetGroupVersionKind(machineapi.ClusterAPIMachineGVK)
+	key := types.NamespacedName{
+		Namespace: mr.Namespace,
+		Name:      mr.Spec.MachineName,
+	}
+	if err := r.client.Get(context.TODO(), key, machine); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	message := fmt.Sprintf("Machine remediation %s failed: %s", mr.Name, mr.Status.Message)
+	updated, err := setOwnerRemediatedCondition(machine, message)
+	if err != nil || !updated {
+		return err
+	}
+
+	if err := r.client.Status().Update(context.TODO(), machine); err != nil {
+		return err
+	}
+
+	glog.Warningf("Remediation of machine %q failed, the machine is returned to its owner", mr.Spec.MachineName)
+	r.recorder.Eventf(
+		mr,
+		corev1.EventTypeWarning,
+		"MachineRemediationReturnedToOwner",
+		"Remediation of machine %q failed, the machine is returned to its owner",
+		mr.Spec.MachineName,
+	)
+	return nil
+}
+
+// setOwnerRemediatedCondition sets the false owner remediated condition of the Cluster API machine,
+// it returns false when the machine already has the false condition
+func setOwnerRemediatedCondition(machine *unstructured.Unstructured, message string) (bool, error) {
+	conditions, _, err := unstructured.NestedSlice(machine.Object, "status", "conditions")
+	if err != nil {
+		return false, err
+	}
+
+	condition := map[string]interface{}{
+		"type":               ConditionOwnerRemediated,
+		"status":             string(corev1.ConditionFalse),
+		"severity":           conditionSeverityWarning,
+		"reason":             ReasonWaitingForRemediation,
+		"message":            message,
+		"lastTransitionTime": metav1.Now().UTC().Format(time.RFC3339),
+	}
+
+	found := false
+	for i := range conditions {
+		existing, ok := conditions[i].(map[string]interface{})
+		if !ok || existing["type"] != ConditionOwnerRemediated {
+			continue
+		}
+		if existing["status"] == string(corev1.ConditionFalse) {
+			return false, nil
+		}
+		conditions[i] = condition
+		found = true
+	}
+	if !found {
+		conditions = append(conditions, condition)
+	}
+	return true, unstructured.SetNestedSlice(machine.Object, conditions, "status", "conditions")
+}
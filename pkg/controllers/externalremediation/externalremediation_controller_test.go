@@ -0,0 +1,180 @@
+package externalremediation
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+}
+
+// newFakeReconciler returns a new reconcile.Reconciler with a fake client
+func newFakeReconciler(recorder record.EventRecorder, initObjects ...runtime.Object) *ReconcileExternalRemediation {
+	return &ReconcileExternalRemediation{
+		client:   fake.NewFakeClient(initObjects...),
+		recorder: recorder,
+	}
+}
+
+func newClusterAPIMachine(name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(machineapi.ClusterAPIMachineGVK)
+	u.SetNamespace(consts.NamespaceOpenshiftMachineAPI)
+	u.SetName(name)
+	return u
+}
+
+// newExternalRemediation returns the machine remediation created by the MachineHealthCheck from the template
+func newExternalRemediation(machineName string, remediationState mrv1.RemediationState) *mrv1.MachineRemediation {
+	mr := mrtesting.NewMachineRemediation(machineName, "", mrv1.RemediationTypeReboot, remediationState)
+	mr.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: machineapi.ClusterAPIMachineGVK.GroupVersion().String(),
+			Kind:       machineapi.ClusterAPIMachineGVK.Kind,
+			Name:       machineName,
+			UID:        "1",
+		},
+	}
+	return mr
+}
+
+func TestReconcile(t *testing.T) {
+	mrUnbound := newExternalRemediation("machineUnbound", "")
+
+	mrFailed := newExternalRemediation("machineFailed", mrv1.RemediationStateFailed)
+	mrFailed.Spec.MachineName = "machineFailed"
+	mrFailed.Status.Message = "the node did not become ready"
+
+	machineReturned := newClusterAPIMachine("machineReturned")
+	unstructured.SetNestedSlice(machineReturned.Object, []interface{}{
+		map[string]interface{}{"type": ConditionOwnerRemediated, "status": "False", "reason": ReasonWaitingForRemediation},
+	}, "status", "conditions")
+	mrReturned := newExternalRemediation("machineReturned", mrv1.RemediationStateFailed)
+	mrReturned.Spec.MachineName = "machineReturned"
+
+	mrSucceeded := newExternalRemediation("machineSucceeded", mrv1.RemediationStateSucceeded)
+	mrSucceeded.Spec.MachineName = "machineSucceeded"
+
+	mrWithoutOwner := mrtesting.NewMachineRemediation("mrWithoutOwner", "", mrv1.RemediationTypeReboot, "")
+
+	testCases := []struct {
+		name                string
+		mr                  *mrv1.MachineRemediation
+		machine             *unstructured.Unstructured
+		expectedMachineName string
+		expectedCondition   bool
+		expectedEvents      []string
+	}{
+		{
+			name:                "with machine remediation without machine name",
+			mr:                  mrUnbound,
+			machine:             newClusterAPIMachine("machineUnbound"),
+			expectedMachineName: "machineUnbound",
+		},
+		{
+			name:                "with failed machine remediation",
+			mr:                  mrFailed,
+			machine:             newClusterAPIMachine("machineFailed"),
+			expectedMachineName: "machineFailed",
+			expectedCondition:   true,
+			expectedEvents:      []string{"MachineRemediationReturnedToOwner"},
+		},
+		{
+			name:                "with failed machine remediation of machine returned to its owner",
+			mr:                  mrReturned,
+			machine:             machineReturned,
+			expectedMachineName: "machineReturned",
+			expectedCondition:   true,
+		},
+		{
+			name:                "with succeeded machine remediation",
+			mr:                  mrSucceeded,
+			machine:             newClusterAPIMachine("machineSucceeded"),
+			expectedMachineName: "machineSucceeded",
+		},
+		{
+			name:                "with machine remediation without Cluster API machine owner",
+			mr:                  mrWithoutOwner,
+			expectedMachineName: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		objects := []runtime.Object{tc.mr}
+		if tc.machine != nil {
+			objects = append(objects, tc.machine)
+		}
+		r := newFakeReconciler(recorder, objects...)
+
+		key := types.NamespacedName{Namespace: tc.mr.Namespace, Name: tc.mr.Name}
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Fatalf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		if mr.Spec.MachineName != tc.expectedMachineName {
+			t.Errorf("Test case: %s. Expected machine name: %q, got: %q", tc.name, tc.expectedMachineName, mr.Spec.MachineName)
+		}
+
+		if tc.machine != nil {
+			machine := newClusterAPIMachine(tc.machine.GetName())
+			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: tc.machine.GetNamespace(), Name: tc.machine.GetName()}, machine); err != nil {
+				t.Fatalf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			}
+			if hasCondition := hasOwnerRemediatedCondition(machine); hasCondition != tc.expectedCondition {
+				t.Errorf("Test case: %s. Expected owner remediated condition: %t, got: %t", tc.name, tc.expectedCondition, hasCondition)
+			}
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+	}
+}
+
+func TestMachineOwnerPredicate(t *testing.T) {
+	mrWithOwner := newExternalRemediation("machine", "")
+	mrWithoutOwner := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, "")
+
+	if !machineOwnerPredicate.Create(event.CreateEvent{Meta: mrWithOwner, Object: mrWithOwner}) {
+		t.Errorf("Expected: the create event of the machine remediation with the machine owner passes")
+	}
+	if machineOwnerPredicate.Create(event.CreateEvent{Meta: mrWithoutOwner, Object: mrWithoutOwner}) {
+		t.Errorf("Expected: the create event of the machine remediation without the machine owner does not pass")
+	}
+	if !machineOwnerPredicate.Update(event.UpdateEvent{MetaOld: mrWithOwner, ObjectOld: mrWithOwner, MetaNew: mrWithOwner, ObjectNew: mrWithOwner}) {
+		t.Errorf("Expected: the update event of the machine remediation with the machine owner passes")
+	}
+}
+
+func hasOwnerRemediatedCondition(machine *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(machine.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition := c.(map[string]interface{})
+		if condition["type"] == ConditionOwnerRemediated && condition["status"] == string(corev1.ConditionFalse) {
+			return true
+		}
+	}
+	return false
+}
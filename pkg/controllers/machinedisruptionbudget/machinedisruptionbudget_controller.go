@@ -0,0 +1,184 @@
+package machinedisruptionbudget
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/budgets"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var _ reconcile.Reconciler = &ReconcileMachineDisruptionBudget{}
+
+// ReconcileMachineDisruptionBudget reconciles a MachineDisruptionBudget object
+type ReconcileMachineDisruptionBudget struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client    client.Client
+	namespace string
+}
+
+// Add creates a new MachineDisruptionBudget Controller and adds it to the Manager, the controller watches
+// machines of the machine API flavor. The Manager will set fields on the Controller and start it when the Manager is started.
+func Add(mgr manager.Manager, flavor machineapi.Flavor, opts manager.Options) error {
+	r, err := newReconciler(mgr, opts)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r, flavor)
+}
+
+func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileMachineDisruptionBudget, error) {
+	return &ReconcileMachineDisruptionBudget{
+		client:    mgr.GetClient(),
+		namespace: opts.Namespace,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileMachineDisruptionBudget, flavor machineapi.Flavor) error {
+	// Create a new controller
+	c, err := controller.New("machinedisruptionbudget-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &mrv1.MachineDisruptionBudget{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// the budget status depends on machines, their nodes and machine remediations under the budget namespace
+	toBudgets := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.namespaceBudgets)}
+	if err := c.Watch(&source.Kind{Type: machineapi.NewMachineObject(flavor)}, toBudgets); err != nil {
+		return err
+	}
+	if err := c.Watch(&source.Kind{Type: &mrv1.MachineRemediation{}}, toBudgets); err != nil {
+		return err
+	}
+	return c.Watch(
+		&source.Kind{Type: &corev1.Node{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.nodeBudgets)},
+		nodeReadinessPredicate,
+	)
+}
+
+// nodeReadinessPredicate passes the creation and the deletion of the node and the change of its readiness,
+// so node status heartbeats do not trigger the reconcile
+var nodeReadinessPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		newNode, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		return conditions.NodeHasCondition(oldNode, corev1.NodeReady, corev1.ConditionTrue) !=
+			conditions.NodeHasCondition(newNode, corev1.NodeReady, corev1.ConditionTrue)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// nodeBudgets returns requests for all machine disruption budgets under the namespace of the node machine
+func (r *ReconcileMachineDisruptionBudget) nodeBudgets(o handler.MapObject) []reconcile.Request {
+	node, ok := o.Object.(*corev1.Node)
+	if !ok {
+		return nil
+	}
+
+	machine, err := machineutils.GetMachineByNode(r.client, node)
+	if err != nil {
+		glog.V(4).Infof("Failed to get the machine of node %q: %v", node.Name, err)
+		return nil
+	}
+	return r.getBudgetRequests(machine.Namespace)
+}
+
+// namespaceBudgets returns requests for all machine disruption budgets under the namespace of the object
+func (r *ReconcileMachineDisruptionBudget) namespaceBudgets(o handler.MapObject) []reconcile.Request {
+	return r.getBudgetRequests(o.Meta.GetNamespace())
+}
+
+func (r *ReconcileMachineDisruptionBudget) getBudgetRequests(namespace string) []reconcile.Request {
+	mdbList := &mrv1.MachineDisruptionBudgetList{}
+	if err := r.client.List(context.TODO(), mdbList, client.InNamespace(namespace)); err != nil {
+		glog.Errorf("Failed to list machine disruption budgets: %v", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, mdb := range mdbList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: mdb.Namespace, Name: mdb.Name},
+		})
+	}
+	return requests
+}
+
+// Reconcile updates the number of selected machines, the number of healthy machines and the number of allowed
+// disruptions under the MachineDisruptionBudget status
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileMachineDisruptionBudget) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	glog.V(4).Infof("Reconciling MachineDisruptionBudget triggered by %s/%s\n", request.Namespace, request.Name)
+
+	// Get MachineDisruptionBudget from request
+	mdb := &mrv1.MachineDisruptionBudget{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, mdb); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if mdb.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	status, _, err := budgets.GetStatus(r.client, mdb)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if mdb.Status == *status {
+		return reconcile.Result{}, nil
+	}
+
+	// Copy the machine disruption budget object to prevent modification of the original one
+	mdbCopy := mdb.DeepCopy()
+	mdbCopy.Status = *status
+	if err := r.client.Status().Update(context.TODO(), mdbCopy); err != nil {
+		glog.Errorf("Failed to update machine disruption budget %q status: %v", mdb.Name, err)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
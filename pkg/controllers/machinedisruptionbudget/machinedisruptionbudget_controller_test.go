@@ -0,0 +1,151 @@
+package machinedisruptionbudget
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+// newFakeReconciler returns a new reconcile.Reconciler with a fake client
+func newFakeReconciler(initObjects ...runtime.Object) *ReconcileMachineDisruptionBudget {
+	return &ReconcileMachineDisruptionBudget{
+		client:    fake.NewFakeClient(initObjects...),
+		namespace: consts.NamespaceOpenshiftMachineAPI,
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	node := mrtesting.NewNode("node", true, "machine")
+	remediatedMachine := mrtesting.NewMachine("remediatedMachine", "remediatedNode", "")
+	remediatedNode := mrtesting.NewNode("remediatedNode", true, "remediatedMachine")
+	unhealthyMachine := mrtesting.NewMachine("unhealthyMachine", "unhealthyNode", "")
+	unhealthyNode := mrtesting.NewNode("unhealthyNode", false, "unhealthyMachine")
+
+	mrActive := mrtesting.NewMachineRemediation("mrActive", remediatedMachine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	minAvailable := int32(1)
+	mdb := mrtesting.NewMachineDisruptionBudget("mdb", &minAvailable, nil)
+	mdb.Generation = 2
+
+	maxUnavailable := int32(3)
+	mdbMaxUnavailable := mrtesting.NewMachineDisruptionBudget("mdbMaxUnavailable", nil, &maxUnavailable)
+
+	testsCases := []struct {
+		name           string
+		mdb            *mrv1.MachineDisruptionBudget
+		expectedStatus mrv1.MachineDisruptionBudgetStatus
+	}{
+		{
+			name: "with min available",
+			mdb:  mdb,
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				ObservedGeneration: 2,
+				DisruptionsAllowed: 0,
+				CurrentHealthy:     1,
+				DesiredHealthy:     1,
+				Total:              3,
+			},
+		},
+		{
+			name: "with max unavailable",
+			mdb:  mdbMaxUnavailable,
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DisruptionsAllowed: 1,
+				CurrentHealthy:     1,
+				DesiredHealthy:     0,
+				Total:              3,
+			},
+		},
+	}
+
+	for _, tc := range testsCases {
+		r := newFakeReconciler(tc.mdb, machine, node, remediatedMachine, remediatedNode, unhealthyMachine, unhealthyNode, mrActive)
+		key := types.NamespacedName{
+			Namespace: tc.mdb.Namespace,
+			Name:      tc.mdb.Name,
+		}
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		updated := &mrv1.MachineDisruptionBudget{}
+		if err := r.client.Get(context.TODO(), key, updated); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if updated.Status != tc.expectedStatus {
+			t.Errorf("Test case: %s. Expected status: %+v, got: %+v", tc.name, tc.expectedStatus, updated.Status)
+		}
+	}
+}
+
+func TestNodeBudgets(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	node := mrtesting.NewNode("node", true, "machine")
+	r := newFakeReconciler(
+		machine,
+		mrtesting.NewMachineDisruptionBudget("mdb1", nil, nil),
+		mrtesting.NewMachineDisruptionBudget("mdb2", nil, nil),
+	)
+
+	requests := r.nodeBudgets(handler.MapObject{Meta: node, Object: node})
+	if len(requests) != 2 {
+		t.Errorf("Expected requests for 2 budgets, got: %v", requests)
+	}
+}
+
+func TestNodeReadinessPredicate(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+
+	nodeHeartbeat := node.DeepCopy()
+	nodeHeartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+
+	nodeNotReady := node.DeepCopy()
+	nodeNotReady.Status.Conditions[0].Status = corev1.ConditionUnknown
+
+	testCases := []struct {
+		name     string
+		newNode  *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "with heartbeat",
+			newNode:  nodeHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "with not ready node",
+			newNode:  nodeNotReady,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		e := event.UpdateEvent{MetaOld: node, ObjectOld: node, MetaNew: tc.newNode, ObjectNew: tc.newNode}
+		if passed := nodeReadinessPredicate.Update(e); passed != tc.expected {
+			t.Errorf("Test case: %s. Expected: %t, got: %t", tc.name, tc.expected, passed)
+		}
+	}
+}
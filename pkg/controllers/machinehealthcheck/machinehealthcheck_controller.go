@@ -0,0 +1,428 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+	nodeutils "kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// failedRemediationBackoff contains the time after the failure of the machine remediation, when the machine
+	// health check does not create the new machine remediation of the same machine
+	failedRemediationBackoff = 10 * time.Minute
+)
+
+var _ reconcile.Reconciler = &ReconcileMachineHealthCheck{}
+
+// ReconcileMachineHealthCheck reconciles a MachineHealthCheck object
+type ReconcileMachineHealthCheck struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client client.Client
+	// reader reads config maps directly from the API server, to avoid the cache of all config maps under the cluster,
+	// and machine remediations, that the cache can miss right after their creation
+	reader    client.Reader
+	recorder  record.EventRecorder
+	namespace string
+}
+
+// Add creates a new MachineHealthCheck Controller and adds it to the Manager, the controller watches
+// machines of the machine API flavor. The Manager will set fields on the Controller and start it when the Manager is started.
+func Add(mgr manager.Manager, flavor machineapi.Flavor, opts manager.Options) error {
+	r, err := newReconciler(mgr, opts)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r, flavor)
+}
+
+func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileMachineHealthCheck, error) {
+	return &ReconcileMachineHealthCheck{
+		client:    mgr.GetClient(),
+		reader:    mgr.GetAPIReader(),
+		recorder:  mgr.GetEventRecorderFor("machinehealthcheck-controller"),
+		namespace: opts.Namespace,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileMachineHealthCheck, flavor machineapi.Flavor) error {
+	// Create a new controller
+	c, err := controller.New("machinehealthcheck-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &mrv1.MachineHealthCheck{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// health of selected machines depends on conditions of their nodes
+	if err := c.Watch(
+		&source.Kind{Type: &corev1.Node{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.nodeHealthChecks)},
+		nodeConditionsPredicate,
+	); err != nil {
+		return err
+	}
+	return c.Watch(
+		&source.Kind{Type: machineapi.NewMachineObject(flavor)},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.machineHealthChecks)},
+	)
+}
+
+// nodeConditionsPredicate passes the creation and the deletion of the node and the change of its conditions,
+// so node status heartbeats do not trigger the reconcile
+var nodeConditionsPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		newNode, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		return conditions.NodeConditionsChanged(oldNode, newNode)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// nodeHealthChecks returns requests for machine health checks that select the machine of the node
+func (r *ReconcileMachineHealthCheck) nodeHealthChecks(o handler.MapObject) []reconcile.Request {
+	node, ok := o.Object.(*corev1.Node)
+	if !ok {
+		return nil
+	}
+
+	machine, err := machineutils.GetMachineByNode(r.client, node)
+	if err != nil {
+		glog.V(4).Infof("Failed to get the machine of node %q: %v", node.Name, err)
+		return nil
+	}
+	return r.getHealthCheckRequests(machine)
+}
+
+// machineHealthChecks returns requests for machine health checks that select the machine
+func (r *ReconcileMachineHealthCheck) machineHealthChecks(o handler.MapObject) []reconcile.Request {
+	var machine *mapiv1.Machine
+	switch obj := o.Object.(type) {
+	case *mapiv1.Machine:
+		machine = obj
+	case *unstructured.Unstructured:
+		var err error
+		if machine, err = machineapi.ConvertMachine(obj, nil); err != nil {
+			glog.Errorf("Failed to map machine to machine health checks: %v", err)
+			return nil
+		}
+	default:
+		return nil
+	}
+	return r.getHealthCheckRequests(machine)
+}
+
+func (r *ReconcileMachineHealthCheck) getHealthCheckRequests(machine *mapiv1.Machine) []reconcile.Request {
+	mhcs := &mrv1.MachineHealthCheckList{}
+	if err := r.client.List(context.TODO(), mhcs, client.InNamespace(machine.Namespace)); err != nil {
+		glog.Errorf("Failed to list machine health checks: %v", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, mhc := range mhcs.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&mhc.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(machine.Labels)) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: mhc.Namespace, Name: mhc.Name},
+		})
+	}
+	return requests
+}
+
+// Reconcile checks nodes of machines that the MachineHealthCheck selects, and creates the MachineRemediation
+// for each machine with the node that has one of unhealthy conditions longer than its timeout
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileMachineHealthCheck) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	glog.V(4).Infof("Reconciling MachineHealthCheck triggered by %s/%s\n", request.Namespace, request.Name)
+
+	// Get MachineHealthCheck from request
+	mhc := &mrv1.MachineHealthCheck{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, mhc); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if mhc.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	unhealthyConditions, err := r.getUnhealthyConditions(mhc)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	machineList, err := machineutils.GetMachinesByLabelSelector(r.client, &mhc.Spec.Selector, mhc.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var selectedMachines []mapiv1.Machine
+	if machineList != nil {
+		selectedMachines = machineList.Items
+	}
+
+	now := time.Now()
+	healthy := 0
+	var requeueAfter time.Duration
+	var unhealthyTargets []target
+	for i := range selectedMachines {
+		machine := &selectedMachines[i]
+		// deleted machines are replaced by their owners, so they do not need the remediation
+		if machine.DeletionTimestamp != nil {
+			continue
+		}
+
+		node, err := nodeutils.GetNodeByMachine(r.client, machine)
+		if err != nil {
+			// the machine without the node is still provisioning, the health check starts once the node joins
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+
+		unhealthyCondition, expiresIn := conditions.GetUnhealthyCondition(node, unhealthyConditions, now)
+		if unhealthyCondition == nil {
+			healthy++
+			// check the node again once its condition expires
+			if expiresIn != 0 && (requeueAfter == 0 || expiresIn < requeueAfter) {
+				requeueAfter = expiresIn
+			}
+			continue
+		}
+
+		glog.V(4).Infof("Node %q of machine %q has condition %s with status %s longer than %s", node.Name, machine.Name, unhealthyCondition.Type, unhealthyCondition.Status, unhealthyCondition.Timeout.Duration)
+		unhealthyTargets = append(unhealthyTargets, target{machine: machine, node: node})
+	}
+
+	if err := r.updateStatus(mhc, int32(len(selectedMachines)), int32(healthy)); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if len(unhealthyTargets) != 0 {
+		allowed, err := isRemediationAllowed(mhc, len(selectedMachines), len(unhealthyTargets))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if !allowed {
+			glog.Warningf("Machine health check %q has %d unhealthy machines, more than the max unhealthy %s, skip the remediation", mhc.Name, len(unhealthyTargets), mhc.Spec.MaxUnhealthy.String())
+			r.recorder.Eventf(
+				mhc,
+				corev1.EventTypeWarning,
+				"MachineRemediationRestricted",
+				"Remediation restricted, %d of %d machines are unhealthy, max unhealthy is %s",
+				len(unhealthyTargets),
+				len(selectedMachines),
+				mhc.Spec.MaxUnhealthy.String(),
+			)
+		} else {
+			for _, t := range unhealthyTargets {
+				backoff, err := r.remediate(mhc, t.machine, t.node, now)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+				// check the machine again once the backoff of its failed remediation expires
+				if backoff != 0 && (requeueAfter == 0 || backoff < requeueAfter) {
+					requeueAfter = backoff
+				}
+			}
+		}
+	}
+
+	if requeueAfter != 0 {
+		return reconcile.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// target contains the unhealthy machine and its node
+type target struct {
+	machine *mapiv1.Machine
+	node    *corev1.Node
+}
+
+// getUnhealthyConditions returns unhealthy conditions of the machine health check, of the config map
+// under its namespace, or the default ones
+func (r *ReconcileMachineHealthCheck) getUnhealthyConditions(mhc *mrv1.MachineHealthCheck) ([]mrv1.UnhealthyCondition, error) {
+	if len(mhc.Spec.UnhealthyConditions) != 0 {
+		return mhc.Spec.UnhealthyConditions, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{
+		Namespace: mhc.Namespace,
+		Name:      mrv1.ConfigMapNodeUnhealthyConditions,
+	}
+	if err := r.reader.Get(context.TODO(), key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return conditions.DefaultUnhealthyConditions(), nil
+		}
+		return nil, err
+	}
+	return conditions.ParseUnhealthyConditions(cm)
+}
+
+// isRemediationAllowed returns true when the number of unhealthy machines does not exceed the max unhealthy
+func isRemediationAllowed(mhc *mrv1.MachineHealthCheck, selectedMachines int, unhealthyMachines int) (bool, error) {
+	if mhc.Spec.MaxUnhealthy == nil {
+		return true, nil
+	}
+
+	maxUnhealthy, err := intstr.GetValueFromIntOrPercent(mhc.Spec.MaxUnhealthy, selectedMachines, false)
+	if err != nil {
+		return false, err
+	}
+	return unhealthyMachines <= maxUnhealthy, nil
+}
+
+// remediate creates the machine remediation for the unhealthy machine, unless the machine already has
+// the machine remediation in progress, or its machine remediation failed recently. It returns the time
+// until the backoff of the failed machine remediation expires.
+func (r *ReconcileMachineHealthCheck) remediate(mhc *mrv1.MachineHealthCheck, machine *mapiv1.Machine, node *corev1.Node, now time.Time) (time.Duration, error) {
+	// the machine remediation created by the previous reconcile can be missing under the cache
+	mrs := &mrv1.MachineRemediationList{}
+	if err := r.reader.List(context.TODO(), mrs, client.InNamespace(machine.Namespace)); err != nil {
+		return 0, err
+	}
+
+	if isRemediationInProgress(mrs.Items, machine) {
+		glog.V(4).Infof("Machine %q already has the machine remediation in progress", machine.Name)
+		return 0, nil
+	}
+
+	if backoff := getFailedRemediationBackoff(mrs.Items, machine, now); backoff != 0 {
+		glog.V(4).Infof("Machine %q has the failed machine remediation, the next one starts in %s", machine.Name, backoff)
+		return backoff, nil
+	}
+
+	// Creates new machine remediation object
+	mr := &mrv1.MachineRemediation{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "remediation-",
+			Namespace:    machine.Namespace,
+		},
+		Spec: mrv1.MachineRemediationSpec{
+			MachineName:      machine.Name,
+			Type:             mrv1.RemediationTypeReboot,
+			SavedAnnotations: node.Annotations,
+			SavedLabels:      node.Labels,
+			SavedTaints:      nodeutils.GetSavedTaints(node),
+		},
+	}
+
+	// the machine remediation policy of the machine replaces the default reboot with its strategies
+	if err := policies.SetMachineStrategies(r.client, machine, mr); err != nil {
+		return 0, err
+	}
+
+	if err := r.client.Create(context.TODO(), mr); err != nil {
+		return 0, err
+	}
+
+	glog.Infof("Machine health check %q created the machine remediation %q for machine %q", mhc.Name, mr.Name, machine.Name)
+	r.recorder.Eventf(
+		mhc,
+		corev1.EventTypeNormal,
+		"MachineRemediationCreated",
+		"Created the machine remediation for unhealthy machine %q",
+		machine.Name,
+	)
+	return 0, nil
+}
+
+// updateStatus updates counters of machines under the machine health check status
+func (r *ReconcileMachineHealthCheck) updateStatus(mhc *mrv1.MachineHealthCheck, expected int32, healthy int32) error {
+	status := mrv1.MachineHealthCheckStatus{
+		ObservedGeneration: mhc.Generation,
+		ExpectedMachines:   expected,
+		CurrentHealthy:     healthy,
+	}
+	if mhc.Status == status {
+		return nil
+	}
+
+	// Copy the machine health check object to prevent modification of the original one
+	mhcCopy := mhc.DeepCopy()
+	mhcCopy.Status = status
+	return r.client.Status().Update(context.TODO(), mhcCopy)
+}
+
+// isRemediationInProgress returns true when the machine has the machine remediation that did not end yet
+func isRemediationInProgress(mrs []mrv1.MachineRemediation, machine *mapiv1.Machine) bool {
+	for _, mr := range mrs {
+		if mr.Spec.MachineName == machine.Name && mr.Status.EndTime == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getFailedRemediationBackoff returns the time until the backoff of the last failed machine remediation
+// of the machine expires, or zero when the machine does not have the recently failed machine remediation
+func getFailedRemediationBackoff(mrs []mrv1.MachineRemediation, machine *mapiv1.Machine, now time.Time) time.Duration {
+	var backoff time.Duration
+	for _, mr := range mrs {
+		if mr.Spec.MachineName != machine.Name || mr.Status.State != mrv1.RemediationStateFailed || mr.Status.EndTime == nil {
+			continue
+		}
+		if remaining := mr.Status.EndTime.Add(failedRemediationBackoff).Sub(now); remaining > backoff {
+			backoff = remaining
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,296 @@
+package machinehealthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+// newFakeReconciler returns a new reconcile.Reconciler with a fake client
+func newFakeReconciler(recorder record.EventRecorder, initObjects ...runtime.Object) *ReconcileMachineHealthCheck {
+	fakeClient := fake.NewFakeClient(initObjects...)
+	return &ReconcileMachineHealthCheck{
+		client:    fakeClient,
+		reader:    fakeClient,
+		recorder:  recorder,
+		namespace: consts.NamespaceOpenshiftMachineAPI,
+	}
+}
+
+type expectedReconcile struct {
+	result reconcile.Result
+	error  bool
+}
+
+func TestReconcile(t *testing.T) {
+	// healthy node
+	nodeHealthy := mrtesting.NewNode("healthy", true, "machineHealthy")
+	machineHealthy := mrtesting.NewMachine("machineHealthy", nodeHealthy.Name, "")
+
+	// unhealthy node, the not ready condition of test nodes started long time ago
+	nodeUnhealthy := mrtesting.NewNode("unhealthy", false, "machineUnhealthy")
+	machineUnhealthy := mrtesting.NewMachine("machineUnhealthy", nodeUnhealthy.Name, "")
+
+	// machine without the node
+	machineWithoutNode := mrtesting.NewMachine("machineWithoutNode", "", "")
+
+	// machine that the health check does not select
+	nodeNotSelected := mrtesting.NewNode("notSelected", false, "machineNotSelected")
+	machineNotSelected := mrtesting.NewMachine("machineNotSelected", nodeNotSelected.Name, "")
+	machineNotSelected.Labels = map[string]string{"pool": "other"}
+
+	// the machine remediation in progress
+	mrInProgress := mrtesting.NewMachineRemediation("mrInProgress", machineUnhealthy.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	// the machine remediation that failed recently, and the one that failed before the backoff
+	mrFailed := mrtesting.NewMachineRemediation("mrFailed", machineUnhealthy.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateFailed)
+	mrFailed.Status.EndTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	mrFailedBeforeBackoff := mrFailed.DeepCopy()
+	mrFailedBeforeBackoff.Status.EndTime = &metav1.Time{Time: time.Now().Add(-failedRemediationBackoff - time.Minute)}
+
+	mhc := mrtesting.NewMachineHealthCheck("mhc")
+
+	mhcMaxUnhealthy := mrtesting.NewMachineHealthCheck("mhcMaxUnhealthy")
+	maxUnhealthy := intstr.FromString("20%")
+	mhcMaxUnhealthy.Spec.MaxUnhealthy = &maxUnhealthy
+
+	// the condition that no test node has
+	mhcWithConditions := mrtesting.NewMachineHealthCheck("mhcWithConditions")
+	mhcWithConditions.Spec.UnhealthyConditions = []mrv1.UnhealthyCondition{
+		{
+			Type:   corev1.NodeReady,
+			Status: corev1.ConditionFalse,
+		},
+	}
+
+	// the config map with the condition that no test node has
+	cmNotReady := mrtesting.NewUnhealthyConditionsConfigMap(
+		mrv1.ConfigMapNodeUnhealthyConditions,
+		"items:\n- name: Ready\n  timeout: 60s\n  status: \"False\"",
+	)
+
+	testsCases := []struct {
+		name                 string
+		mhc                  *mrv1.MachineHealthCheck
+		extraObjects         []runtime.Object
+		expected             expectedReconcile
+		expectedEvents       []string
+		expectedStatus       mrv1.MachineHealthCheckStatus
+		expectedRemediations int
+		expectedBackoff      bool
+	}{
+		{
+			name:                 "with unhealthy machine",
+			mhc:                  mhc,
+			expectedEvents:       []string{"MachineRemediationCreated"},
+			expectedStatus:       mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 1},
+			expectedRemediations: 1,
+		},
+		{
+			name:           "with unhealthy machine over max unhealthy",
+			mhc:            mhcMaxUnhealthy,
+			expectedEvents: []string{"MachineRemediationRestricted"},
+			expectedStatus: mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 1},
+		},
+		{
+			name:                 "with machine remediation in progress",
+			mhc:                  mhc,
+			extraObjects:         []runtime.Object{mrInProgress},
+			expectedStatus:       mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 1},
+			expectedRemediations: 1,
+		},
+		{
+			name:                 "with recently failed machine remediation",
+			mhc:                  mhc,
+			extraObjects:         []runtime.Object{mrFailed},
+			expectedStatus:       mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 1},
+			expectedRemediations: 1,
+			expectedBackoff:      true,
+		},
+		{
+			name:                 "with machine remediation failed before the backoff",
+			mhc:                  mhc,
+			extraObjects:         []runtime.Object{mrFailedBeforeBackoff},
+			expectedEvents:       []string{"MachineRemediationCreated"},
+			expectedStatus:       mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 1},
+			expectedRemediations: 2,
+		},
+		{
+			name:           "with unhealthy conditions under the spec",
+			mhc:            mhcWithConditions,
+			expectedStatus: mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 2},
+		},
+		{
+			name:           "with unhealthy conditions under the config map",
+			mhc:            mhc,
+			extraObjects:   []runtime.Object{cmNotReady},
+			expectedStatus: mrv1.MachineHealthCheckStatus{ExpectedMachines: 3, CurrentHealthy: 2},
+		},
+	}
+
+	for _, tc := range testsCases {
+		objects := []runtime.Object{
+			tc.mhc.DeepCopy(),
+			nodeHealthy,
+			machineHealthy,
+			nodeUnhealthy,
+			machineUnhealthy,
+			machineWithoutNode,
+			nodeNotSelected,
+			machineNotSelected,
+		}
+		objects = append(objects, tc.extraObjects...)
+
+		recorder := record.NewFakeRecorder(10)
+		r := newFakeReconciler(recorder, objects...)
+		key := types.NamespacedName{
+			Namespace: tc.mhc.Namespace,
+			Name:      tc.mhc.Name,
+		}
+		result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		if tc.expected.error != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %v, got: %v", tc.name, tc.expected.error, err)
+		}
+
+		// the reconcile checks the machine again once the backoff of its failed remediation expires
+		if tc.expectedBackoff {
+			if !result.Requeue || result.RequeueAfter <= 0 || result.RequeueAfter > failedRemediationBackoff {
+				t.Errorf("Test case: %s. Expected: requeue within the backoff, got: %v", tc.name, result)
+			}
+		} else if result != tc.expected.result {
+			t.Errorf("Test case: %s. Expected result: %v, got: %v", tc.name, tc.expected.result, result)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		updated := &mrv1.MachineHealthCheck{}
+		if err := r.client.Get(context.TODO(), key, updated); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if updated.Status != tc.expectedStatus {
+			t.Errorf("Test case: %s. Expected status: %+v, got: %+v", tc.name, tc.expectedStatus, updated.Status)
+		}
+
+		mrs := &mrv1.MachineRemediationList{}
+		if err := r.client.List(context.TODO(), mrs, client.InNamespace(consts.NamespaceOpenshiftMachineAPI)); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		remediated := map[string]int{}
+		for _, mr := range mrs.Items {
+			remediated[mr.Spec.MachineName]++
+		}
+
+		if remediated[machineUnhealthy.Name] != tc.expectedRemediations {
+			t.Errorf("Test case: %s. Expected: %d machine remediations for machine %q, got: %d", tc.name, tc.expectedRemediations, machineUnhealthy.Name, remediated[machineUnhealthy.Name])
+		}
+
+		if remediated[machineNotSelected.Name] != 0 {
+			t.Errorf("Test case: %s. Expected: no machine remediation for machine %q, got: %d", tc.name, machineNotSelected.Name, remediated[machineNotSelected.Name])
+		}
+	}
+}
+
+func TestNodeHealthChecks(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	machine := mrtesting.NewMachine("machine", node.Name, "")
+	mhcEmptySelector := mrtesting.NewMachineHealthCheck("mhcEmptySelector")
+	mhcEmptySelector.Spec.Selector = *mrtesting.NewSelector(nil)
+
+	r := newFakeReconciler(
+		record.NewFakeRecorder(10),
+		machine,
+		mrtesting.NewMachineHealthCheck("mhc"),
+		mhcEmptySelector,
+	)
+
+	requests := r.nodeHealthChecks(handler.MapObject{Meta: node, Object: node})
+	if len(requests) != 1 || requests[0].Name != "mhc" {
+		t.Errorf("Expected request for the machine health check %q, got: %v", "mhc", requests)
+	}
+}
+
+func TestMachineHealthChecks(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	r := newFakeReconciler(record.NewFakeRecorder(10), mrtesting.NewMachineHealthCheck("mhc"))
+
+	// the Cluster API machine is watched as the unstructured object
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(machine)
+	if err != nil {
+		t.Fatalf("Failed to convert machine: %v", err)
+	}
+	clusterAPIMachine := &unstructured.Unstructured{Object: content}
+	clusterAPIMachine.SetGroupVersionKind(machineapi.ClusterAPIMachineGVK)
+
+	for _, object := range []runtime.Object{machine, clusterAPIMachine} {
+		requests := r.machineHealthChecks(handler.MapObject{Object: object})
+		if len(requests) != 1 || requests[0].Name != "mhc" {
+			t.Errorf("Expected request for the machine health check %q of %T, got: %v", "mhc", object, requests)
+		}
+	}
+}
+
+func TestNodeConditionsPredicate(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+
+	nodeHeartbeat := node.DeepCopy()
+	nodeHeartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+
+	nodeDiskPressure := node.DeepCopy()
+	nodeDiskPressure.Status.Conditions = append(nodeDiskPressure.Status.Conditions, corev1.NodeCondition{
+		Type:   corev1.NodeDiskPressure,
+		Status: corev1.ConditionTrue,
+	})
+
+	testCases := []struct {
+		name     string
+		newNode  *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "with heartbeat",
+			newNode:  nodeHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "with new condition",
+			newNode:  nodeDiskPressure,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		e := event.UpdateEvent{MetaOld: node, ObjectOld: node, MetaNew: tc.newNode, ObjectNew: tc.newNode}
+		if passed := nodeConditionsPredicate.Update(e); passed != tc.expected {
+			t.Errorf("Test case: %s. Expected: %t, got: %t", tc.name, tc.expected, passed)
+		}
+	}
+}
@@ -2,15 +2,30 @@ package machineremediation
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/golang/glog"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/budgets"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/controlplane"
+	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
 
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -19,44 +34,89 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+const (
+	// pollInterval contains the interval of the reconcile of machine remediations in progress,
+	// that wait for changes not reported by watches
+	pollInterval = 10 * time.Second
+	// safetyNetInterval contains the interval of the reconcile of machine remediations in progress,
+	// that changes of watched objects reconcile, it catches up on expired timeouts and missed events
+	safetyNetInterval = time.Minute
+	// succeededRemediationRetention contains the time that the succeeded machine remediation keeps
+	// the history of its attempts and approved certificates, before the controller deletes it
+	succeededRemediationRetention = 24 * time.Hour
+)
+
 var _ reconcile.Reconciler = &ReconcileMachineRemediation{}
 
 // ReconcileMachineRemediation reconciles a MachineRemediation object
 type ReconcileMachineRemediation struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client     client.Client
+	client client.Client
+	// reader reads etcd member pods directly from the API server, to avoid the cache of all pods under the cluster,
+	// and machine remediations, that budgets count, without the delay of the cache
+	reader client.Reader
+	// etcd lists etcd members for the quorum check of control plane remediations,
+	// the quorum follows control plane machines when it is nil
+	etcd       *etcd.Client
+	recorder   record.EventRecorder
 	remediator Remediator
 	namespace  string
 }
 
 // AddWithRemediator creates a new MachineRemediation Controller with remediator and adds it to the Manager.
 // The Manager will set fields on the Controller and start it when the Manager is started.
-func AddWithRemediator(mgr manager.Manager, remediator Remediator, opts manager.Options) error {
-	r, err := newReconciler(mgr, remediator, opts)
+func AddWithRemediator(mgr manager.Manager, remediator Remediator, flavor machineapi.Flavor, etcdClient *etcd.Client, opts manager.Options) error {
+	r, err := newReconciler(mgr, remediator, etcdClient, opts)
 	if err != nil {
 		return err
 	}
-	return add(mgr, r)
+	return add(mgr, r, remediator, flavor)
 }
 
-func newReconciler(mgr manager.Manager, remediator Remediator, opts manager.Options) (reconcile.Reconciler, error) {
+func newReconciler(mgr manager.Manager, remediator Remediator, etcdClient *etcd.Client, opts manager.Options) (*ReconcileMachineRemediation, error) {
 	return &ReconcileMachineRemediation{
 		client:     mgr.GetClient(),
+		reader:     mgr.GetAPIReader(),
+		etcd:       etcdClient,
+		recorder:   mgr.GetEventRecorderFor("machineremediation-controller"),
 		remediator: remediator,
 		namespace:  opts.Namespace,
 	}, nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r *ReconcileMachineRemediation, remediator Remediator, flavor machineapi.Flavor) error {
 	// Create a new controller
 	c, err := controller.New("machineremediation-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
 		return err
 	}
 
-	return c.Watch(&source.Kind{Type: &mrv1.MachineRemediation{}}, &handler.EnqueueRequestForObject{})
+	if err := c.Watch(&source.Kind{Type: &mrv1.MachineRemediation{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// changes of machines and nodes are mapped to machine remediations in progress of the machine
+	if err := c.Watch(
+		&source.Kind{Type: machineapi.NewMachineObject(flavor)},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.machineToRequests)},
+	); err != nil {
+		return err
+	}
+	if err := c.Watch(
+		&source.Kind{Type: &corev1.Node{}},
+		&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.nodeToRequests)},
+		nodeChangePredicate,
+	); err != nil {
+		return err
+	}
+
+	// the remediator reconciles machine remediations on changes of its infrastructure objects
+	if watcher, ok := remediator.(Watcher); ok {
+		return watcher.Watch(c)
+	}
+	return nil
 }
 
 // Reconcile monitors MachineRemediation and apply the remediation strategy in the case when the
@@ -81,25 +141,157 @@ func (r *ReconcileMachineRemediation) Reconcile(request reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
-	// we do not want to do anything on delete objects
+	// we do not want to do anything on delete objects, except the cancel of the remediation
 	if mr.DeletionTimestamp != nil {
+		if canceler, ok := r.remediator.(Canceler); ok {
+			glog.V(4).Infof("Cancel remediation for MachineRemediation %s", mr.Name)
+			if err := canceler.Cancel(context.TODO(), mr); err != nil {
+				glog.Errorf("Cancel of MachineRemediation %s failed with error: %v", mr.Name, err)
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// the machine remediation created by the MachineHealthCheck from the template waits until
+	// the external remediation controller binds it to the machine owner
+	if mr.Spec.MachineName == "" && machineapi.GetMachineOwner(mr) != nil {
+		glog.V(4).Infof("MachineRemediation %s waits for the machine name of its owner", mr.Name)
 		return reconcile.Result{}, nil
 	}
 
-	if mr.Status.State == "" {
+	if mr.Status.State == "" || mr.Status.State == mrv1.RemediationStatePending {
+		// the remediation starts only when the machine remediation policy of the machine allows it
+		machine, err := r.getMachine(mr)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		var policy *mrv1.MachineRemediationPolicy
+		if machine != nil {
+			policy, err = policies.GetMachinePolicy(r.client, machine)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		if policy != nil {
+			if remediationType := policies.GetDisallowedType(policy, mr); remediationType != "" {
+				return reconcile.Result{}, r.failNotAllowed(mr, policy, remediationType)
+			}
+
+			throttled, err := r.throttle(mr, policy)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if throttled {
+				return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+			}
+		}
+
+		// and machine disruption budgets of the machine allow one more disruption
+		if machine != nil {
+			pending, err := r.waitForBudget(mr, machine)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if pending {
+				return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+			}
+		}
+
+		// and the remediation of the control plane machine keeps the etcd quorum
+		if machine != nil && machineutils.IsControlPlane(machine) {
+			pending, err := r.waitForControlPlane(mr, machine)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			if pending {
+				return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+			}
+		}
+
+		// the escalation that waited for the control plane starts the attempt with the next strategy
+		if strategies.GetCurrentAttempt(mr) != nil {
+			mrCopy := mr.DeepCopy()
+			if err := r.escalate(mrCopy); err != nil {
+				return reconcile.Result{}, err
+			}
+			if err := r.client.Status().Update(context.TODO(), mrCopy); err != nil {
+				glog.Errorf("failed to update MR %q status: %v", mr.Name, err)
+				return reconcile.Result{}, err
+			}
+			return reconcile.Result{Requeue: true, RequeueAfter: r.getRequeueAfter(mrCopy)}, nil
+		}
+
 		mrCopy := mr.DeepCopy()
+		now := time.Now()
 		mrCopy.Status = mrv1.MachineRemediationStatus{
-			State:     mrv1.RemediationStateStarted,
-			Reason:    "Machine remediation started",
-			StartTime: &metav1.Time{Time: time.Now()},
+			ObservedGeneration:  mr.Generation,
+			State:               mrv1.RemediationStateStarted,
+			Reason:              mrv1.RemediationReasonInProgress,
+			Message:             "Machine remediation started",
+			StartTime:           &metav1.Time{Time: now},
+			StateTransitionTime: &metav1.Time{Time: now},
+		}
+		strategies.StartAttempt(mrCopy, strategies.GetCurrentStrategy(mr), metav1.Time{Time: now})
+
+		// record the boot ID of the node, the reboot succeeds only once the node boots again
+		if machine != nil {
+			bootID, err := nodes.GetBootIDByMachine(r.client, machine)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			mrCopy.Status.NodeBootID = bootID
+		}
+
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionSucceeded,
+			corev1.ConditionUnknown,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
+		if err := r.client.Status().Update(context.TODO(), mrCopy); err != nil {
+			glog.Errorf("failed to update MR %q status: %v", mr.Name, err)
+			return reconcile.Result{}, err
 		}
+	} else if mr.Status.ObservedGeneration != mr.Generation {
+		mrCopy := mr.DeepCopy()
+		mrCopy.Status.ObservedGeneration = mr.Generation
 		if err := r.client.Status().Update(context.TODO(), mrCopy); err != nil {
 			glog.Errorf("failed to update MR %q status: %v", mr.Name, err)
 			return reconcile.Result{}, err
 		}
+		// continue with the updated object to avoid the conflict on the next update
+		mr = mrCopy
 	}
 
-	switch mr.Spec.Type {
+	// record the outcome of the finished attempt and move to the next strategy when the attempt failed
+	if mr.Status.State == mrv1.RemediationStateFailed || mr.Status.State == mrv1.RemediationStateSucceeded {
+		mrCopy := mr.DeepCopy()
+		if strategies.FinishAttempt(mrCopy, metav1.Now()) {
+			if mr.Status.State == mrv1.RemediationStateFailed && strategies.GetNextStrategy(mrCopy) != nil {
+				// the next attempt disrupts the control plane machine again, so it passes the same checks as the first one
+				pending, err := r.waitForEscalation(mrCopy)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+				if pending {
+					return reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+				}
+				if err := r.escalate(mrCopy); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+			if err := r.client.Status().Update(context.TODO(), mrCopy); err != nil {
+				glog.Errorf("failed to update MR %q status: %v", mr.Name, err)
+				return reconcile.Result{}, err
+			}
+			mr = mrCopy
+		}
+	}
+
+	switch strategies.GetCurrentStrategy(mr).Type {
 	case mrv1.RemediationTypeReboot:
 		glog.V(4).Infof("Run remediation reboot acion for MachineRemediation %s", mr.Name)
 		if err := r.remediator.Reboot(context.TODO(), mr); err != nil {
@@ -115,11 +307,303 @@ func (r *ReconcileMachineRemediation) Reconcile(request reconcile.Request) (reco
 	}
 
 	switch mr.Status.State {
-	// we want to stop reconcile the object once it reaches Succeeded or Failed state
-	case mrv1.RemediationStateFailed, mrv1.RemediationStateSucceeded:
+	// we want to stop reconcile the object once it reaches Failed state
+	case mrv1.RemediationStateFailed:
 		return reconcile.Result{}, nil
-	// for all other cases we want to reconcile object in ten seconds, to give time for the object update
+	// the succeeded object is reconciled again only to delete it once its retention expires
+	case mrv1.RemediationStateSucceeded:
+		return r.deleteSucceeded(mr, time.Now())
+	// for all other cases we want to reconcile object again, changes of watched objects trigger the reconcile earlier
 	default:
-		return reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second}, nil
+		return reconcile.Result{Requeue: true, RequeueAfter: r.getRequeueAfter(mr)}, nil
+	}
+}
+
+// getRequeueAfter returns the interval of the next reconcile of the machine remediation in progress, remediations
+// of the remediator that watches its infrastructure are reconciled on changes, so they are polled only as the safety net
+func (r *ReconcileMachineRemediation) getRequeueAfter(mr *mrv1.MachineRemediation) time.Duration {
+	if watcher, ok := r.remediator.(Watcher); ok && !watcher.NeedsPolling(mr) {
+		return safetyNetInterval
+	}
+	return pollInterval
+}
+
+// deleteSucceeded deletes the succeeded machine remediation once its retention expires, the machine remediation
+// created by the upstream MachineHealthCheck for the machine owner is left to its creator
+func (r *ReconcileMachineRemediation) deleteSucceeded(mr *mrv1.MachineRemediation, now time.Time) (reconcile.Result, error) {
+	if mr.Status.EndTime == nil || machineapi.GetMachineOwner(mr) != nil {
+		return reconcile.Result{}, nil
+	}
+
+	if remaining := mr.Status.EndTime.Add(succeededRemediationRetention).Sub(now); remaining > 0 {
+		return reconcile.Result{Requeue: true, RequeueAfter: remaining}, nil
+	}
+
+	glog.V(4).Infof("Delete MachineRemediation %s, its retention expired", mr.Name)
+	if err := r.client.Delete(context.TODO(), mr); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// getMachine returns the remediated machine, or nil when the machine does not exist
+func (r *ReconcileMachineRemediation) getMachine(mr *mrv1.MachineRemediation) (*mapiv1.Machine, error) {
+	if mr.Spec.MachineName == "" {
+		return nil, nil
+	}
+
+	machine := &mapiv1.Machine{}
+	key := types.NamespacedName{
+		Namespace: mr.Namespace,
+		Name:      mr.Spec.MachineName,
+	}
+	if err := r.client.Get(context.TODO(), key, machine); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return machine, nil
+}
+
+// failNotAllowed fails the machine remediation, that has the remediation type not allowed by the policy
+func (r *ReconcileMachineRemediation) failNotAllowed(mr *mrv1.MachineRemediation, policy *mrv1.MachineRemediationPolicy, remediationType mrv1.RemediationType) error {
+	glog.Warningf("Remediation %s of machine %q is not allowed by the policy %q", remediationType, mr.Spec.MachineName, policy.Name)
+	r.recorder.Eventf(
+		mr,
+		corev1.EventTypeWarning,
+		"MachineRemediationNotAllowed",
+		"Remediation %s of machine %q is not allowed by the policy %q",
+		remediationType,
+		mr.Spec.MachineName,
+		policy.Name,
+	)
+
+	// Copy the machine remediation object to prevent modification of the original one
+	mrCopy := mr.DeepCopy()
+	now := metav1.Now()
+	mrCopy.Status = mrv1.MachineRemediationStatus{
+		ObservedGeneration:  mr.Generation,
+		State:               mrv1.RemediationStateFailed,
+		Reason:              mrv1.RemediationReasonNotAllowedByPolicy,
+		Message:             fmt.Sprintf("Remediation %s is not allowed by the policy %q", remediationType, policy.Name),
+		StartTime:           &now,
+		StateTransitionTime: &now,
+		EndTime:             &now,
+	}
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonNotAllowedByPolicy,
+		mrCopy.Status.Message,
+	)
+	return r.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// throttle returns true when the policy reached the limit of concurrent remediations,
+// and moves the machine remediation to the pending state until one of active remediations finishes
+func (r *ReconcileMachineRemediation) throttle(mr *mrv1.MachineRemediation, policy *mrv1.MachineRemediationPolicy) (bool, error) {
+	selectedMachines, err := policies.GetSelectedMachines(r.client, policy)
+	if err != nil {
+		return false, err
+	}
+
+	maxConcurrent, limited, err := policies.GetMaxConcurrent(policy, len(selectedMachines))
+	if err != nil || !limited {
+		return false, err
+	}
+
+	// the live list of machine remediations counts remediations that started just before this one
+	active, err := policies.CountActiveRemediations(r.reader, policy.Namespace, selectedMachines)
+	if err != nil {
+		return false, err
+	}
+	if active < maxConcurrent {
+		return false, nil
+	}
+
+	glog.V(4).Infof("Remediation of machine %q waits for %d active remediations of the policy %q", mr.Spec.MachineName, active, policy.Name)
+	return true, r.setPending(
+		mr,
+		mrv1.RemediationReasonThrottled,
+		corev1.EventTypeNormal,
+		"MachineRemediationThrottled",
+		fmt.Sprintf("Waiting for active remediations, the policy %q allows %d concurrent remediations", policy.Name, maxConcurrent),
+	)
+}
+
+// waitForBudget returns true when one of machine disruption budgets of the machine does not allow its disruption,
+// and moves the machine remediation to the pending state until the budget allows it
+func (r *ReconcileMachineRemediation) waitForBudget(mr *mrv1.MachineRemediation, machine *mapiv1.Machine) (bool, error) {
+	// the live list of machine remediations counts remediations that started just before this one
+	mdb, err := budgets.GetExceededBudget(r.client, r.reader, machine)
+	if err != nil || mdb == nil {
+		return false, err
+	}
+
+	glog.V(4).Infof("Remediation of machine %q waits for the machine disruption budget %q", machine.Name, mdb.Name)
+	return true, r.setPending(
+		mr,
+		mrv1.RemediationReasonDisruptionBudgetExceeded,
+		corev1.EventTypeNormal,
+		"MachineRemediationPending",
+		fmt.Sprintf("Waiting for the machine disruption budget %q, it does not allow more disruptions", mdb.Name),
+	)
+}
+
+// waitForControlPlane returns true when the remediation of the control plane machine can not start, because other
+// control plane machine has the remediation in progress, or because the remediation would leave the etcd cluster
+// without the quorum and nobody approved it, and moves the machine remediation to the pending state
+func (r *ReconcileMachineRemediation) waitForControlPlane(mr *mrv1.MachineRemediation, machine *mapiv1.Machine) (bool, error) {
+	controlPlaneMachines, err := controlplane.GetMachines(r.client, machine.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	// remediate only one control plane machine at a time
+	activeName, err := controlplane.GetActiveRemediation(r.reader, mr, controlPlaneMachines)
+	if err != nil {
+		return false, err
+	}
+	if activeName != "" {
+		glog.V(4).Infof("Remediation of control plane machine %q waits for the machine remediation %q", machine.Name, activeName)
+		return true, r.setPending(
+			mr,
+			mrv1.RemediationReasonControlPlaneRemediationInProgress,
+			corev1.EventTypeNormal,
+			"MachineRemediationPending",
+			fmt.Sprintf("Waiting for the control plane machine remediation %q", activeName),
+		)
 	}
+
+	quorum, err := controlplane.GetQuorum(r.client, r.reader, r.etcd, controlPlaneMachines)
+	if err != nil {
+		return false, err
+	}
+
+	if quorum.IsSafeWithout(machine.Name) {
+		r.recorder.Eventf(
+			mr,
+			corev1.EventTypeNormal,
+			"MachineRemediationQuorumChecked",
+			"Remediation of control plane machine %q keeps the etcd quorum, %s",
+			machine.Name,
+			quorum,
+		)
+		return false, nil
+	}
+
+	if mr.Annotations[consts.AnnotationQuorumRiskApproved] == "true" {
+		glog.Warningf("Remediation of control plane machine %q puts the etcd quorum at risk, but it was approved, %s", machine.Name, quorum)
+		r.recorder.Eventf(
+			mr,
+			corev1.EventTypeWarning,
+			"MachineRemediationQuorumRiskApproved",
+			"Remediation of control plane machine %q puts the etcd quorum at risk, %s, the remediation was approved",
+			machine.Name,
+			quorum,
+		)
+		return false, nil
+	}
+
+	glog.Warningf("Remediation of control plane machine %q puts the etcd quorum at risk, %s", machine.Name, quorum)
+	return true, r.setPending(
+		mr,
+		mrv1.RemediationReasonQuorumAtRisk,
+		corev1.EventTypeWarning,
+		"MachineRemediationQuorumAtRisk",
+		fmt.Sprintf(
+			"Remediation puts the etcd quorum at risk, %s, set the %q annotation to \"true\" to approve it",
+			quorum,
+			consts.AnnotationQuorumRiskApproved,
+		),
+	)
+}
+
+// waitForEscalation returns true when the escalation of the failed remediation of the control plane machine
+// can not start yet, and moves the machine remediation with the finished attempt to the pending state
+func (r *ReconcileMachineRemediation) waitForEscalation(mrCopy *mrv1.MachineRemediation) (bool, error) {
+	machine, err := r.getMachine(mrCopy)
+	if err != nil || machine == nil || !machineutils.IsControlPlane(machine) {
+		return false, err
+	}
+
+	// the pending machine remediation did not end, the escalation starts once the checks pass
+	mrCopy.Status.EndTime = nil
+	return r.waitForControlPlane(mrCopy, machine)
+}
+
+// setPending moves the machine remediation to the pending state with the reason,
+// and records the event once the machine remediation gets the new reason
+func (r *ReconcileMachineRemediation) setPending(mr *mrv1.MachineRemediation, reason mrv1.RemediationReason, eventType string, eventReason string, message string) error {
+	if mr.Status.State == mrv1.RemediationStatePending && mr.Status.Reason == reason {
+		return nil
+	}
+
+	r.recorder.Event(mr, eventType, eventReason, message)
+
+	// Copy the machine remediation object to prevent modification of the original one
+	mrCopy := mr.DeepCopy()
+	now := metav1.Now()
+	mrCopy.Status.ObservedGeneration = mr.Generation
+	mrCopy.Status.State = mrv1.RemediationStatePending
+	mrCopy.Status.Reason = reason
+	mrCopy.Status.Message = message
+	mrCopy.Status.StateTransitionTime = &now
+	return r.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// escalate starts the attempt with the next remediation strategy of the machine remediation.
+// The new attempt records the current boot ID, so the reboot of the failed attempt does not finish it.
+func (r *ReconcileMachineRemediation) escalate(mrCopy *mrv1.MachineRemediation) error {
+	failedAttempt := strategies.GetCurrentAttempt(mrCopy)
+	nextStrategy := strategies.GetNextStrategy(mrCopy)
+	if nextStrategy == nil {
+		return nil
+	}
+
+	machine, err := r.getMachine(mrCopy)
+	if err != nil {
+		return err
+	}
+	bootID := ""
+	if machine != nil {
+		if bootID, err = nodes.GetBootIDByMachine(r.client, machine); err != nil {
+			return err
+		}
+	}
+
+	glog.Infof("Remediation %s of machine %q failed, escalate to the %s", failedAttempt.Type, mrCopy.Spec.MachineName, nextStrategy.Type)
+	r.recorder.Eventf(
+		mrCopy,
+		corev1.EventTypeNormal,
+		"MachineRemediationEscalated",
+		"Remediation %s of machine %q failed, escalated to %s",
+		failedAttempt.Type,
+		mrCopy.Spec.MachineName,
+		nextStrategy.Type,
+	)
+
+	now := metav1.Now()
+	strategies.StartAttempt(mrCopy, *nextStrategy, now)
+	mrCopy.Status.State = mrv1.RemediationStateStarted
+	mrCopy.Status.StateTransitionTime = &now
+	mrCopy.Status.EndTime = nil
+	mrCopy.Status.NodeBootID = bootID
+	mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+	mrCopy.Status.Message = fmt.Sprintf("Escalated to %s after the %s failure: %s", nextStrategy.Type, failedAttempt.Type, failedAttempt.Message)
+
+	// conditions of the failed attempt do not describe the progress of the new one
+	conditions.RemoveMachineRemediationCondition(mrCopy, mrv1.MachineRemediationConditionFenced)
+	conditions.RemoveMachineRemediationCondition(mrCopy, mrv1.MachineRemediationConditionPoweredOn)
+	conditions.RemoveMachineRemediationCondition(mrCopy, mrv1.MachineRemediationConditionNodeReady)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionUnknown,
+		mrv1.RemediationReasonInProgress,
+		mrCopy.Status.Message,
+	)
+	return nil
 }
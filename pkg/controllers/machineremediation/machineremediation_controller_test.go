@@ -2,17 +2,26 @@ package machineremediation
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
 	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
 
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -20,6 +29,7 @@ import (
 func init() {
 	// Add types to scheme
 	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
 }
 
 type FakeRemedatior struct {
@@ -34,11 +44,13 @@ func (fr *FakeRemedatior) Reboot(context.Context, *mrv1.MachineRemediation) erro
 }
 
 // newFakeReconciler returns a new reconcile.Reconciler with a fake client
-func newFakeReconciler(initObjects ...runtime.Object) *ReconcileMachineRemediation {
+func newFakeReconciler(recorder record.EventRecorder, initObjects ...runtime.Object) *ReconcileMachineRemediation {
 	fakeClient := fake.NewFakeClient(initObjects...)
 	remediator := &FakeRemedatior{}
 	return &ReconcileMachineRemediation{
 		client:     fakeClient,
+		reader:     fakeClient,
+		recorder:   recorder,
 		remediator: remediator,
 		namespace:  consts.NamespaceOpenshiftMachineAPI,
 	}
@@ -107,6 +119,7 @@ func TestReconcile(t *testing.T) {
 	}
 
 	r := newFakeReconciler(
+		record.NewFakeRecorder(10),
 		machineRemediationStarted,
 		machineRemediationPoweroff,
 		machineRemediationPoweron,
@@ -135,3 +148,691 @@ func TestReconcile(t *testing.T) {
 		}
 	}
 }
+
+type expectedStatus struct {
+	state              mrv1.RemediationState
+	reason             mrv1.RemediationReason
+	observedGeneration int64
+	succeeded          corev1.ConditionStatus
+	attempts           int
+	nodeBootID         string
+}
+
+func TestReconcileStatus(t *testing.T) {
+	machineRemediationNew := mrtesting.NewMachineRemediation("machineRemediationNew", "", mrv1.RemediationTypeReboot, "")
+	machineRemediationNew.Generation = 1
+
+	machineRemediationUpdated := mrtesting.NewMachineRemediation("machineRemediationUpdated", "", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	machineRemediationUpdated.Generation = 3
+	machineRemediationUpdated.Status.ObservedGeneration = 2
+	machineRemediationUpdated.Status.Reason = mrv1.RemediationReasonInProgress
+
+	machine := mrtesting.NewMachine("machine", "node", "")
+	node := mrtesting.NewNode("node", false, "machine")
+	node.Status.NodeInfo.BootID = "bootBeforeRemediation"
+	machineRemediationWithNode := mrtesting.NewMachineRemediation("machineRemediationWithNode", machine.Name, mrv1.RemediationTypeReboot, "")
+	machineRemediationWithNode.Generation = 1
+
+	testsCases := []struct {
+		machineRemediation *mrv1.MachineRemediation
+		expected           expectedStatus
+	}{
+		{
+			machineRemediation: machineRemediationNew,
+			expected: expectedStatus{
+				state:              mrv1.RemediationStateStarted,
+				reason:             mrv1.RemediationReasonInProgress,
+				observedGeneration: 1,
+				succeeded:          corev1.ConditionUnknown,
+				attempts:           1,
+			},
+		},
+		{
+			machineRemediation: machineRemediationWithNode,
+			expected: expectedStatus{
+				state:              mrv1.RemediationStateStarted,
+				reason:             mrv1.RemediationReasonInProgress,
+				observedGeneration: 1,
+				succeeded:          corev1.ConditionUnknown,
+				attempts:           1,
+				nodeBootID:         "bootBeforeRemediation",
+			},
+		},
+		{
+			machineRemediation: machineRemediationUpdated,
+			expected: expectedStatus{
+				state:              mrv1.RemediationStatePowerOff,
+				reason:             mrv1.RemediationReasonInProgress,
+				observedGeneration: 3,
+			},
+		},
+	}
+
+	r := newFakeReconciler(record.NewFakeRecorder(10), machineRemediationNew, machineRemediationUpdated, machineRemediationWithNode, machine, node)
+
+	for _, tc := range testsCases {
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.machineRemediation.Name, err)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.machineRemediation.Name, err)
+		}
+
+		if mr.Status.State != tc.expected.state {
+			t.Errorf("Test case: %s. Expected state: %q, got: %q", tc.machineRemediation.Name, tc.expected.state, mr.Status.State)
+		}
+
+		if mr.Status.Reason != tc.expected.reason {
+			t.Errorf("Test case: %s. Expected reason: %q, got: %q", tc.machineRemediation.Name, tc.expected.reason, mr.Status.Reason)
+		}
+
+		if mr.Status.ObservedGeneration != tc.expected.observedGeneration {
+			t.Errorf("Test case: %s. Expected observed generation: %d, got: %d", tc.machineRemediation.Name, tc.expected.observedGeneration, mr.Status.ObservedGeneration)
+		}
+
+		var succeeded corev1.ConditionStatus
+		if cond := conditions.GetMachineRemediationCondition(mr, mrv1.MachineRemediationConditionSucceeded); cond != nil {
+			succeeded = cond.Status
+		}
+		if succeeded != tc.expected.succeeded {
+			t.Errorf("Test case: %s. Expected succeeded condition status: %q, got: %q", tc.machineRemediation.Name, tc.expected.succeeded, succeeded)
+		}
+
+		if len(mr.Status.Attempts) != tc.expected.attempts {
+			t.Errorf("Test case: %s. Expected %d attempts, got: %d", tc.machineRemediation.Name, tc.expected.attempts, len(mr.Status.Attempts))
+		}
+
+		if mr.Status.NodeBootID != tc.expected.nodeBootID {
+			t.Errorf("Test case: %s. Expected node boot ID: %q, got: %q", tc.machineRemediation.Name, tc.expected.nodeBootID, mr.Status.NodeBootID)
+		}
+	}
+}
+
+// newMachineRemediationWithStrategies returns the machine remediation with reboot and recreate strategies,
+// that finished the attempt with the strategy under the index
+func newMachineRemediationWithStrategies(name string, index int, state mrv1.RemediationState) *mrv1.MachineRemediation {
+	mr := mrtesting.NewMachineRemediation(name, "machine", "", state)
+	mr.Spec.Strategies = []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+	for i := 0; i <= index; i++ {
+		mr.Status.Attempts = append(mr.Status.Attempts, mrv1.RemediationAttempt{
+			Type:      mr.Spec.Strategies[i].Type,
+			StartTime: mr.Status.StartTime,
+		})
+	}
+	conditions.SetMachineRemediationCondition(mr, mrv1.MachineRemediationConditionFenced, corev1.ConditionTrue, mrv1.RemediationReasonInProgress, "")
+	return mr
+}
+
+func TestReconcileEscalation(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	node.Status.NodeInfo.BootID = "bootAfterFirstAttempt"
+	machine := mrtesting.NewMachine("machine", node.Name, "bareMetalHost")
+
+	// the first reboot power cycled the host, but the node did not become ready in time
+	mrRebootFailed := newMachineRemediationWithStrategies("machineRemediationRebootFailed", 0, mrv1.RemediationStateFailed)
+	mrRebootFailed.Spec.Strategies[1].Type = mrv1.RemediationTypeReboot
+	mrRebootFailed.Status.NodeBootID = "bootBeforeFirstAttempt"
+
+	testsCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		objects            []runtime.Object
+		expectedState      mrv1.RemediationState
+		expectedAttempts   []mrv1.RemediationState
+		expectedFenced     bool
+		expectedNodeBootID string
+		expectedEvents     []string
+	}{
+		{
+			name:               "with failed first reboot and rebooted node",
+			machineRemediation: mrRebootFailed,
+			objects:            []runtime.Object{machine, node},
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedAttempts:   []mrv1.RemediationState{mrv1.RemediationStateFailed, ""},
+			expectedFenced:     false,
+			expectedNodeBootID: "bootAfterFirstAttempt",
+			expectedEvents:     []string{"MachineRemediationEscalated"},
+		},
+		{
+			name:               "with failed first strategy",
+			machineRemediation: newMachineRemediationWithStrategies("machineRemediationFirstFailed", 0, mrv1.RemediationStateFailed),
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedAttempts:   []mrv1.RemediationState{mrv1.RemediationStateFailed, ""},
+			expectedFenced:     false,
+			expectedEvents:     []string{"MachineRemediationEscalated"},
+		},
+		{
+			name:               "with succeeded first strategy",
+			machineRemediation: newMachineRemediationWithStrategies("machineRemediationFirstSucceeded", 0, mrv1.RemediationStateSucceeded),
+			expectedState:      mrv1.RemediationStateSucceeded,
+			expectedAttempts:   []mrv1.RemediationState{mrv1.RemediationStateSucceeded},
+			expectedFenced:     true,
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with failed last strategy",
+			machineRemediation: newMachineRemediationWithStrategies("machineRemediationLastFailed", 1, mrv1.RemediationStateFailed),
+			expectedState:      mrv1.RemediationStateFailed,
+			expectedAttempts:   []mrv1.RemediationState{"", mrv1.RemediationStateFailed},
+			expectedFenced:     true,
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with first strategy in progress",
+			machineRemediation: newMachineRemediationWithStrategies("machineRemediationFirstInProgress", 0, mrv1.RemediationStatePowerOff),
+			expectedState:      mrv1.RemediationStatePowerOff,
+			expectedAttempts:   []mrv1.RemediationState{""},
+			expectedFenced:     true,
+			expectedEvents:     []string{},
+		},
+	}
+
+	for _, tc := range testsCases {
+		recorder := record.NewFakeRecorder(10)
+		r := newFakeReconciler(recorder, append(tc.objects, tc.machineRemediation)...)
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if mr.Status.State != tc.expectedState {
+			t.Errorf("Test case: %s. Expected state: %q, got: %q", tc.name, tc.expectedState, mr.Status.State)
+		}
+
+		var attempts []mrv1.RemediationState
+		for _, attempt := range mr.Status.Attempts {
+			attempts = append(attempts, attempt.State)
+		}
+		if !reflect.DeepEqual(attempts, tc.expectedAttempts) {
+			t.Errorf("Test case: %s. Expected attempts states: %v, got: %v", tc.name, tc.expectedAttempts, attempts)
+		}
+
+		fenced := conditions.MachineRemediationHasCondition(mr, mrv1.MachineRemediationConditionFenced, corev1.ConditionTrue)
+		if fenced != tc.expectedFenced {
+			t.Errorf("Test case: %s. Expected fenced condition: %t, got: %t", tc.name, tc.expectedFenced, fenced)
+		}
+
+		if mr.Status.NodeBootID != tc.expectedNodeBootID {
+			t.Errorf("Test case: %s. Expected node boot ID: %q, got: %q", tc.name, tc.expectedNodeBootID, mr.Status.NodeBootID)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+	}
+}
+
+func TestReconcileSucceeded(t *testing.T) {
+	newSucceededRemediation := func(name string, endedBefore time.Duration) *mrv1.MachineRemediation {
+		mr := mrtesting.NewMachineRemediation(name, "", mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+		mr.Status.EndTime = &metav1.Time{Time: time.Now().Add(-endedBefore)}
+		return mr
+	}
+
+	// the machine remediation created by the upstream MachineHealthCheck for the machine
+	mrOwned := newSucceededRemediation("machineRemediationOwned", 2*succeededRemediationRetention)
+	mrOwned.OwnerReferences = []metav1.OwnerReference{
+		{
+			APIVersion: machineapi.ClusterAPIMachineGVK.GroupVersion().String(),
+			Kind:       machineapi.ClusterAPIMachineGVK.Kind,
+			Name:       "machine",
+			UID:        "1",
+		},
+	}
+
+	testsCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		expectedRequeue    bool
+		expectedDeleted    bool
+	}{
+		{
+			name:               "with recently succeeded remediation",
+			machineRemediation: newSucceededRemediation("machineRemediationRecent", time.Hour),
+			expectedRequeue:    true,
+			expectedDeleted:    false,
+		},
+		{
+			name:               "with succeeded remediation after the retention",
+			machineRemediation: newSucceededRemediation("machineRemediationExpired", 2*succeededRemediationRetention),
+			expectedRequeue:    false,
+			expectedDeleted:    true,
+		},
+		{
+			name:               "with succeeded remediation of the machine owner after the retention",
+			machineRemediation: mrOwned,
+			expectedRequeue:    false,
+			expectedDeleted:    false,
+		},
+	}
+
+	for _, tc := range testsCases {
+		r := newFakeReconciler(record.NewFakeRecorder(10), tc.machineRemediation)
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if result.Requeue != tc.expectedRequeue || result.RequeueAfter > succeededRemediationRetention {
+			t.Errorf("Test case: %s. Expected requeue: %t, got: %v", tc.name, tc.expectedRequeue, result)
+		}
+
+		err = r.client.Get(context.TODO(), key, &mrv1.MachineRemediation{})
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if errors.IsNotFound(err) != tc.expectedDeleted {
+			t.Errorf("Test case: %s. Expected machine remediation deleted: %t, got: %v", tc.name, tc.expectedDeleted, err)
+		}
+	}
+}
+
+func TestReconcilePolicy(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	activeMachine := mrtesting.NewMachine("activeMachine", "activeNode", "")
+	otherMachine := mrtesting.NewMachine("otherMachine", "otherNode", "")
+	otherMachine.Labels = map[string]string{"pool": "other"}
+
+	mrActive := mrtesting.NewMachineRemediation("mrActive", "activeMachine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	rebootOnly := []mrv1.RemediationStrategy{{Type: mrv1.RemediationTypeReboot}}
+	maxOne := intstr.FromInt(1)
+	maxAll := intstr.FromString("100%")
+
+	testsCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		maxConcurrent      *intstr.IntOrString
+		expectedState      mrv1.RemediationState
+		expectedReason     mrv1.RemediationReason
+		expectedResult     reconcile.Result
+		expectedEvents     []string
+	}{
+		{
+			name:               "with allowed remediation type",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with remediation type not allowed by the policy",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeRecreate, ""),
+			expectedState:      mrv1.RemediationStateFailed,
+			expectedReason:     mrv1.RemediationReasonNotAllowedByPolicy,
+			expectedResult:     reconcile.Result{},
+			expectedEvents:     []string{"MachineRemediationNotAllowed"},
+		},
+		{
+			name:               "with machine not selected by the policy",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "otherMachine", mrv1.RemediationTypeRecreate, ""),
+			maxConcurrent:      &maxOne,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with reached limit of concurrent remediations",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			maxConcurrent:      &maxOne,
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonThrottled,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationThrottled"},
+		},
+		{
+			name:               "with percentage limit of concurrent remediations",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			maxConcurrent:      &maxAll,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+	}
+
+	for _, tc := range testsCases {
+		policy := mrtesting.NewMachineRemediationPolicy("policy", rebootOnly)
+		policy.Spec.MaxConcurrent = tc.maxConcurrent
+
+		recorder := record.NewFakeRecorder(10)
+		r := newFakeReconciler(recorder, machine, activeMachine, otherMachine, mrActive, policy, tc.machineRemediation)
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		if result != tc.expectedResult {
+			t.Errorf("Test case: %s. Expected: %v, got: %v", tc.name, tc.expectedResult, result)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if mr.Status.State != tc.expectedState {
+			t.Errorf("Test case: %s. Expected state: %q, got: %q", tc.name, tc.expectedState, mr.Status.State)
+		}
+
+		if mr.Status.Reason != tc.expectedReason {
+			t.Errorf("Test case: %s. Expected reason: %q, got: %q", tc.name, tc.expectedReason, mr.Status.Reason)
+		}
+
+		if mr.Status.State == mrv1.RemediationStatePending && mr.Status.StateTransitionTime == nil {
+			t.Errorf("Test case: %s. Expected: state transition time of the pending state, got: nil", tc.name)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+	}
+}
+
+func TestReconcileDisruptionBudget(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	node := mrtesting.NewNode("node", true, "machine")
+	otherMachine := mrtesting.NewMachine("otherMachine", "otherNode", "")
+	otherNode := mrtesting.NewNode("otherNode", true, "otherMachine")
+	unhealthyMachine := mrtesting.NewMachine("unhealthyMachine", "unhealthyNode", "")
+	unhealthyNode := mrtesting.NewNode("unhealthyNode", false, "unhealthyMachine")
+
+	minOne := int32(1)
+	minTwo := int32(2)
+
+	mrPending := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePending)
+	mrPending.Status.Reason = mrv1.RemediationReasonDisruptionBudgetExceeded
+
+	testsCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		minAvailable       *int32
+		expectedState      mrv1.RemediationState
+		expectedReason     mrv1.RemediationReason
+		expectedResult     reconcile.Result
+		expectedEvents     []string
+	}{
+		{
+			name:               "with allowed disruption",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			minAvailable:       &minOne,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with exceeded disruption budget",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			minAvailable:       &minTwo,
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonDisruptionBudgetExceeded,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationPending"},
+		},
+		{
+			name:               "with pending remediation and exceeded disruption budget",
+			machineRemediation: mrPending,
+			minAvailable:       &minTwo,
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonDisruptionBudgetExceeded,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with pending remediation and allowed disruption",
+			machineRemediation: mrPending,
+			minAvailable:       &minOne,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with unhealthy machine and exceeded disruption budget",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "unhealthyMachine", mrv1.RemediationTypeReboot, ""),
+			minAvailable:       &minTwo,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{},
+		},
+	}
+
+	for _, tc := range testsCases {
+		mdb := mrtesting.NewMachineDisruptionBudget("mdb", tc.minAvailable, nil)
+
+		recorder := record.NewFakeRecorder(10)
+		r := newFakeReconciler(
+			recorder,
+			machine,
+			node,
+			otherMachine,
+			otherNode,
+			unhealthyMachine,
+			unhealthyNode,
+			mdb,
+			tc.machineRemediation.DeepCopy(),
+		)
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		if result != tc.expectedResult {
+			t.Errorf("Test case: %s. Expected: %v, got: %v", tc.name, tc.expectedResult, result)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if mr.Status.State != tc.expectedState {
+			t.Errorf("Test case: %s. Expected state: %q, got: %q", tc.name, tc.expectedState, mr.Status.State)
+		}
+
+		if mr.Status.Reason != tc.expectedReason {
+			t.Errorf("Test case: %s. Expected reason: %q, got: %q", tc.name, tc.expectedReason, mr.Status.Reason)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+	}
+}
+
+func TestReconcileControlPlane(t *testing.T) {
+	newMasterMachine := func(name string, nodeName string) *mapiv1.Machine {
+		machine := mrtesting.NewMachine(name, nodeName, "")
+		machine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+		return machine
+	}
+
+	mrApproved := mrtesting.NewMachineRemediation("mr", "master1", mrv1.RemediationTypeReboot, "")
+	mrApproved.Annotations = map[string]string{consts.AnnotationQuorumRiskApproved: "true"}
+
+	mrActive := mrtesting.NewMachineRemediation("mrActive", "master3", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	mrRebootFailed := newMachineRemediationWithStrategies("mr", 0, mrv1.RemediationStateFailed)
+	mrRebootFailed.Spec.MachineName = "master1"
+
+	// the escalation after the failed reboot waited for other control plane remediation
+	mrEscalationPending := mrRebootFailed.DeepCopy()
+	mrEscalationPending.Status.State = mrv1.RemediationStatePending
+	mrEscalationPending.Status.Reason = mrv1.RemediationReasonControlPlaneRemediationInProgress
+	mrEscalationPending.Status.Attempts[0].State = mrv1.RemediationStateFailed
+	mrEscalationPending.Status.Attempts[0].EndTime = &metav1.Time{Time: time.Now()}
+
+	testsCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		node2Ready         bool
+		extraObjects       []runtime.Object
+		expectedState      mrv1.RemediationState
+		expectedReason     mrv1.RemediationReason
+		expectedResult     reconcile.Result
+		expectedEvents     []string
+	}{
+		{
+			name:               "with healthy quorum",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "master1", mrv1.RemediationTypeReboot, ""),
+			node2Ready:         true,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumChecked"},
+		},
+		{
+			name:               "with quorum at risk",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "master1", mrv1.RemediationTypeReboot, ""),
+			node2Ready:         false,
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonQuorumAtRisk,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumAtRisk"},
+		},
+		{
+			name:               "with quorum at risk and approved remediation",
+			machineRemediation: mrApproved,
+			node2Ready:         false,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumRiskApproved"},
+		},
+		{
+			name:               "with unhealthy member",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "master2", mrv1.RemediationTypeReboot, ""),
+			node2Ready:         false,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumChecked"},
+		},
+		{
+			name:               "with other control plane remediation in progress",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "master1", mrv1.RemediationTypeReboot, ""),
+			node2Ready:         true,
+			extraObjects:       []runtime.Object{mrActive},
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonControlPlaneRemediationInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationPending"},
+		},
+		{
+			name:               "with failed reboot and healthy quorum",
+			machineRemediation: mrRebootFailed,
+			node2Ready:         true,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumChecked", "MachineRemediationEscalated"},
+		},
+		{
+			name:               "with failed reboot and quorum at risk",
+			machineRemediation: mrRebootFailed,
+			node2Ready:         false,
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonQuorumAtRisk,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumAtRisk"},
+		},
+		{
+			name:               "with failed reboot and other control plane remediation in progress",
+			machineRemediation: mrRebootFailed,
+			node2Ready:         true,
+			extraObjects:       []runtime.Object{mrActive},
+			expectedState:      mrv1.RemediationStatePending,
+			expectedReason:     mrv1.RemediationReasonControlPlaneRemediationInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 30 * time.Second},
+			expectedEvents:     []string{"MachineRemediationPending"},
+		},
+		{
+			name:               "with pending escalation and finished control plane remediation",
+			machineRemediation: mrEscalationPending,
+			node2Ready:         true,
+			expectedState:      mrv1.RemediationStateStarted,
+			expectedReason:     mrv1.RemediationReasonInProgress,
+			expectedResult:     reconcile.Result{Requeue: true, RequeueAfter: 10 * time.Second},
+			expectedEvents:     []string{"MachineRemediationQuorumChecked", "MachineRemediationEscalated"},
+		},
+	}
+
+	for _, tc := range testsCases {
+		objects := []runtime.Object{
+			newMasterMachine("master1", "node1"),
+			mrtesting.NewNode("node1", true, "master1"),
+			newMasterMachine("master2", "node2"),
+			mrtesting.NewNode("node2", tc.node2Ready, "master2"),
+			newMasterMachine("master3", "node3"),
+			mrtesting.NewNode("node3", true, "master3"),
+			tc.machineRemediation.DeepCopy(),
+		}
+		objects = append(objects, tc.extraObjects...)
+
+		recorder := record.NewFakeRecorder(10)
+		r := newFakeReconciler(recorder, objects...)
+		key := types.NamespacedName{
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+			Name:      tc.machineRemediation.Name,
+		}
+		result, err := r.Reconcile(reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		if result != tc.expectedResult {
+			t.Errorf("Test case: %s. Expected: %v, got: %v", tc.name, tc.expectedResult, result)
+		}
+
+		mr := &mrv1.MachineRemediation{}
+		if err := r.client.Get(context.TODO(), key, mr); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if mr.Status.State != tc.expectedState {
+			t.Errorf("Test case: %s. Expected state: %q, got: %q", tc.name, tc.expectedState, mr.Status.State)
+		}
+
+		if mr.Status.Reason != tc.expectedReason {
+			t.Errorf("Test case: %s. Expected reason: %q, got: %q", tc.name, tc.expectedReason, mr.Status.Reason)
+		}
+
+		// the escalation keeps the failed attempt and starts the next strategy only once checks pass
+		if len(tc.machineRemediation.Spec.Strategies) != 0 {
+			expectedAttempts := 1
+			if mr.Status.State == mrv1.RemediationStateStarted {
+				expectedAttempts = 2
+			}
+			if len(mr.Status.Attempts) != expectedAttempts || mr.Status.Attempts[0].State != mrv1.RemediationStateFailed {
+				t.Errorf("Test case: %s. Expected: %d attempts after the failed reboot, got: %v", tc.name, expectedAttempts, mr.Status.Attempts)
+			}
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+	}
+}
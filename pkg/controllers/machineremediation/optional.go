@@ -0,0 +1,213 @@
+package machineremediation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var (
+	_ Remediator = &OptionalRemediator{}
+	_ Canceler   = &OptionalRemediator{}
+	_ Watcher    = &OptionalRemediator{}
+
+	_ manager.Runnable = &OptionalRemediator{}
+)
+
+// platformPollInterval contains the interval of checks whether the cluster serves the API of the infrastructure platform
+const platformPollInterval = 30 * time.Second
+
+// OptionalRemediator creates the remediator, that requires the API of the infrastructure platform, only once
+// the cluster serves the API, until then started machine remediations wait with the unsupported platform reason
+type OptionalRemediator struct {
+	mgr      manager.Manager
+	client   client.Client
+	recorder record.EventRecorder
+	// mapper should be the dynamic REST mapper of the manager, that discovers kinds that appear after the start
+	mapper  meta.RESTMapper
+	name    string
+	factory RemediatorFactory
+	gvk     schema.GroupVersionKind
+
+	lock       sync.Mutex
+	remediator Remediator
+	controller controller.Controller
+}
+
+// NewOptionalFactory returns the factory of the optional remediator, that creates the remediator with the factory
+// once the cluster serves the kind, the manager runs the optional remediator to check the kind without machine remediations
+func NewOptionalFactory(name string, factory RemediatorFactory, gvk schema.GroupVersionKind) RemediatorFactory {
+	return func(mgr manager.Manager) (Remediator, error) {
+		or := &OptionalRemediator{
+			mgr:      mgr,
+			client:   mgr.GetClient(),
+			recorder: mgr.GetEventRecorderFor("machineremediation-controller"),
+			mapper:   mgr.GetRESTMapper(),
+			name:     name,
+			factory:  factory,
+			gvk:      gvk,
+		}
+		if err := mgr.Add(or); err != nil {
+			return nil, err
+		}
+		return or, nil
+	}
+}
+
+// Start checks periodically whether the cluster serves the kind, until it creates the remediator and adds its watches
+func (or *OptionalRemediator) Start(stop <-chan struct{}) error {
+	err := wait.PollImmediateUntil(platformPollInterval, func() (bool, error) {
+		remediator, err := or.getRemediator()
+		if err != nil {
+			glog.Errorf("Failed to start remediator %q: %v", or.name, err)
+			return false, nil
+		}
+		return remediator != nil, nil
+	}, stop)
+	if err == wait.ErrWaitTimeout {
+		return nil
+	}
+	return err
+}
+
+// Reboot reboots the machine with the remediator, or the machine remediation waits when the API is not served
+func (or *OptionalRemediator) Reboot(ctx context.Context, mr *mrv1.MachineRemediation) error {
+	remediator, err := or.getRemediator()
+	if err != nil {
+		return err
+	}
+	if remediator == nil {
+		return or.waitForPlatform(mr)
+	}
+	return remediator.Reboot(ctx, mr)
+}
+
+// Recreate recreates the machine with the remediator, or the machine remediation waits when the API is not served
+func (or *OptionalRemediator) Recreate(ctx context.Context, mr *mrv1.MachineRemediation) error {
+	remediator, err := or.getRemediator()
+	if err != nil {
+		return err
+	}
+	if remediator == nil {
+		return or.waitForPlatform(mr)
+	}
+	return remediator.Recreate(ctx, mr)
+}
+
+// Cancel cancels the remediation with the remediator, when it was created and can cancel remediations
+func (or *OptionalRemediator) Cancel(ctx context.Context, mr *mrv1.MachineRemediation) error {
+	or.lock.Lock()
+	remediator := or.remediator
+	or.lock.Unlock()
+
+	if canceler, ok := remediator.(Canceler); ok {
+		return canceler.Cancel(ctx, mr)
+	}
+	return nil
+}
+
+// Watch keeps the machine remediation controller, to add watches of the remediator once it is created
+func (or *OptionalRemediator) Watch(c controller.Controller) error {
+	or.lock.Lock()
+	defer or.lock.Unlock()
+
+	or.controller = c
+	if watcher, ok := or.remediator.(Watcher); ok {
+		return watcher.Watch(c)
+	}
+	return nil
+}
+
+// NeedsPolling returns true, unless the created remediator watches changes that the remediation waits for
+func (or *OptionalRemediator) NeedsPolling(mr *mrv1.MachineRemediation) bool {
+	or.lock.Lock()
+	remediator := or.remediator
+	or.lock.Unlock()
+
+	if watcher, ok := remediator.(Watcher); ok {
+		return watcher.NeedsPolling(mr)
+	}
+	return true
+}
+
+// getRemediator returns the remediator, it creates the remediator and adds its watches when the cluster
+// serves the kind, or returns nil when it does not
+func (or *OptionalRemediator) getRemediator() (Remediator, error) {
+	or.lock.Lock()
+	defer or.lock.Unlock()
+
+	if or.remediator != nil {
+		return or.remediator, nil
+	}
+
+	// the dynamic REST mapper reloads the discovery, when it does not know the kind
+	if _, err := or.mapper.RESTMapping(or.gvk.GroupKind(), or.gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	glog.Infof("The cluster serves %s under %s, starting remediator %q", or.gvk.Kind, or.gvk.GroupVersion(), or.name)
+	remediator, err := or.factory(or.mgr)
+	if err != nil {
+		return nil, err
+	}
+
+	if watcher, ok := remediator.(Watcher); ok && or.controller != nil {
+		if err := watcher.Watch(or.controller); err != nil {
+			return nil, err
+		}
+	}
+	or.remediator = remediator
+	return remediator, nil
+}
+
+// waitForPlatform reports the unsupported platform reason of the started machine remediation.
+// The machine remediation stays started, so the remediator continues it once the cluster serves the API.
+func (or *OptionalRemediator) waitForPlatform(mr *mrv1.MachineRemediation) error {
+	if mr.Status.State != mrv1.RemediationStateStarted || mr.Status.Reason == mrv1.RemediationReasonUnsupportedPlatform {
+		return nil
+	}
+
+	glog.Warningf("Remediation of machine %q waits, the cluster does not serve %s under %s", mr.Spec.MachineName, or.gvk.Kind, or.gvk.GroupVersion())
+	or.recorder.Eventf(
+		mr,
+		corev1.EventTypeWarning,
+		"MachineRemediationUnsupportedPlatform",
+		"Remediation of machine %q waits, remediator %q requires %s under %s, that the cluster does not serve",
+		mr.Spec.MachineName,
+		or.name,
+		or.gvk.Kind,
+		or.gvk.GroupVersion(),
+	)
+
+	// Copy the machine remediation object to prevent modification of the original one
+	mrCopy := mr.DeepCopy()
+	mrCopy.Status.Reason = mrv1.RemediationReasonUnsupportedPlatform
+	mrCopy.Status.Message = fmt.Sprintf("Remediator %q waits for %s under %s, that the cluster does not serve", or.name, or.gvk.Kind, or.gvk.GroupVersion())
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionUnknown,
+		mrv1.RemediationReasonUnsupportedPlatform,
+		mrCopy.Status.Message,
+	)
+	return or.client.Status().Update(context.TODO(), mrCopy)
+}
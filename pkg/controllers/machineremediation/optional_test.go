@@ -0,0 +1,150 @@
+package machineremediation
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var bareMetalHostGVK = schema.GroupVersionKind{Group: "metal3.io", Version: "v1alpha1", Kind: "BareMetalHost"}
+
+// watchingFakeRemediator counts reboots and keeps the controller that it watches with
+type watchingFakeRemediator struct {
+	FakeRemedatior
+	reboots    int
+	controller controller.Controller
+}
+
+func (w *watchingFakeRemediator) Reboot(context.Context, *mrv1.MachineRemediation) error {
+	w.reboots++
+	return nil
+}
+
+func (w *watchingFakeRemediator) Watch(c controller.Controller) error {
+	w.controller = c
+	return nil
+}
+
+func (w *watchingFakeRemediator) NeedsPolling(*mrv1.MachineRemediation) bool {
+	return false
+}
+
+// fakeController is the machine remediation controller passed to watchers
+type fakeController struct {
+	controller.Controller
+}
+
+func TestOptionalRemediator(t *testing.T) {
+	mr := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
+	mrFailed := mrtesting.NewMachineRemediation("mrFailed", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStateFailed)
+
+	recorder := record.NewFakeRecorder(10)
+	fakeClient := fake.NewFakeClient(mr, mrFailed)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	remediator := &watchingFakeRemediator{}
+	created := 0
+	or := &OptionalRemediator{
+		client:   fakeClient,
+		recorder: recorder,
+		mapper:   mapper,
+		name:     "baremetal",
+		factory: func(mgr manager.Manager) (Remediator, error) {
+			created++
+			return remediator, nil
+		},
+		gvk: bareMetalHostGVK,
+	}
+
+	c := &fakeController{}
+	if err := or.Watch(c); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+
+	// the cluster does not serve bare metal hosts yet
+	for _, machineRemediation := range []*mrv1.MachineRemediation{mr, mrFailed} {
+		if err := or.Reboot(context.TODO(), machineRemediation); err != nil {
+			t.Fatalf("Expected: no error, got: %v", err)
+		}
+	}
+	if created != 0 {
+		t.Errorf("Expected: the remediator is not created, got: %d remediators", created)
+	}
+
+	updated := &mrv1.MachineRemediation{}
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name}, updated); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if updated.Status.State != mrv1.RemediationStateStarted || updated.Status.Reason != mrv1.RemediationReasonUnsupportedPlatform {
+		t.Errorf("Expected: started state with the unsupported platform reason, got: %s, %s", updated.Status.State, updated.Status.Reason)
+	}
+	if updated.Status.EndTime != nil {
+		t.Errorf("Expected: the waiting remediation does not end, got the end time: %v", updated.Status.EndTime)
+	}
+
+	// the waiting machine remediation does not report the unsupported platform again
+	if err := or.Reboot(context.TODO(), updated); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	// the machine remediation that already ended does not wait
+	mrtesting.AssertEvents(t, "with missing bare metal hosts", []string{"MachineRemediationUnsupportedPlatform"}, recorder.Events)
+
+	// the cluster serves bare metal hosts, the waiting remediation continues
+	mapper.Add(bareMetalHostGVK, meta.RESTScopeNamespace)
+	for i := 0; i < 2; i++ {
+		if err := or.Reboot(context.TODO(), updated); err != nil {
+			t.Fatalf("Expected: no error, got: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("Expected: the remediator is created once, got: %d remediators", created)
+	}
+	if remediator.reboots != 2 {
+		t.Errorf("Expected: 2 reboots by the remediator, got: %d", remediator.reboots)
+	}
+	if remediator.controller != c {
+		t.Errorf("Expected: the remediator watches with the machine remediation controller")
+	}
+}
+
+func TestOptionalRemediatorStart(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(bareMetalHostGVK, meta.RESTScopeNamespace)
+	remediator := &watchingFakeRemediator{}
+	or := &OptionalRemediator{
+		mapper: mapper,
+		name:   "baremetal",
+		factory: func(mgr manager.Manager) (Remediator, error) {
+			return remediator, nil
+		},
+		gvk: bareMetalHostGVK,
+	}
+
+	c := &fakeController{}
+	if err := or.Watch(c); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+
+	// the remediator starts without machine remediations, once the cluster serves bare metal hosts
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := or.Start(stop); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if or.remediator != remediator {
+		t.Errorf("Expected: the remediator is created")
+	}
+	if remediator.controller != c {
+		t.Errorf("Expected: the remediator watches with the machine remediation controller")
+	}
+}
@@ -0,0 +1,118 @@
+package machineremediation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	osconfigv1 "github.com/openshift/api/config/v1"
+
+	"kubevirt.io/machine-remediation/pkg/utils/infrastructure"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// RemediatorFactory creates a new Remediator for the manager
+type RemediatorFactory func(mgr manager.Manager) (Remediator, error)
+
+// Registry contains all remediators known by the controller, keyed by the remediator name
+// and by the infrastructure platform that the remediator supports
+type Registry struct {
+	factories map[string]RemediatorFactory
+	platforms map[osconfigv1.PlatformType]string
+}
+
+// NewRegistry returns new empty Registry object
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: map[string]RemediatorFactory{},
+		platforms: map[osconfigv1.PlatformType]string{},
+	}
+}
+
+// Register adds the remediator factory under the name to the registry, the remediator will be selected
+// by default for each one of the specified platforms
+func (r *Registry) Register(name string, factory RemediatorFactory, platforms ...osconfigv1.PlatformType) error {
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("remediator %q already registered", name)
+	}
+
+	for _, platform := range platforms {
+		if registered, ok := r.platforms[platform]; ok {
+			return fmt.Errorf("platform %q already has registered remediator %q", platform, registered)
+		}
+	}
+
+	r.factories[name] = factory
+	for _, platform := range platforms {
+		r.platforms[platform] = name
+	}
+	return nil
+}
+
+// Names returns sorted names of all registered remediators
+func (r *Registry) Names() []string {
+	names := []string{}
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Platforms returns sorted platforms that have registered remediator
+func (r *Registry) Platforms() []string {
+	platforms := []string{}
+	for platform := range r.platforms {
+		platforms = append(platforms, string(platform))
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// GetByName returns the remediator factory registered under the name
+func (r *Registry) GetByName(name string) (RemediatorFactory, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown remediator %q, supported remediators: %s", name, strings.Join(r.Names(), ", "))
+	}
+	return factory, nil
+}
+
+// GetByPlatform returns the name and the factory of the remediator registered for the platform
+func (r *Registry) GetByPlatform(platform osconfigv1.PlatformType) (string, RemediatorFactory, error) {
+	name, ok := r.platforms[platform]
+	if !ok {
+		return "", nil, fmt.Errorf("no remediator registered for platform %q, supported platforms: %s", platform, strings.Join(r.Platforms(), ", "))
+	}
+	return name, r.factories[name], nil
+}
+
+// NewRemediator creates the remediator with the specified name, when the name is empty
+// it detects the cluster infrastructure platform and creates the remediator registered for it.
+// The client should be able to read objects before the manager start, so it should not be the cached one.
+func (r *Registry) NewRemediator(mgr manager.Manager, c client.Client, name string) (Remediator, error) {
+	if name == "" {
+		platform, err := infrastructure.GetPlatformType(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect the infrastructure platform: %v", err)
+		}
+
+		var factory RemediatorFactory
+		name, factory, err = r.GetByPlatform(platform)
+		if err != nil {
+			return nil, err
+		}
+		glog.Infof("Detected platform %q, using remediator %q", platform, name)
+		return factory(mgr)
+	}
+
+	factory, err := r.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	glog.Infof("Using remediator %q", name)
+	return factory(mgr)
+}
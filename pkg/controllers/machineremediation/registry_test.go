@@ -0,0 +1,124 @@
+package machineremediation
+
+import (
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func init() {
+	// Add types to scheme
+	osconfigv1.AddToScheme(scheme.Scheme)
+}
+
+type namedFakeRemediator struct {
+	FakeRemedatior
+	name string
+}
+
+func newNamedFakeRemediatorFactory(name string) RemediatorFactory {
+	return func(mgr manager.Manager) (Remediator, error) {
+		return &namedFakeRemediator{name: name}, nil
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register("baremetal", newNamedFakeRemediatorFactory("baremetal"), osconfigv1.BareMetalPlatformType); err != nil {
+		t.Fatalf("Failed to register remediator: %v", err)
+	}
+
+	if err := registry.Register("baremetal", newNamedFakeRemediatorFactory("baremetal")); err == nil {
+		t.Errorf("Expected error on the registration of the remediator with the same name")
+	}
+
+	if err := registry.Register("other", newNamedFakeRemediatorFactory("other"), osconfigv1.BareMetalPlatformType); err == nil {
+		t.Errorf("Expected error on the registration of the remediator for the platform that already has remediator")
+	}
+
+	if names := registry.Names(); len(names) != 1 || names[0] != "baremetal" {
+		t.Errorf("Expected registered remediators [baremetal], got: %v", names)
+	}
+}
+
+func TestRegistryNewRemediator(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("baremetal", newNamedFakeRemediatorFactory("baremetal"), osconfigv1.BareMetalPlatformType)
+	registry.Register("other", newNamedFakeRemediatorFactory("other"), osconfigv1.OpenStackPlatformType)
+
+	infraBareMetal := mrtesting.NewInfrastructure(consts.InfrastructureName, osconfigv1.BareMetalPlatformType)
+	infraAWS := mrtesting.NewInfrastructure(consts.InfrastructureName, osconfigv1.AWSPlatformType)
+
+	testsCases := []struct {
+		name               string
+		infrastructure     runtime.Object
+		remediatorName     string
+		expectedRemediator string
+		expectedError      bool
+	}{
+		{
+			name:               "with platform that has registered remediator",
+			infrastructure:     infraBareMetal,
+			remediatorName:     "",
+			expectedRemediator: "baremetal",
+			expectedError:      false,
+		},
+		{
+			name:               "with platform that does not have registered remediator",
+			infrastructure:     infraAWS,
+			remediatorName:     "",
+			expectedRemediator: "",
+			expectedError:      true,
+		},
+		{
+			name:               "with remediator override",
+			infrastructure:     infraAWS,
+			remediatorName:     "other",
+			expectedRemediator: "other",
+			expectedError:      false,
+		},
+		{
+			name:               "with unknown remediator override",
+			infrastructure:     infraBareMetal,
+			remediatorName:     "unknown",
+			expectedRemediator: "",
+			expectedError:      true,
+		},
+		{
+			name:               "without cluster infrastructure",
+			infrastructure:     mrtesting.NewInfrastructure("wrongName", osconfigv1.BareMetalPlatformType),
+			remediatorName:     "",
+			expectedRemediator: "",
+			expectedError:      true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		c := fake.NewFakeClient(tc.infrastructure)
+		remediator, err := registry.NewRemediator(nil, c, tc.remediatorName)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.name, tc.expectedError, err)
+		}
+
+		if tc.expectedRemediator == "" {
+			if remediator != nil {
+				t.Errorf("Test case: %s. Expected no remediator, got: %v", tc.name, remediator)
+			}
+			continue
+		}
+
+		named, ok := remediator.(*namedFakeRemediator)
+		if !ok || named.name != tc.expectedRemediator {
+			t.Errorf("Test case: %s. Expected remediator %q, got: %v", tc.name, tc.expectedRemediator, remediator)
+		}
+	}
+}
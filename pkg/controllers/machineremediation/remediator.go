@@ -3,7 +3,9 @@ package machineremediation
 import (
 	"context"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 )
 
 // Remediator apply machine remediation strategy under a specific infrastructure.
@@ -13,3 +15,19 @@ type Remediator interface {
 	// Recreate the machine.
 	Recreate(context.Context, *mrv1.MachineRemediation) error
 }
+
+// Canceler is implemented by remediators that can cancel the remediation in progress.
+type Canceler interface {
+	// Cancel the remediation of the deleted machine remediation object.
+	Cancel(context.Context, *mrv1.MachineRemediation) error
+}
+
+// Watcher is implemented by remediators that watch objects of their infrastructure, the controller reconciles
+// their machine remediations on changes of watched objects and polls them only as the safety net.
+type Watcher interface {
+	// Watch adds watches of infrastructure objects to the machine remediation controller.
+	Watch(controller.Controller) error
+	// NeedsPolling returns true when the remediation under its current state waits for changes
+	// that watches do not report, like the drain of the node or the rejoin of the etcd member.
+	NeedsPolling(*mrv1.MachineRemediation) bool
+}
@@ -0,0 +1,102 @@
+package machineremediation
+
+import (
+	"github.com/golang/glog"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/remediations"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// nodeChangePredicate passes only node events that remediations wait for, the creation and the deletion of the node,
+// the change of the node readiness and the new boot ID, so node status heartbeats do not trigger the reconcile
+var nodeChangePredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return true
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		newNode, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		if oldNode.Status.NodeInfo.BootID != newNode.Status.NodeInfo.BootID {
+			return true
+		}
+		return conditions.NodeHasCondition(oldNode, corev1.NodeReady, corev1.ConditionTrue) !=
+			conditions.NodeHasCondition(newNode, corev1.NodeReady, corev1.ConditionTrue)
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return true
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// machineToRequests maps the machine to reconcile requests of its machine remediations in progress
+func (r *ReconcileMachineRemediation) machineToRequests(o handler.MapObject) []reconcile.Request {
+	mrs, err := remediations.GetActiveMachineRemediations(r.client)
+	if err != nil {
+		glog.Errorf("Failed to list machine remediations: %v", err)
+		return nil
+	}
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	var machine *mapiv1.Machine
+	switch obj := o.Object.(type) {
+	case *mapiv1.Machine:
+		machine = obj
+	case *unstructured.Unstructured:
+		if machine, err = machineapi.ConvertMachine(obj, nil); err != nil {
+			glog.Errorf("Failed to map machine to machine remediations: %v", err)
+			return nil
+		}
+	default:
+		return nil
+	}
+	return remediations.GetRequests(mrs, machine, machine.Annotations[consts.AnnotationBareMetalHost])
+}
+
+// nodeToRequests maps the node to reconcile requests of machine remediations in progress of the node machine
+func (r *ReconcileMachineRemediation) nodeToRequests(o handler.MapObject) []reconcile.Request {
+	node, ok := o.Object.(*corev1.Node)
+	if !ok {
+		return nil
+	}
+
+	// look for the machine of the node only when some remediation is in progress
+	mrs, err := remediations.GetActiveMachineRemediations(r.client)
+	if err != nil {
+		glog.Errorf("Failed to list machine remediations: %v", err)
+		return nil
+	}
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	machine, err := machineutils.GetMachineByNode(r.client, node)
+	if err != nil {
+		if !machineutils.IsMachineNotFound(err) {
+			glog.Errorf("Failed to map node %q to machine remediations: %v", node.Name, err)
+		}
+		return nil
+	}
+	return remediations.GetRequests(mrs, machine, machine.Annotations[consts.AnnotationBareMetalHost])
+}
@@ -0,0 +1,153 @@
+package machineremediation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func getRequestNames(requests []reconcile.Request) []string {
+	names := []string{}
+	for _, request := range requests {
+		names = append(names, request.Name)
+	}
+	return names
+}
+
+func TestMapToRequests(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "host")
+	otherMachine := mrtesting.NewMachine("otherMachine", "otherNode", "otherHost")
+	node := mrtesting.NewNode("node", false, "machine")
+	otherNode := mrtesting.NewNode("otherNode", true, "otherMachine")
+	mrActive := mrtesting.NewMachineRemediation("mrActive", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mrEnded := mrtesting.NewMachineRemediation("mrEnded", "otherMachine", mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+	mrEnded.Status.EndTime = &metav1.Time{Time: time.Now()}
+
+	testCases := []struct {
+		name             string
+		objects          []runtime.Object
+		mapFunc          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc
+		object           runtime.Object
+		expectedRequests []string
+	}{
+		{
+			name:             "with machine under remediation",
+			objects:          []runtime.Object{machine, otherMachine, mrActive, mrEnded},
+			mapFunc:          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc { return r.machineToRequests },
+			object:           machine,
+			expectedRequests: []string{"mrActive"},
+		},
+		{
+			name:             "with machine of ended remediation",
+			objects:          []runtime.Object{machine, otherMachine, mrActive, mrEnded},
+			mapFunc:          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc { return r.machineToRequests },
+			object:           otherMachine,
+			expectedRequests: []string{},
+		},
+		{
+			name:             "with node of machine under remediation",
+			objects:          []runtime.Object{machine, otherMachine, node, otherNode, mrActive, mrEnded},
+			mapFunc:          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc { return r.nodeToRequests },
+			object:           node,
+			expectedRequests: []string{"mrActive"},
+		},
+		{
+			name:             "with node of machine of ended remediation",
+			objects:          []runtime.Object{machine, otherMachine, node, otherNode, mrActive, mrEnded},
+			mapFunc:          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc { return r.nodeToRequests },
+			object:           otherNode,
+			expectedRequests: []string{},
+		},
+		{
+			name:             "with node without machine",
+			objects:          []runtime.Object{node, mrActive},
+			mapFunc:          func(r *ReconcileMachineRemediation) handler.ToRequestsFunc { return r.nodeToRequests },
+			object:           node,
+			expectedRequests: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		r := newFakeReconciler(record.NewFakeRecorder(10), tc.objects...)
+		requests := tc.mapFunc(r)(handler.MapObject{Object: tc.object})
+		if names := getRequestNames(requests); !reflect.DeepEqual(names, tc.expectedRequests) {
+			t.Errorf("Test case: %s. Expected requests: %v, got: %v", tc.name, tc.expectedRequests, names)
+		}
+	}
+}
+
+func TestNodeChangePredicate(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	node.Status.NodeInfo.BootID = "boot"
+
+	nodeHeartbeat := node.DeepCopy()
+	nodeHeartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+
+	nodeNotReady := node.DeepCopy()
+	nodeNotReady.Status.Conditions[0].Status = corev1.ConditionUnknown
+
+	nodeRebooted := node.DeepCopy()
+	nodeRebooted.Status.NodeInfo.BootID = "newBoot"
+
+	testCases := []struct {
+		name     string
+		newNode  *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "with heartbeat",
+			newNode:  nodeHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "with not ready node",
+			newNode:  nodeNotReady,
+			expected: true,
+		},
+		{
+			name:     "with new boot ID",
+			newNode:  nodeRebooted,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		e := event.UpdateEvent{MetaOld: node, ObjectOld: node, MetaNew: tc.newNode, ObjectNew: tc.newNode}
+		if passed := nodeChangePredicate.Update(e); passed != tc.expected {
+			t.Errorf("Test case: %s. Expected: %t, got: %t", tc.name, tc.expected, passed)
+		}
+	}
+
+	if !nodeChangePredicate.Create(event.CreateEvent{Meta: node, Object: node}) {
+		t.Errorf("Expected: the creation of the node passes")
+	}
+	if !nodeChangePredicate.Delete(event.DeleteEvent{Meta: node, Object: node}) {
+		t.Errorf("Expected: the deletion of the node passes")
+	}
+}
+
+func TestGetRequeueAfter(t *testing.T) {
+	mr := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	r := newFakeReconciler(record.NewFakeRecorder(10))
+	if requeueAfter := r.getRequeueAfter(mr); requeueAfter != pollInterval {
+		t.Errorf("Expected: %v for the remediator that does not watch, got: %v", pollInterval, requeueAfter)
+	}
+
+	r.remediator = &watchingFakeRemediator{}
+	if requeueAfter := r.getRequeueAfter(mr); requeueAfter != safetyNetInterval {
+		t.Errorf("Expected: %v for the watching remediator, got: %v", safetyNetInterval, requeueAfter)
+	}
+}
@@ -0,0 +1,138 @@
+package machineremediationpolicy
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var _ reconcile.Reconciler = &ReconcileMachineRemediationPolicy{}
+
+// ReconcileMachineRemediationPolicy reconciles a MachineRemediationPolicy object
+type ReconcileMachineRemediationPolicy struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client    client.Client
+	namespace string
+}
+
+// Add creates a new MachineRemediationPolicy Controller and adds it to the Manager, the controller watches
+// machines of the machine API flavor. The Manager will set fields on the Controller and start it when the Manager is started.
+func Add(mgr manager.Manager, flavor machineapi.Flavor, opts manager.Options) error {
+	r, err := newReconciler(mgr, opts)
+	if err != nil {
+		return err
+	}
+	return add(mgr, r, flavor)
+}
+
+func newReconciler(mgr manager.Manager, opts manager.Options) (*ReconcileMachineRemediationPolicy, error) {
+	return &ReconcileMachineRemediationPolicy{
+		client:    mgr.GetClient(),
+		namespace: opts.Namespace,
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileMachineRemediationPolicy, flavor machineapi.Flavor) error {
+	// Create a new controller
+	c, err := controller.New("machineremediationpolicy-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	if err := c.Watch(&source.Kind{Type: &mrv1.MachineRemediationPolicy{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+
+	// counters of the policy depend on machines and machine remediations under the policy namespace
+	toPolicies := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.namespacePolicies)}
+	if err := c.Watch(&source.Kind{Type: machineapi.NewMachineObject(flavor)}, toPolicies); err != nil {
+		return err
+	}
+	return c.Watch(&source.Kind{Type: &mrv1.MachineRemediation{}}, toPolicies)
+}
+
+// namespacePolicies returns requests for all machine remediation policies under the namespace of the object
+func (r *ReconcileMachineRemediationPolicy) namespacePolicies(o handler.MapObject) []reconcile.Request {
+	policyList := &mrv1.MachineRemediationPolicyList{}
+	if err := r.client.List(context.TODO(), policyList, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		glog.Errorf("Failed to list machine remediation policies: %v", err)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, policy := range policyList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name},
+		})
+	}
+	return requests
+}
+
+// Reconcile updates the number of machines that the MachineRemediationPolicy selects and the number of
+// remediations in progress under the policy status
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+func (r *ReconcileMachineRemediationPolicy) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	glog.V(4).Infof("Reconciling MachineRemediationPolicy triggered by %s/%s\n", request.Namespace, request.Name)
+
+	// Get MachineRemediationPolicy from request
+	policy := &mrv1.MachineRemediationPolicy{}
+	if err := r.client.Get(context.TODO(), request.NamespacedName, policy); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Return and don't requeue
+			return reconcile.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return reconcile.Result{}, err
+	}
+
+	if policy.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	selectedMachines, err := policies.GetSelectedMachines(r.client, policy)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	activeRemediations, err := policies.CountActiveRemediations(r.client, policy.Namespace, selectedMachines)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status := mrv1.MachineRemediationPolicyStatus{
+		ObservedGeneration: policy.Generation,
+		MatchedMachines:    int32(len(selectedMachines)),
+		ActiveRemediations: int32(activeRemediations),
+	}
+	if policy.Status == status {
+		return reconcile.Result{}, nil
+	}
+
+	// Copy the policy object to prevent modification of the original one
+	policyCopy := policy.DeepCopy()
+	policyCopy.Status = status
+	if err := r.client.Status().Update(context.TODO(), policyCopy); err != nil {
+		glog.Errorf("Failed to update machine remediation policy %q status: %v", policy.Name, err)
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
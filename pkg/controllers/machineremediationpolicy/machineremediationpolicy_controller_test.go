@@ -0,0 +1,104 @@
+package machineremediationpolicy
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+// newFakeReconciler returns a new reconcile.Reconciler with a fake client
+func newFakeReconciler(initObjects ...runtime.Object) *ReconcileMachineRemediationPolicy {
+	return &ReconcileMachineRemediationPolicy{
+		client:    fake.NewFakeClient(initObjects...),
+		namespace: consts.NamespaceOpenshiftMachineAPI,
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	activeMachine := mrtesting.NewMachine("activeMachine", "activeNode", "")
+	otherMachine := mrtesting.NewMachine("otherMachine", "otherNode", "")
+	otherMachine.Labels = map[string]string{"pool": "other"}
+
+	mrActive := mrtesting.NewMachineRemediation("mrActive", activeMachine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mrSucceeded := mrtesting.NewMachineRemediation("mrSucceeded", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+	mrOther := mrtesting.NewMachineRemediation("mrOther", otherMachine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+
+	policy := mrtesting.NewMachineRemediationPolicy("policy", nil)
+	policy.Generation = 2
+
+	policyWithEmptySelector := mrtesting.NewMachineRemediationPolicy("policyWithEmptySelector", nil)
+	policyWithEmptySelector.Spec.Selector = *mrtesting.NewSelector(nil)
+
+	testsCases := []struct {
+		name           string
+		policy         *mrv1.MachineRemediationPolicy
+		expectedStatus mrv1.MachineRemediationPolicyStatus
+	}{
+		{
+			name:   "with selected machines and active remediations",
+			policy: policy,
+			expectedStatus: mrv1.MachineRemediationPolicyStatus{
+				ObservedGeneration: 2,
+				MatchedMachines:    2,
+				ActiveRemediations: 1,
+			},
+		},
+		{
+			name:           "with empty selector",
+			policy:         policyWithEmptySelector,
+			expectedStatus: mrv1.MachineRemediationPolicyStatus{},
+		},
+	}
+
+	for _, tc := range testsCases {
+		r := newFakeReconciler(tc.policy, machine, activeMachine, otherMachine, mrActive, mrSucceeded, mrOther)
+		key := types.NamespacedName{
+			Namespace: tc.policy.Namespace,
+			Name:      tc.policy.Name,
+		}
+		if _, err := r.Reconcile(reconcile.Request{NamespacedName: key}); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		updated := &mrv1.MachineRemediationPolicy{}
+		if err := r.client.Get(context.TODO(), key, updated); err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+
+		if updated.Status != tc.expectedStatus {
+			t.Errorf("Test case: %s. Expected status: %+v, got: %+v", tc.name, tc.expectedStatus, updated.Status)
+		}
+	}
+}
+
+func TestNamespacePolicies(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	r := newFakeReconciler(
+		mrtesting.NewMachineRemediationPolicy("policy1", nil),
+		mrtesting.NewMachineRemediationPolicy("policy2", nil),
+	)
+
+	requests := r.namespacePolicies(handler.MapObject{Meta: machine, Object: machine})
+	if len(requests) != 2 {
+		t.Errorf("Expected requests for 2 policies, got: %v", requests)
+	}
+}
@@ -2,12 +2,16 @@ package nodereboot
 
 import (
 	"context"
+	"time"
 
 	"github.com/golang/glog"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
 	machineutils "kubevirt.io/machine-remediation/pkg/utils/machines"
+	nodeutils "kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -15,8 +19,10 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -56,7 +62,30 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
-	return c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{})
+	return c.Watch(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestForObject{}, rebootAnnotationPredicate)
+}
+
+// rebootAnnotationPredicate passes only node events that add or change the reboot annotation,
+// so node status heartbeats do not trigger the reconcile
+var rebootAnnotationPredicate = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		_, ok := e.Meta.GetAnnotations()[consts.AnnotationNodeMachineReboot]
+		return ok
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		newValue, ok := e.MetaNew.GetAnnotations()[consts.AnnotationNodeMachineReboot]
+		if !ok {
+			return false
+		}
+		oldValue, ok := e.MetaOld.GetAnnotations()[consts.AnnotationNodeMachineReboot]
+		return !ok || oldValue != newValue
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
 }
 
 // Reconcile monitors Nodes and creates the MachineRemediation object when the node has reboot annotation.
@@ -90,6 +119,11 @@ func (r *ReconcileNodeReboot) Reconcile(request reconcile.Request) (reconcile.Re
 	// Verify that we do not have machine remediation in progress
 	machine, err := machineutils.GetMachineByNode(r.client, node)
 	if err != nil {
+		if machineutils.IsMachineNotFound(err) {
+			// the freshly joined node can be linked to its machine later
+			glog.Warningf("Postpone reboot of node %s: %v", node.Name, err)
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, nil
+		}
 		return reconcile.Result{}, err
 	}
 
@@ -113,18 +147,26 @@ func (r *ReconcileNodeReboot) Reconcile(request reconcile.Request) (reconcile.Re
 			Type:             mrv1.RemediationTypeReboot,
 			SavedAnnotations: node.Annotations,
 			SavedLabels:      node.Labels,
+			SavedTaints:      nodeutils.GetSavedTaints(node),
 		},
 	}
 
+	// the machine remediation policy of the machine replaces the default reboot with its strategies
+	if err := policies.SetMachineStrategies(r.client, machine, mr); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	if err = r.client.Create(context.TODO(), mr); err != nil {
 		return reconcile.Result{}, err
 	}
 	return reconcile.Result{}, nil
 }
 
-func isRebootInProgress(c client.Client, machineName string) (bool, error) {
+// isRebootInProgress returns true when the machine has the machine remediation that did not end yet,
+// the machine remediations list uses the machine name field index of the manager cache
+func isRebootInProgress(c client.Reader, machineName string) (bool, error) {
 	machineRemediations := &mrv1.MachineRemediationList{}
-	if err := c.List(context.TODO(), machineRemediations); err != nil {
+	if err := c.List(context.TODO(), machineRemediations, client.MatchingField(indexes.MachineRemediationMachineNameField, machineName)); err != nil {
 		return false, err
 	}
 
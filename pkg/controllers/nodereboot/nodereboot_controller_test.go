@@ -2,6 +2,7 @@ package nodereboot
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,13 +13,17 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
 	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -102,3 +107,205 @@ func TestReconcile(t *testing.T) {
 		assert.Equal(t, len(mrList.Items), tc.expectedNumMachineRemediations)
 	}
 }
+
+func TestReconcileWithPolicy(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	node.Annotations[consts.AnnotationNodeMachineReboot] = ""
+	machine := mrtesting.NewMachine("machine", node.Name, "")
+
+	strategies := []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+
+	testsCases := []struct {
+		name               string
+		policy             *mrv1.MachineRemediationPolicy
+		expectedType       mrv1.RemediationType
+		expectedStrategies []mrv1.RemediationStrategy
+	}{
+		{
+			name:               "with policy strategies",
+			policy:             mrtesting.NewMachineRemediationPolicy("policy", strategies),
+			expectedType:       "",
+			expectedStrategies: strategies,
+		},
+		{
+			name:               "with policy without strategies",
+			policy:             mrtesting.NewMachineRemediationPolicy("policy", nil),
+			expectedType:       mrv1.RemediationTypeReboot,
+			expectedStrategies: nil,
+		},
+	}
+
+	for _, tc := range testsCases {
+		r := newFakeReconciler(machine, node, tc.policy)
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: metav1.NamespaceNone,
+				Name:      node.Name,
+			},
+		}
+		_, err := r.Reconcile(request)
+		assert.NoError(t, err, tc.name)
+
+		mrList := &mrv1.MachineRemediationList{}
+		assert.NoError(t, r.client.List(context.TODO(), mrList), tc.name)
+		if !assert.Len(t, mrList.Items, 1, tc.name) {
+			continue
+		}
+
+		assert.Equal(t, tc.expectedType, mrList.Items[0].Spec.Type, tc.name)
+		assert.Equal(t, tc.expectedStrategies, mrList.Items[0].Spec.Strategies, tc.name)
+	}
+}
+
+func TestRebootAnnotationPredicate(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	nodeWithRebootAnnotation := node.DeepCopy()
+	nodeWithRebootAnnotation.Annotations[consts.AnnotationNodeMachineReboot] = ""
+	nodeWithChangedRebootAnnotation := node.DeepCopy()
+	nodeWithChangedRebootAnnotation.Annotations[consts.AnnotationNodeMachineReboot] = "again"
+	nodeHeartbeat := nodeWithRebootAnnotation.DeepCopy()
+	nodeHeartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+
+	testsCases := []struct {
+		name     string
+		old      *corev1.Node
+		new      *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "with added reboot annotation",
+			old:      node,
+			new:      nodeWithRebootAnnotation,
+			expected: true,
+		},
+		{
+			name:     "with changed reboot annotation",
+			old:      nodeWithRebootAnnotation,
+			new:      nodeWithChangedRebootAnnotation,
+			expected: true,
+		},
+		{
+			name:     "with removed reboot annotation",
+			old:      nodeWithRebootAnnotation,
+			new:      node,
+			expected: false,
+		},
+		{
+			name:     "with heartbeat of node with reboot annotation",
+			old:      nodeWithRebootAnnotation,
+			new:      nodeHeartbeat,
+			expected: false,
+		},
+		{
+			name:     "with heartbeat of node without reboot annotation",
+			old:      node,
+			new:      node.DeepCopy(),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testsCases {
+		updated := rebootAnnotationPredicate.Update(event.UpdateEvent{
+			MetaOld:   tc.old,
+			ObjectOld: tc.old,
+			MetaNew:   tc.new,
+			ObjectNew: tc.new,
+		})
+		assert.Equal(t, tc.expected, updated, tc.name)
+	}
+
+	assert.True(t, rebootAnnotationPredicate.Create(event.CreateEvent{Meta: nodeWithRebootAnnotation, Object: nodeWithRebootAnnotation}))
+	assert.False(t, rebootAnnotationPredicate.Create(event.CreateEvent{Meta: node, Object: node}))
+	assert.False(t, rebootAnnotationPredicate.Delete(event.DeleteEvent{Meta: nodeWithRebootAnnotation, Object: nodeWithRebootAnnotation}))
+}
+
+// indexedReader serves machine remediations lists with the machine name field selector from the indexer,
+// like the manager cache with the machine name field index does
+type indexedReader struct {
+	client.Reader
+	indexer cache.Indexer
+}
+
+func newIndexedReader(reader client.Reader, mrs []runtime.Object) (*indexedReader, error) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		indexes.MachineRemediationMachineNameField: func(obj interface{}) ([]string, error) {
+			return indexes.MachineRemediationMachineName(obj.(runtime.Object)), nil
+		},
+	})
+	for _, mr := range mrs {
+		if err := indexer.Add(mr); err != nil {
+			return nil, err
+		}
+	}
+	return &indexedReader{Reader: reader, indexer: indexer}, nil
+}
+
+func (r *indexedReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	mrs, ok := list.(*mrv1.MachineRemediationList)
+	if !ok || listOpts.FieldSelector == nil {
+		return r.Reader.List(ctx, list, opts...)
+	}
+
+	value, ok := listOpts.FieldSelector.RequiresExactMatch(indexes.MachineRemediationMachineNameField)
+	if !ok {
+		return r.Reader.List(ctx, list, opts...)
+	}
+
+	objs, err := r.indexer.ByIndex(indexes.MachineRemediationMachineNameField, value)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		mrs.Items = append(mrs.Items, *obj.(*mrv1.MachineRemediation).DeepCopy())
+	}
+	return nil
+}
+
+// BenchmarkIsRebootInProgress compares the lookup of the machine remediation that scans all machine remediations,
+// like the fake client that ignores field selectors, with the lookup under the machine name field index
+func BenchmarkIsRebootInProgress(b *testing.B) {
+	var mrs []runtime.Object
+	for i := 0; i < 3000; i++ {
+		mr := mrtesting.NewMachineRemediation(fmt.Sprintf("mr-%d", i), fmt.Sprintf("machine-%d", i), mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+		mr.Status.EndTime = &metav1.Time{Time: time.Now()}
+		mrs = append(mrs, mr)
+	}
+
+	fakeClient := fake.NewFakeClient(mrs...)
+	indexed, err := newIndexedReader(fakeClient, mrs)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	readers := []struct {
+		name   string
+		reader client.Reader
+	}{
+		{
+			name:   "without index",
+			reader: fakeClient,
+		},
+		{
+			name:   "with index",
+			reader: indexed,
+		},
+	}
+
+	for _, r := range readers {
+		b.Run(r.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				inProgress, err := isRebootInProgress(r.reader, fmt.Sprintf("machine-%d", i%3000))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if inProgress {
+					b.Fatal("Expected: no reboot in progress")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MemberListPath contains the path of the member list call under the etcd JSON gateway,
+	// the v3beta prefix is served by etcd 3.3 and 3.4
+	MemberListPath = "/v3beta/cluster/member/list"
+	// MemberRemovePath contains the path of the member remove call under the etcd JSON gateway
+	MemberRemovePath = "/v3beta/cluster/member/remove"
+
+	// memberNamePrefix contains the prefix of etcd member names under the OpenShift cluster
+	memberNamePrefix = "etcd-member-"
+
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// MemberID contains the ID of the etcd member, the etcd JSON gateway encodes it as a string
+type MemberID uint64
+
+// MarshalJSON encodes the member ID as a string
+func (id MemberID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(id), 10))
+}
+
+// UnmarshalJSON decodes the member ID from a string or a number
+func (id *MemberID) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseUint(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse etcd member ID %s: %v", string(data), err)
+	}
+	*id = MemberID(value)
+	return nil
+}
+
+// String returns the member ID in the hexadecimal format, that etcdctl uses
+func (id MemberID) String() string {
+	return strconv.FormatUint(uint64(id), 16)
+}
+
+// Member contains the etcd member
+type Member struct {
+	ID         MemberID `json:"ID"`
+	Name       string   `json:"name,omitempty"`
+	PeerURLs   []string `json:"peerURLs,omitempty"`
+	ClientURLs []string `json:"clientURLs,omitempty"`
+}
+
+// IsStarted returns true when the member joined the cluster and serves clients,
+// the added member that did not start yet does not have the name and client URLs
+func (m *Member) IsStarted() bool {
+	return m.Name != "" && len(m.ClientURLs) != 0
+}
+
+// MemberListResponse contains the response of the member list call
+type MemberListResponse struct {
+	Members []Member `json:"members"`
+}
+
+// MemberRemoveRequest contains the body of the member remove call
+type MemberRemoveRequest struct {
+	ID MemberID `json:"ID"`
+}
+
+// Client is the client to the etcd cluster membership API
+type Client struct {
+	endpoints  []*url.URL
+	httpClient *http.Client
+}
+
+// NewClient returns new Client for etcd endpoints, the client sends each call to the first endpoint that responds,
+// because the endpoint of the remediated member can be down
+func NewClient(endpoints []string, tlsConfig *tls.Config) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("the etcd client requires at least one endpoint")
+	}
+
+	var parsed []*url.URL
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse etcd endpoint %q: %v", endpoint, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported etcd endpoint scheme %q", u.Scheme)
+		}
+		parsed = append(parsed, u)
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	return &Client{
+		endpoints: parsed,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   defaultRequestTimeout,
+		},
+	}, nil
+}
+
+// NewTLSConfig returns the TLS configuration with the CA bundle and the client certificate,
+// that etcd requires from its clients
+func NewTLSConfig(caFile string, certFile string, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("the CA bundle %q does not have certificates", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// MemberList returns members of the etcd cluster
+func (c *Client) MemberList(ctx context.Context) ([]Member, error) {
+	resp := &MemberListResponse{}
+	if err := c.do(ctx, MemberListPath, struct{}{}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Members, nil
+}
+
+// MemberRemove removes the member from the etcd cluster
+func (c *Client) MemberRemove(ctx context.Context, id MemberID) error {
+	return c.do(ctx, MemberRemovePath, &MemberRemoveRequest{ID: id}, nil)
+}
+
+// do sends the request to endpoints one by one, until one of them responds, and decodes the response into the out object
+func (c *Client) do(ctx context.Context, path string, in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, endpoint := range c.endpoints {
+		err := c.doEndpoint(ctx, endpoint, path, data, out)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return fmt.Errorf("all etcd endpoints failed: %s", strings.Join(errs, "; "))
+}
+
+func (c *Client) doEndpoint(ctx context.Context, endpoint *url.URL, path string, in []byte, out interface{}) error {
+	u := *endpoint
+	u.Path = path
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(in))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("POST %s%s failed with status %d: %s", endpoint.Host, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// FindMember returns the member that runs on the node with one of names or addresses, or nil when no member matches,
+// the member matches by its name, that can have the etcd-member- prefix, or by the host of its peer URLs
+func FindMember(members []Member, nodeNames []string, addresses []string) *Member {
+	names := map[string]bool{}
+	for _, name := range nodeNames {
+		if name == "" {
+			continue
+		}
+		names[name] = true
+		names[memberNamePrefix+name] = true
+	}
+
+	hosts := map[string]bool{}
+	for _, address := range addresses {
+		if address != "" {
+			hosts[address] = true
+		}
+	}
+
+	for i := range members {
+		if names[members[i].Name] {
+			return &members[i]
+		}
+
+		for _, peerURL := range members[i].PeerURLs {
+			u, err := url.Parse(peerURL)
+			if err != nil {
+				continue
+			}
+			host, _, err := net.SplitHostPort(u.Host)
+			if err != nil {
+				host = u.Host
+			}
+			if hosts[host] {
+				return &members[i]
+			}
+		}
+	}
+	return nil
+}
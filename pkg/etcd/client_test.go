@@ -0,0 +1,147 @@
+package etcd_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/etcd/simulator"
+)
+
+func TestMemberID(t *testing.T) {
+	testsCases := []struct {
+		data          string
+		expected      etcd.MemberID
+		expectedError bool
+	}{
+		{
+			data:     `"10276657743932975437"`,
+			expected: etcd.MemberID(10276657743932975437),
+		},
+		{
+			data:     `42`,
+			expected: etcd.MemberID(42),
+		},
+		{
+			data:          `"wrong"`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		var id etcd.MemberID
+		err := json.Unmarshal([]byte(tc.data), &id)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.data, tc.expectedError, err)
+		}
+
+		if id != tc.expected {
+			t.Errorf("Test case: %s. Expected: %d, got: %d", tc.data, tc.expected, id)
+		}
+	}
+
+	data, err := json.Marshal(&etcd.MemberRemoveRequest{ID: etcd.MemberID(42)})
+	if err != nil {
+		t.Errorf("Expected: no error, got: %v", err)
+	}
+	if string(data) != `{"ID":"42"}` {
+		t.Errorf("Expected: the member ID encoded as a string, got: %s", string(data))
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := simulator.NewSimulator(
+		simulator.NewMember(1, "etcd-member-master-0", "192.168.111.20"),
+		simulator.NewMember(2, "etcd-member-master-1", "192.168.111.21"),
+	)
+	defer s.Close()
+
+	// the first endpoint is down, like the endpoint of the remediated member
+	c, err := etcd.NewClient([]string{"http://127.0.0.1:1", s.Endpoint()}, nil)
+	if err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+
+	members, err := c.MemberList(context.TODO())
+	if err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if len(members) != 2 {
+		t.Errorf("Expected: 2 members, got: %v", members)
+	}
+
+	if err := c.MemberRemove(context.TODO(), etcd.MemberID(1)); err != nil {
+		t.Errorf("Expected: no error, got: %v", err)
+	}
+
+	if err := c.MemberRemove(context.TODO(), etcd.MemberID(1)); err == nil {
+		t.Errorf("Expected: error on the removal of the missing member, got: nil")
+	}
+
+	members = s.Members()
+	if len(members) != 1 || members[0].ID != 2 {
+		t.Errorf("Expected: only member 2, got: %v", members)
+	}
+}
+
+func TestFindMember(t *testing.T) {
+	members := []etcd.Member{
+		simulator.NewMember(1, "etcd-member-master-0", "192.168.111.20"),
+		simulator.NewMember(2, "master-1", "192.168.111.21"),
+		// the member that was added, but did not start yet
+		{ID: 3, PeerURLs: []string{"https://192.168.111.22:2380"}},
+	}
+
+	testsCases := []struct {
+		name       string
+		nodeNames  []string
+		addresses  []string
+		expectedID etcd.MemberID
+		started    bool
+	}{
+		{
+			name:       "with OpenShift member name",
+			nodeNames:  []string{"master-0"},
+			expectedID: 1,
+			started:    true,
+		},
+		{
+			name:       "with node name",
+			nodeNames:  []string{"master-1"},
+			expectedID: 2,
+			started:    true,
+		},
+		{
+			name:       "with peer address",
+			nodeNames:  []string{"master-2"},
+			addresses:  []string{"192.168.111.22"},
+			expectedID: 3,
+			started:    false,
+		},
+		{
+			name:      "with unknown node",
+			nodeNames: []string{"worker-0"},
+			addresses: []string{"192.168.111.30"},
+		},
+	}
+
+	for _, tc := range testsCases {
+		member := etcd.FindMember(members, tc.nodeNames, tc.addresses)
+		if tc.expectedID == 0 {
+			if member != nil {
+				t.Errorf("Test case: %s. Expected: no member, got: %v", tc.name, member)
+			}
+			continue
+		}
+
+		if member == nil || member.ID != tc.expectedID {
+			t.Errorf("Test case: %s. Expected: member %d, got: %v", tc.name, tc.expectedID, member)
+			continue
+		}
+
+		if member.IsStarted() != tc.started {
+			t.Errorf("Test case: %s. Expected started: %t, got: %t", tc.name, tc.started, member.IsStarted())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"kubevirt.io/machine-remediation/pkg/etcd"
+)
+
+// Simulator is the local stand-in of the etcd JSON gateway, that serves cluster membership calls
+// and can be used for testing
+type Simulator struct {
+	server *httptest.Server
+
+	lock    sync.Mutex
+	members []etcd.Member
+}
+
+// NewSimulator starts new etcd simulator with members
+func NewSimulator(members ...etcd.Member) *Simulator {
+	s := &Simulator{
+		members: append([]etcd.Member{}, members...),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close stops the simulator
+func (s *Simulator) Close() {
+	s.server.Close()
+}
+
+// Endpoint returns the client endpoint of the simulator
+func (s *Simulator) Endpoint() string {
+	return s.server.URL
+}
+
+// Members returns current members of the simulated cluster
+func (s *Simulator) Members() []etcd.Member {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]etcd.Member{}, s.members...)
+}
+
+// AddMember adds the member to the simulated cluster, like the new host that joins the cluster
+func (s *Simulator) AddMember(member etcd.Member) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.members = append(s.members, member)
+}
+
+// NewMember returns the started member, that runs on the host with the name and the address
+func NewMember(id uint64, name string, address string) etcd.Member {
+	return etcd.Member{
+		ID:         etcd.MemberID(id),
+		Name:       name,
+		PeerURLs:   []string{fmt.Sprintf("https://%s:2380", address)},
+		ClientURLs: []string{fmt.Sprintf("https://%s:2379", address)},
+	}
+}
+
+func (s *Simulator) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case etcd.MemberListPath:
+		writeJSON(w, http.StatusOK, &etcd.MemberListResponse{Members: s.Members()})
+
+	case etcd.MemberRemovePath:
+		request := &etcd.MemberRemoveRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.remove(request.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, &etcd.MemberListResponse{Members: s.Members()})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// remove removes the member from the simulated cluster
+func (s *Simulator) remove(id etcd.MemberID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, member := range s.members {
+		if member.ID == id {
+			s.members = append(s.members[:i], s.members[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("member %s not found", id)
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(obj)
+}
@@ -0,0 +1,79 @@
+// Package protocol contains the wire protocol between the machine remediation controller and
+// the external remediator that runs out of the controller process.
+//
+// The controller sends HTTP POST requests with the JSON body to the external remediator endpoint,
+// the path of each call starts with the protocol version, for example /v1alpha1/reboot.
+// The external remediator should answer with the JSON body and the status code 200, in case of the error
+// it should answer with the ErrorResponse body and the 4xx status code when the error is permanent
+// and the remediation should fail, or with the 5xx status code when the controller should retry the call.
+package protocol
+
+import (
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+)
+
+const (
+	// Version contains the version of the protocol
+	Version = "v1alpha1"
+
+	// RebootPath contains the path of the reboot call
+	RebootPath = "/" + Version + "/reboot"
+	// RecreatePath contains the path of the recreate call
+	RecreatePath = "/" + Version + "/recreate"
+	// CapabilitiesPath contains the path of the capabilities call
+	CapabilitiesPath = "/" + Version + "/capabilities"
+	// CancelPath contains the path of the cancel call
+	CancelPath = "/" + Version + "/cancel"
+)
+
+// Request contains the body of the reboot, recreate and cancel calls.
+// The controller sends the reboot or the recreate call on each reconcile of the machine remediation object,
+// until the external remediator moves the remediation to the Succeeded or Failed state.
+type Request struct {
+	// APIVersion contains the version of the protocol
+	APIVersion string `json:"apiVersion"`
+	// MachineRemediation contains the machine remediation object with its current status
+	MachineRemediation *mrv1.MachineRemediation `json:"machineRemediation"`
+	// Machine contains the machine that should be remediated, it can be empty when the machine does not exist
+	Machine *mapiv1.Machine `json:"machine,omitempty"`
+}
+
+// Response contains the body of the response on the reboot, recreate and cancel calls
+type Response struct {
+	// APIVersion contains the version of the protocol
+	APIVersion string `json:"apiVersion"`
+	// State contains the new state of the remediation, the empty state leaves the state untouched
+	State mrv1.RemediationState `json:"state,omitempty"`
+	// Reason contains the human readable reason of the state, the controller saves it under the status message
+	Reason string `json:"reason,omitempty"`
+}
+
+// CapabilitiesResponse contains the body of the response on the capabilities call
+type CapabilitiesResponse struct {
+	// APIVersion contains the version of the protocol
+	APIVersion string `json:"apiVersion"`
+	// RemediationTypes contains remediation types supported by the external remediator
+	RemediationTypes []mrv1.RemediationType `json:"remediationTypes"`
+	// Cancel is true when the external remediator supports the cancel call
+	Cancel bool `json:"cancel"`
+}
+
+// ErrorResponse contains the body of the response when the call failed
+type ErrorResponse struct {
+	// APIVersion contains the version of the protocol
+	APIVersion string `json:"apiVersion"`
+	// Message contains the human readable error message
+	Message string `json:"message"`
+}
+
+// SupportsType returns true when the remediation type is supported
+func (c *CapabilitiesResponse) SupportsType(remediationType mrv1.RemediationType) bool {
+	for _, t := range c.RemediationTypes {
+		if t == remediationType {
+			return true
+		}
+	}
+	return false
+}
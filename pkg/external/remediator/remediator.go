@@ -0,0 +1,390 @@
+package remediator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/external/protocol"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// permanentError contains the error returned by the external remediator, that should fail the remediation
+type permanentError struct {
+	message string
+}
+
+func (e *permanentError) Error() string {
+	return e.message
+}
+
+// ExternalRemediator implements Remediator interface by forwarding machine remediations
+// to the external remediator over the versioned HTTP protocol
+type ExternalRemediator struct {
+	client     client.Client
+	recorder   record.EventRecorder
+	endpoint   string
+	httpClient *http.Client
+	// timeouts contains default timeouts of remediations, phases of the remediation are owned
+	// by the external remediator, so the adapter enforces only the total timeout
+	timeouts *timeouts.Defaults
+
+	lock         sync.Mutex
+	capabilities *protocol.CapabilitiesResponse
+}
+
+// NewExternalRemediator returns new ExternalRemediator object, that sends calls to the endpoint
+func NewExternalRemediator(mgr manager.Manager, endpoint string, defaultTimeouts *timeouts.Defaults) *ExternalRemediator {
+	return &ExternalRemediator{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor("external-remediator"),
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{
+			Timeout: defaultRequestTimeout,
+		},
+		timeouts: defaultTimeouts,
+	}
+}
+
+// Reboot forwards the reboot remediation to the external remediator
+func (er *ExternalRemediator) Reboot(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	return er.remediate(ctx, machineRemediation, protocol.RebootPath, "Reboot")
+}
+
+// Recreate forwards the recreate remediation to the external remediator
+func (er *ExternalRemediator) Recreate(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	return er.remediate(ctx, machineRemediation, protocol.RecreatePath, "Recreate")
+}
+
+// Cancel cancels the remediation in progress under the external remediator,
+// the controller calls it when the machine remediation object was deleted
+func (er *ExternalRemediator) Cancel(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	if !hasFinalizer(machineRemediation) {
+		return nil
+	}
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	if !isFinished(machineRemediation) {
+		capabilities, err := er.getCapabilities(ctx)
+		if err != nil {
+			return err
+		}
+
+		if capabilities.Cancel {
+			glog.V(4).Infof("Cancel remediation %q of machine %q", machineRemediation.Name, machineRemediation.Spec.MachineName)
+			response := &protocol.Response{}
+			if err := er.post(ctx, protocol.CancelPath, er.newRequest(machineRemediation), response); err != nil {
+				return err
+			}
+
+			er.recorder.Eventf(
+				machineRemediation,
+				corev1.EventTypeNormal,
+				"MachineRemediationCanceled",
+				"Remediation of machine %q canceled",
+				machineRemediation.Spec.MachineName,
+			)
+		}
+	}
+	return er.removeFinalizer(mrCopy)
+}
+
+// remediate sends the remediation call to the external remediator and updates the status from the response
+func (er *ExternalRemediator) remediate(ctx context.Context, machineRemediation *mrv1.MachineRemediation, path string, operation string) error {
+	glog.V(4).Infof("MachineRemediation %q has state %q", machineRemediation.Name, machineRemediation.Status.State)
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	now := time.Now()
+	switch machineRemediation.Status.State {
+	case "":
+		return nil
+
+	case mrv1.RemediationStateSucceeded, mrv1.RemediationStateFailed:
+		return er.removeFinalizer(mrCopy)
+	}
+
+	// failed the remediation on timeout
+	if er.timeouts.TotalExpired(machineRemediation, now) {
+		glog.Errorf("Remediation of machine %q failed on timeout", machineRemediation.Spec.MachineName)
+		er.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeWarning,
+			fmt.Sprintf("MachineRemediation%sTimedOut", operation),
+			"Remediation of machine %q timed out",
+			machineRemediation.Spec.MachineName,
+		)
+		return er.updateStatus(mrCopy, mrv1.RemediationStateFailed, mrv1.RemediationReasonTimedOut, fmt.Sprintf("%s failed on timeout", operation), now)
+	}
+
+	capabilities, err := er.getCapabilities(ctx)
+	if err != nil {
+		return err
+	}
+
+	remediationType := strategies.GetCurrentStrategy(machineRemediation).Type
+	if !capabilities.SupportsType(remediationType) {
+		glog.Errorf("External remediator does not support remediation type %q", remediationType)
+		er.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeWarning,
+			fmt.Sprintf("MachineRemediation%sFailed", operation),
+			"External remediator does not support remediation type %q",
+			remediationType,
+		)
+		return er.updateStatus(
+			mrCopy,
+			mrv1.RemediationStateFailed,
+			mrv1.RemediationReasonUnsupported,
+			fmt.Sprintf("Remediation type %q is not supported", remediationType),
+			now,
+		)
+	}
+
+	// add the finalizer, to cancel the remediation under the external remediator when the object deleted
+	if capabilities.Cancel && !hasFinalizer(mrCopy) {
+		mrCopy.Finalizers = append(mrCopy.Finalizers, consts.FinalizerExternalRemediation)
+		if err := er.client.Update(context.TODO(), mrCopy); err != nil {
+			return err
+		}
+	}
+
+	request := er.newRequest(machineRemediation)
+	machine := &mapiv1.Machine{}
+	key := types.NamespacedName{
+		Namespace: machineRemediation.Namespace,
+		Name:      machineRemediation.Spec.MachineName,
+	}
+	if err := er.client.Get(context.TODO(), key, machine); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		glog.Warningf("The machine %q does not exist", machineRemediation.Spec.MachineName)
+	} else {
+		request.Machine = machine
+	}
+
+	response := &protocol.Response{}
+	if err := er.post(ctx, path, request, response); err != nil {
+		if _, ok := err.(*permanentError); !ok {
+			return err
+		}
+
+		glog.Errorf("Remediation of machine %q failed: %v", machineRemediation.Spec.MachineName, err)
+		er.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeWarning,
+			fmt.Sprintf("MachineRemediation%sFailed", operation),
+			"Remediation of machine %q failed: %v",
+			machineRemediation.Spec.MachineName,
+			err,
+		)
+		return er.updateStatus(mrCopy, mrv1.RemediationStateFailed, mrv1.RemediationReasonExternalRemediatorError, err.Error(), now)
+	}
+
+	// the external remediator still works on the current state
+	if response.State == "" || response.State == machineRemediation.Status.State {
+		return nil
+	}
+
+	glog.V(4).Infof("External remediator moved remediation %q to state %q", machineRemediation.Name, response.State)
+	switch response.State {
+	case mrv1.RemediationStateSucceeded:
+		er.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeNormal,
+			fmt.Sprintf("MachineRemediation%sSucceeded", operation),
+			"Remediation of machine %q succeeded",
+			machineRemediation.Spec.MachineName,
+		)
+	case mrv1.RemediationStateFailed:
+		er.recorder.Eventf(
+			machineRemediation,
+			corev1.EventTypeWarning,
+			fmt.Sprintf("MachineRemediation%sFailed", operation),
+			"Remediation of machine %q failed: %s",
+			machineRemediation.Spec.MachineName,
+			response.Reason,
+		)
+	default:
+		if machineRemediation.Status.State == mrv1.RemediationStateStarted {
+			er.recorder.Eventf(
+				machineRemediation,
+				corev1.EventTypeNormal,
+				fmt.Sprintf("MachineRemediation%sStarted", operation),
+				"%s of machine %q has started",
+				operation,
+				machineRemediation.Spec.MachineName,
+			)
+		}
+	}
+	return er.updateStatus(mrCopy, response.State, getResponseReason(response.State), response.Reason, now)
+}
+
+// updateStatus updates the machine remediation state, reason and message, and sets the end time
+// and the succeeded condition for final states
+func (er *ExternalRemediator) updateStatus(
+	mrCopy *mrv1.MachineRemediation,
+	state mrv1.RemediationState,
+	reason mrv1.RemediationReason,
+	message string,
+	now time.Time,
+) error {
+	mrCopy.Status.State = state
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = reason
+	mrCopy.Status.Message = message
+	switch state {
+	case mrv1.RemediationStateSucceeded:
+		conditions.SetMachineRemediationCondition(mrCopy, mrv1.MachineRemediationConditionSucceeded, corev1.ConditionTrue, reason, message)
+		mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	case mrv1.RemediationStateFailed:
+		conditions.SetMachineRemediationCondition(mrCopy, mrv1.MachineRemediationConditionSucceeded, corev1.ConditionFalse, reason, message)
+		mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	}
+	return er.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// getResponseReason returns the machine remediation reason for the state reported by the external remediator
+func getResponseReason(state mrv1.RemediationState) mrv1.RemediationReason {
+	switch state {
+	case mrv1.RemediationStateSucceeded:
+		return mrv1.RemediationReasonSucceeded
+	case mrv1.RemediationStateFailed:
+		return mrv1.RemediationReasonExternalRemediatorError
+	default:
+		return mrv1.RemediationReasonInProgress
+	}
+}
+
+// removeFinalizer removes the external remediation finalizer from the machine remediation
+func (er *ExternalRemediator) removeFinalizer(machineRemediation *mrv1.MachineRemediation) error {
+	if !hasFinalizer(machineRemediation) {
+		return nil
+	}
+
+	finalizers := []string{}
+	for _, finalizer := range machineRemediation.Finalizers {
+		if finalizer != consts.FinalizerExternalRemediation {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	machineRemediation.Finalizers = finalizers
+	return er.client.Update(context.TODO(), machineRemediation)
+}
+
+// getCapabilities returns capabilities of the external remediator, it requests them only once
+func (er *ExternalRemediator) getCapabilities(ctx context.Context) (*protocol.CapabilitiesResponse, error) {
+	er.lock.Lock()
+	defer er.lock.Unlock()
+
+	if er.capabilities != nil {
+		return er.capabilities, nil
+	}
+
+	capabilities := &protocol.CapabilitiesResponse{}
+	if err := er.post(ctx, protocol.CapabilitiesPath, &protocol.Request{APIVersion: protocol.Version}, capabilities); err != nil {
+		return nil, fmt.Errorf("failed to get external remediator capabilities: %v", err)
+	}
+
+	if capabilities.APIVersion != protocol.Version {
+		return nil, fmt.Errorf("external remediator uses protocol version %q, expected %q", capabilities.APIVersion, protocol.Version)
+	}
+
+	er.capabilities = capabilities
+	return er.capabilities, nil
+}
+
+// newRequest returns new request for the machine remediation
+func (er *ExternalRemediator) newRequest(machineRemediation *mrv1.MachineRemediation) *protocol.Request {
+	return &protocol.Request{
+		APIVersion:         protocol.Version,
+		MachineRemediation: machineRemediation,
+	}
+}
+
+// post sends the call to the external remediator and decodes the response into the out object,
+// errors with 4xx status codes returned as permanent errors
+func (er *ExternalRemediator) post(ctx context.Context, path string, in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, er.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := er.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(body))
+		errorResponse := &protocol.ErrorResponse{}
+		if err := json.Unmarshal(body, errorResponse); err == nil && errorResponse.Message != "" {
+			message = errorResponse.Message
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode < http.StatusInternalServerError {
+			return &permanentError{message: message}
+		}
+		return fmt.Errorf("%s failed with status %d: %s", path, resp.StatusCode, message)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// hasFinalizer returns true when the machine remediation has the external remediation finalizer
+func hasFinalizer(machineRemediation *mrv1.MachineRemediation) bool {
+	for _, finalizer := range machineRemediation.Finalizers {
+		if finalizer == consts.FinalizerExternalRemediation {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinished returns true when the remediation reached the final state
+func isFinished(machineRemediation *mrv1.MachineRemediation) bool {
+	state := machineRemediation.Status.State
+	return state == mrv1.RemediationStateSucceeded || state == mrv1.RemediationStateFailed
+}
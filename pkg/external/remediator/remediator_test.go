@@ -0,0 +1,312 @@
+package remediator
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/external/protocol"
+	"kubevirt.io/machine-remediation/pkg/external/stub"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func newFakeExternalRemediator(recorder record.EventRecorder, endpoint string, objects ...runtime.Object) *ExternalRemediator {
+	fakeClient := fake.NewFakeClient(objects...)
+	return &ExternalRemediator{
+		client:     fakeClient,
+		recorder:   recorder,
+		endpoint:   endpoint,
+		httpClient: &http.Client{},
+		timeouts:   timeouts.NewDefaults(),
+	}
+}
+
+type expectedRemediationResult struct {
+	state                     mrv1.RemediationState
+	hasEndTime                bool
+	finalizerExist            bool
+	machineRemediationDeleted bool
+	calls                     []string
+	error                     bool
+}
+
+func TestRemediation(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+
+	machineRemediationTimedOut := mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	machineRemediationTimedOut.Status.StartTime = &metav1.Time{
+		Time: machineRemediationTimedOut.Status.StartTime.Time.Add(-61 * time.Minute),
+	}
+
+	machineRemediationSucceeded := mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+	machineRemediationSucceeded.Finalizers = []string{consts.FinalizerExternalRemediation}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		capabilities       *protocol.CapabilitiesResponse
+		errorStatus        int
+		expected           expectedRemediationResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with reboot remediation started",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStatePowerOff,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RebootPath},
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with reboot remediation in power off state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStatePowerOn,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RebootPath},
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with reboot remediation in power on state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn),
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStateSucceeded,
+				hasEndTime:     true,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RebootPath},
+			},
+			expectedEvents: []string{"MachineRemediationRebootSucceeded"},
+		},
+		{
+			name:               "with recreate remediation started",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeRecreate, mrv1.RemediationStateStarted),
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStateDeprovisioning,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RecreatePath},
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with remediation type that is not supported",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeRecreate, mrv1.RemediationStateStarted),
+			capabilities: &protocol.CapabilitiesResponse{
+				APIVersion:       protocol.Version,
+				RemediationTypes: []mrv1.RemediationType{mrv1.RemediationTypeReboot},
+			},
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateFailed,
+				hasEndTime: true,
+				calls:      []string{protocol.CapabilitiesPath},
+			},
+			expectedEvents: []string{"MachineRemediationRecreateFailed"},
+		},
+		{
+			name:               "with external remediator that uses other protocol version",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			capabilities: &protocol.CapabilitiesResponse{
+				APIVersion:       "v2",
+				RemediationTypes: []mrv1.RemediationType{mrv1.RemediationTypeReboot},
+			},
+			expected: expectedRemediationResult{
+				state: mrv1.RemediationStateStarted,
+				calls: []string{protocol.CapabilitiesPath},
+				error: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with external remediator that returns permanent error",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			errorStatus:        http.StatusBadRequest,
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStateFailed,
+				hasEndTime:     true,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RebootPath},
+			},
+			expectedEvents: []string{"MachineRemediationRebootFailed"},
+		},
+		{
+			name:               "with external remediator that returns transient error",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			errorStatus:        http.StatusServiceUnavailable,
+			expected: expectedRemediationResult{
+				state:          mrv1.RemediationStateStarted,
+				finalizerExist: true,
+				calls:          []string{protocol.CapabilitiesPath, protocol.RebootPath},
+				error:          true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with remediation that timed out",
+			machineRemediation: machineRemediationTimedOut,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateFailed,
+				hasEndTime: true,
+				calls:      []string{},
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
+		{
+			name:               "with remediation in succeeded state",
+			machineRemediation: machineRemediationSucceeded,
+			expected: expectedRemediationResult{
+				state: mrv1.RemediationStateSucceeded,
+				calls: []string{},
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		s := stub.NewServer()
+		if tc.capabilities != nil {
+			s.SetCapabilities(*tc.capabilities)
+		}
+		if tc.errorStatus != 0 {
+			s.SetError(tc.errorStatus, "stub error")
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		er := newFakeExternalRemediator(recorder, s.URL(), machine, tc.machineRemediation)
+
+		var err error
+		if tc.machineRemediation.Spec.Type == mrv1.RemediationTypeReboot {
+			err = er.Reboot(context.TODO(), tc.machineRemediation)
+		} else {
+			err = er.Recreate(context.TODO(), tc.machineRemediation)
+		}
+		if tc.expected.error != (err != nil) {
+			t.Errorf("%s failed, expected error: %t, got: %v", tc.name, tc.expected.error, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		if calls := s.Calls(); !reflect.DeepEqual(calls, tc.expected.calls) {
+			t.Errorf("%s failed, expected calls: %v, got: %v", tc.name, tc.expected.calls, calls)
+		}
+		s.Close()
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		err = er.client.Get(context.TODO(), key, newMachineRemediation)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineRemediationDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine remediation deleted: %t, got: %v", tc.name, tc.expected.machineRemediationDeleted, err)
+		}
+
+		if tc.expected.machineRemediationDeleted {
+			continue
+		}
+
+		if newMachineRemediation.Status.State != tc.expected.state {
+			t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+		}
+
+		if tc.expected.hasEndTime != (newMachineRemediation.Status.EndTime != nil) {
+			t.Errorf("%s failed, expected endTime: %t, got: %v", tc.name, tc.expected.hasEndTime, newMachineRemediation.Status.EndTime)
+		}
+
+		if hasFinalizer(newMachineRemediation) != tc.expected.finalizerExist {
+			t.Errorf("%s failed, expected finalizer: %t, got: %v", tc.name, tc.expected.finalizerExist, newMachineRemediation.Finalizers)
+		}
+	}
+}
+
+func TestCancel(t *testing.T) {
+	machineRemediationInProgress := mrtesting.NewMachineRemediation("mrInProgress", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	machineRemediationInProgress.Finalizers = []string{consts.FinalizerExternalRemediation}
+
+	machineRemediationFailed := mrtesting.NewMachineRemediation("mrFailed", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStateFailed)
+	machineRemediationFailed.Finalizers = []string{consts.FinalizerExternalRemediation}
+
+	machineRemediationWithoutFinalizer := mrtesting.NewMachineRemediation("mrWithoutFinalizer", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		expectedCanceled   []string
+		expectedEvents     []string
+	}{
+		{
+			name:               "with remediation in progress",
+			machineRemediation: machineRemediationInProgress,
+			expectedCanceled:   []string{machineRemediationInProgress.Name},
+			expectedEvents:     []string{"MachineRemediationCanceled"},
+		},
+		{
+			name:               "with failed remediation",
+			machineRemediation: machineRemediationFailed,
+			expectedCanceled:   []string{},
+			expectedEvents:     []string{},
+		},
+		{
+			name:               "with remediation without finalizer",
+			machineRemediation: machineRemediationWithoutFinalizer,
+			expectedCanceled:   []string{},
+			expectedEvents:     []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		s := stub.NewServer()
+		recorder := record.NewFakeRecorder(10)
+		er := newFakeExternalRemediator(recorder, s.URL(), tc.machineRemediation)
+
+		if err := er.Cancel(context.TODO(), tc.machineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		if canceled := s.Canceled(); !reflect.DeepEqual(canceled, tc.expectedCanceled) {
+			t.Errorf("%s failed, expected canceled: %v, got: %v", tc.name, tc.expectedCanceled, canceled)
+		}
+		s.Close()
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		if err := er.client.Get(context.TODO(), key, newMachineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if hasFinalizer(newMachineRemediation) {
+			t.Errorf("%s failed, expected no finalizer, got: %v", tc.name, newMachineRemediation.Finalizers)
+		}
+	}
+}
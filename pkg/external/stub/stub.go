@@ -0,0 +1,186 @@
+// Package stub contains the reference implementation of the external remediator protocol,
+// that moves each remediation one state forward on each call and can be used for testing.
+package stub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/external/protocol"
+)
+
+var (
+	// RebootStates contains states that the stub moves the reboot remediation through
+	RebootStates = map[mrv1.RemediationState]mrv1.RemediationState{
+		mrv1.RemediationStateStarted:  mrv1.RemediationStatePowerOff,
+		mrv1.RemediationStatePowerOff: mrv1.RemediationStatePowerOn,
+		mrv1.RemediationStatePowerOn:  mrv1.RemediationStateSucceeded,
+	}
+	// RecreateStates contains states that the stub moves the recreate remediation through
+	RecreateStates = map[mrv1.RemediationState]mrv1.RemediationState{
+		mrv1.RemediationStateStarted:        mrv1.RemediationStateDeprovisioning,
+		mrv1.RemediationStateDeprovisioning: mrv1.RemediationStateProvisioning,
+		mrv1.RemediationStateProvisioning:   mrv1.RemediationStateSucceeded,
+	}
+)
+
+// Server is the stub external remediator
+type Server struct {
+	server *httptest.Server
+
+	lock         sync.Mutex
+	capabilities protocol.CapabilitiesResponse
+	errorStatus  int
+	errorMessage string
+	calls        []string
+	canceled     []string
+}
+
+// NewServer starts new stub external remediator, that supports all remediation types and the cancel call
+func NewServer() *Server {
+	s := &Server{
+		capabilities: protocol.CapabilitiesResponse{
+			APIVersion: protocol.Version,
+			RemediationTypes: []mrv1.RemediationType{
+				mrv1.RemediationTypeReboot,
+				mrv1.RemediationTypeRecreate,
+			},
+			Cancel: true,
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(protocol.CapabilitiesPath, s.handleCapabilities)
+	mux.HandleFunc(protocol.RebootPath, s.remediationHandler(RebootStates))
+	mux.HandleFunc(protocol.RecreatePath, s.remediationHandler(RecreateStates))
+	mux.HandleFunc(protocol.CancelPath, s.handleCancel)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Close stops the stub
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the endpoint of the stub
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// SetCapabilities sets the capabilities that the stub reports
+func (s *Server) SetCapabilities(capabilities protocol.CapabilitiesResponse) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.capabilities = capabilities
+}
+
+// SetError makes the stub to answer on reboot and recreate calls with the error,
+// the zero status resets the error
+func (s *Server) SetError(status int, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errorStatus = status
+	s.errorMessage = message
+}
+
+// Calls returns paths of all calls that the stub received
+func (s *Server) Calls() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.calls...)
+}
+
+// Canceled returns names of machine remediations that the stub canceled
+func (s *Server) Canceled() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.canceled...)
+}
+
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = append(s.calls, r.URL.Path)
+	writeJSON(w, http.StatusOK, s.capabilities)
+}
+
+func (s *Server) remediationHandler(states map[mrv1.RemediationState]mrv1.RemediationState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request, ok := s.readRequest(w, r)
+		if !ok {
+			return
+		}
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if s.errorStatus != 0 {
+			writeJSON(w, s.errorStatus, &protocol.ErrorResponse{
+				APIVersion: protocol.Version,
+				Message:    s.errorMessage,
+			})
+			return
+		}
+
+		response := &protocol.Response{APIVersion: protocol.Version}
+		if next, ok := states[request.MachineRemediation.Status.State]; ok {
+			response.State = next
+			response.Reason = "Moved to " + string(next) + " by the stub"
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	request, ok := s.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.canceled = append(s.canceled, request.MachineRemediation.Name)
+	writeJSON(w, http.StatusOK, &protocol.Response{APIVersion: protocol.Version})
+}
+
+// readRequest decodes the request and verifies its version, it answers with the error on the invalid request
+func (s *Server) readRequest(w http.ResponseWriter, r *http.Request) (*protocol.Request, bool) {
+	s.lock.Lock()
+	s.calls = append(s.calls, r.URL.Path)
+	s.lock.Unlock()
+
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, &protocol.ErrorResponse{
+			APIVersion: protocol.Version,
+			Message:    "method not allowed",
+		})
+		return nil, false
+	}
+
+	request := &protocol.Request{}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		writeJSON(w, http.StatusBadRequest, &protocol.ErrorResponse{
+			APIVersion: protocol.Version,
+			Message:    err.Error(),
+		})
+		return nil, false
+	}
+
+	if request.APIVersion != protocol.Version || request.MachineRemediation == nil {
+		writeJSON(w, http.StatusBadRequest, &protocol.ErrorResponse{
+			APIVersion: protocol.Version,
+			Message:    "unsupported request",
+		})
+		return nil, false
+	}
+	return request, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(obj)
+}
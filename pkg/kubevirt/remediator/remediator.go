@@ -0,0 +1,668 @@
+package remediator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var (
+	// VirtualMachineGroupVersionKind contains the group, version and kind of the KubeVirt virtual machine
+	VirtualMachineGroupVersionKind = schema.GroupVersionKind{
+		Group:   "kubevirt.io",
+		Version: "v1alpha3",
+		Kind:    "VirtualMachine",
+	}
+	// VirtualMachineInstanceGroupVersionKind contains the group, version and kind of the KubeVirt virtual machine instance
+	VirtualMachineInstanceGroupVersionKind = schema.GroupVersionKind{
+		Group:   "kubevirt.io",
+		Version: "v1alpha3",
+		Kind:    "VirtualMachineInstance",
+	}
+)
+
+// KubeVirtRemediator implements Remediator interface for machines backed by KubeVirt virtual machines
+type KubeVirtRemediator struct {
+	client client.Client
+	// infraClient talks to the infra cluster that runs the virtual machines
+	infraClient client.Client
+	recorder    record.EventRecorder
+	// timeouts contains default timeouts of remediations
+	timeouts *timeouts.Defaults
+}
+
+// NewKubeVirtRemediator returns new KubeVirtRemediator object, the infra client should point to the cluster
+// that runs the KubeVirt virtual machines
+func NewKubeVirtRemediator(mgr manager.Manager, infraClient client.Client, defaultTimeouts *timeouts.Defaults) *KubeVirtRemediator {
+	return &KubeVirtRemediator{
+		client:      mgr.GetClient(),
+		infraClient: infraClient,
+		recorder:    mgr.GetEventRecorderFor("kubevirt-remediator"),
+		timeouts:    defaultTimeouts,
+	}
+}
+
+// Reboot reboots the KubeVirt machine by restarting its virtual machine instance
+func (kvr *KubeVirtRemediator) Reboot(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	glog.V(4).Infof("MachineRemediation %q has state %q", machineRemediation.Name, machineRemediation.Status.State)
+
+	// Get the machine from the MachineRemediation
+	key := types.NamespacedName{
+		Namespace: machineRemediation.Namespace,
+		Name:      machineRemediation.Spec.MachineName,
+	}
+	machine := &mapiv1.Machine{}
+	if err := kvr.client.Get(context.TODO(), key, machine); err != nil {
+		return err
+	}
+
+	// Get the virtual machine object
+	vm, err := getVirtualMachineByMachine(kvr.infraClient, machine)
+	if err != nil {
+		return err
+	}
+
+	// Copy the VirtualMachine object to prevent modification of the original one
+	vmCopy := vm.DeepCopy()
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	now := time.Now()
+	switch machineRemediation.Status.State {
+	// initiating the reboot action
+	case mrv1.RemediationStateStarted:
+		running, err := isVirtualMachineRunning(vm)
+		if err != nil {
+			return err
+		}
+
+		rebootInProgress := isRebootInProgress(vm)
+		// skip the reboot in case when the virtual machine was stopped before the reboot action
+		// it can mean that an user stopped the machine by purpose
+		if !running && !rebootInProgress {
+			glog.V(4).Infof("Skip the remediation, machine %q has stopped state before the remediation action", machine.Name)
+			kvr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationSkippedOffline",
+				"Remediation of machine %q skipped because it was in stopped state already",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateSucceeded
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSkippedOffline
+			mrCopy.Status.Message = "Skip the reboot, the machine stopped by an user"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSkippedOffline,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+		} else {
+			if !rebootInProgress {
+				// set rebootInProgress annotation on the virtual machine
+				annotations := vmCopy.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations[consts.AnnotationRebootInProgress] = "true"
+				vmCopy.SetAnnotations(annotations)
+			}
+
+			// stop the virtual machine, KubeVirt will delete the virtual machine instance
+			glog.V(4).Infof("Stop virtual machine %q of machine %q", vm.GetName(), machine.Name)
+			if err := unstructured.SetNestedField(vmCopy.Object, false, "spec", "running"); err != nil {
+				return err
+			}
+
+			if err := kvr.infraClient.Update(context.TODO(), vmCopy); err != nil {
+				return err
+			}
+
+			kvr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationRebootStarted",
+				"Reboot of machine %q has started",
+				machine.Name,
+			)
+
+			mrCopy.Status.State = mrv1.RemediationStatePowerOff
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+			mrCopy.Status.Message = "Starts the reboot process"
+		}
+		return kvr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOff:
+		// failed the remediation on timeout
+		if phase := kvr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return kvr.failOnTimeout(machine, mrCopy, "Reboot", phase, now)
+		}
+
+		// virtual machine instance still exists, we need to reconcile
+		exists, err := virtualMachineInstanceExists(kvr.infraClient, vm)
+		if err != nil {
+			return err
+		}
+		if exists {
+			glog.Warningf("virtual machine %q still has running instance", vm.GetName())
+			return nil
+		}
+
+		// delete the node to release workloads, once we are sure that the virtual machine instance was stopped
+		if err := nodes.DeleteNodeByMachine(kvr.client, machine); err != nil {
+			return err
+		}
+
+		// start the virtual machine
+		glog.V(4).Infof("Start virtual machine %q of machine %q", vm.GetName(), machine.Name)
+		if err := unstructured.SetNestedField(vmCopy.Object, true, "spec", "running"); err != nil {
+			return err
+		}
+
+		// remove the reboot in progress annotation
+		annotations := vmCopy.GetAnnotations()
+		if _, ok := annotations[consts.AnnotationRebootInProgress]; ok {
+			delete(annotations, consts.AnnotationRebootInProgress)
+			vmCopy.SetAnnotations(annotations)
+		}
+
+		if err := kvr.infraClient.Update(context.TODO(), vmCopy); err != nil {
+			return err
+		}
+		kvr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationRebootPoweringOn",
+			"Starting machine %q",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStatePowerOn
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Reboot in progress"
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionFenced,
+			corev1.ConditionTrue,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
+		return kvr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOn:
+		// failed the remediation on timeout
+		if phase := kvr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return kvr.failOnTimeout(machine, mrCopy, "Reboot", phase, now)
+		}
+
+		// wait for the virtual machine instance, the node ready timeout is measured from its start
+		if !conditions.MachineRemediationHasCondition(machineRemediation, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue) {
+			exists, err := virtualMachineInstanceExists(kvr.infraClient, vm)
+			if err != nil {
+				return err
+			}
+
+			// virtual machine instance still was not started, we need to reconcile
+			if !exists {
+				glog.Warningf("virtual machine %q still does not have running instance", vm.GetName())
+				return nil
+			}
+
+			glog.V(4).Infof("Machine %q powered on", machine.Name)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonInProgress,
+				"Virtual machine instance started",
+			)
+			return kvr.client.Status().Update(context.TODO(), mrCopy)
+		}
+
+		node, err := nodes.GetNodeByMachine(kvr.client, machine)
+		if err != nil {
+			// we want to reconcile with delay of 10 seconds when the machine does not have node reference
+			// or node does not exist
+			if errors.IsNotFound(err) {
+				glog.Warningf("The machine %q node does not exist", machine.Name)
+				return nil
+			}
+			return err
+		}
+
+		// Node back to Ready under the cluster, and it booted again
+		if conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) && nodes.IsRebooted(node, machineRemediation) {
+			glog.V(4).Infof("Remediation of machine %q succeeded", machine.Name)
+			nodeCopy := node.DeepCopy()
+			nodes.RestoreSavedMetadata(nodeCopy, machineRemediation)
+			delete(nodeCopy.Annotations, consts.AnnotationNodeMachineReboot)
+			if err := kvr.client.Update(context.TODO(), nodeCopy); err != nil {
+				return err
+			}
+
+			glog.V(4).Infof("Reapplied labels, annotations and taints to node %q", node.Name)
+
+			kvr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationRebootSucceeded",
+				"Remediation of machine %q succeeded",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateSucceeded
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSucceeded
+			mrCopy.Status.Message = "Reboot succeeded"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionNodeReady,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+			return kvr.client.Status().Update(context.TODO(), mrCopy)
+		}
+		return nil
+
+	case mrv1.RemediationStateFailed:
+		node, err := nodes.GetNodeByMachine(kvr.client, machine)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// remove the reboot annotation from the node, to initiate the reboot again
+		return nodes.RemoveRebootAnnotation(kvr.client, node)
+	}
+	return nil
+}
+
+// Recreate deletes the KubeVirt virtual machine of the machine and creates it again from its spec
+func (kvr *KubeVirtRemediator) Recreate(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	glog.V(4).Infof("MachineRemediation %q has state %q", machineRemediation.Name, machineRemediation.Status.State)
+
+	// Get the machine from the MachineRemediation
+	key := types.NamespacedName{
+		Namespace: machineRemediation.Namespace,
+		Name:      machineRemediation.Spec.MachineName,
+	}
+	machine := &mapiv1.Machine{}
+	if err := kvr.client.Get(context.TODO(), key, machine); err != nil {
+		return err
+	}
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	now := time.Now()
+	switch machineRemediation.Status.State {
+	// initiating the recreate action
+	case mrv1.RemediationStateStarted:
+		// the virtual machine was saved by the previous reconcile, that failed to update the state
+		_, saved := mrCopy.Annotations[consts.AnnotationSavedVirtualMachine]
+
+		vm, err := getVirtualMachineByMachine(kvr.infraClient, machine)
+		if err != nil {
+			// the saved virtual machine was already deleted
+			if !saved || !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		// save the virtual machine under the machine remediation object,
+		// because we need its spec to create it again after the deletion
+		if !saved {
+			savedVM, err := newSavedVirtualMachine(vm)
+			if err != nil {
+				return err
+			}
+			if mrCopy.Annotations == nil {
+				mrCopy.Annotations = map[string]string{}
+			}
+			mrCopy.Annotations[consts.AnnotationSavedVirtualMachine] = savedVM
+			if err := kvr.client.Update(context.TODO(), mrCopy); err != nil {
+				return err
+			}
+		}
+
+		// delete the virtual machine, KubeVirt will delete the virtual machine instance
+		if vm != nil {
+			glog.V(4).Infof("Delete virtual machine %q of machine %q", vm.GetName(), machine.Name)
+			if err := kvr.infraClient.Delete(context.TODO(), vm); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		kvr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationRecreateStarted",
+			"Recreate of machine %q has started",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStatePowerOff
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Deleting the virtual machine"
+		return kvr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOff:
+		// failed the remediation on timeout
+		if phase := kvr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return kvr.failOnTimeout(machine, mrCopy, "Recreate", phase, now)
+		}
+
+		savedVM, err := getSavedVirtualMachine(machineRemediation)
+		if err != nil {
+			return err
+		}
+
+		// virtual machine or its instance still exist, we need to reconcile
+		vm := newVirtualMachine(savedVM.GetNamespace(), savedVM.GetName())
+		vmKey := client.ObjectKey{
+			Namespace: savedVM.GetNamespace(),
+			Name:      savedVM.GetName(),
+		}
+		if err := kvr.infraClient.Get(context.TODO(), vmKey, vm); err == nil {
+			glog.Warningf("virtual machine %q still was not deleted", vm.GetName())
+			return nil
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+
+		exists, err := virtualMachineInstanceExists(kvr.infraClient, savedVM)
+		if err != nil {
+			return err
+		}
+		if exists {
+			glog.Warningf("virtual machine %q still has running instance", savedVM.GetName())
+			return nil
+		}
+
+		// delete the node to release workloads, once we are sure that the virtual machine instance was deleted
+		if err := nodes.DeleteNodeByMachine(kvr.client, machine); err != nil {
+			return err
+		}
+
+		// create the virtual machine from the saved spec
+		glog.V(4).Infof("Create virtual machine %q of machine %q", savedVM.GetName(), machine.Name)
+		if err := kvr.infraClient.Create(context.TODO(), savedVM); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+
+		kvr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationRecreateVirtualMachineCreated",
+			"Virtual machine of machine %q created, waiting for the node",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStatePowerOn
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Recreate in progress"
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionFenced,
+			corev1.ConditionTrue,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
+		return kvr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOn:
+		// failed the remediation on timeout
+		if phase := kvr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return kvr.failOnTimeout(machine, mrCopy, "Recreate", phase, now)
+		}
+
+		node, err := nodes.GetNodeByMachine(kvr.client, machine)
+		if err != nil {
+			// we want to reconcile with delay of 10 seconds when the machine does not have node reference
+			// or node does not exist
+			if errors.IsNotFound(err) {
+				glog.Warningf("The machine %q node does not exist", machine.Name)
+				return nil
+			}
+			return err
+		}
+
+		// Node of the recreated virtual machine is Ready under the cluster, and it booted again
+		if conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) && nodes.IsRebooted(node, machineRemediation) {
+			glog.V(4).Infof("Remediation of machine %q succeeded", machine.Name)
+			kvr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationRecreateSucceeded",
+				"Recreate of machine %q succeeded",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateSucceeded
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSucceeded
+			mrCopy.Status.Message = "Recreate succeeded"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionNodeReady,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+			return kvr.client.Status().Update(context.TODO(), mrCopy)
+		}
+		return nil
+	}
+	return nil
+}
+
+// failOnTimeout moves the machine remediation to the failed state
+func (kvr *KubeVirtRemediator) failOnTimeout(
+	machine *mapiv1.Machine,
+	mrCopy *mrv1.MachineRemediation,
+	operation string,
+	phase timeouts.Phase,
+	now time.Time,
+) error {
+	glog.Errorf("Remediation of machine %q failed on %s timeout", machine.Name, phase)
+	kvr.recorder.Eventf(
+		machine,
+		corev1.EventTypeWarning,
+		fmt.Sprintf("MachineRemediation%sTimedOut", operation),
+		"Remediation of machine %q timed out, %s timeout expired",
+		machine.Name,
+		phase,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateFailed
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonTimedOut
+	mrCopy.Status.Message = fmt.Sprintf("%s failed on %s timeout", operation, phase)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonTimedOut,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return kvr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// newVirtualMachine returns new empty virtual machine object with the namespace and the name
+func newVirtualMachine(namespace string, name string) *unstructured.Unstructured {
+	vm := &unstructured.Unstructured{}
+	vm.SetGroupVersionKind(VirtualMachineGroupVersionKind)
+	vm.SetNamespace(namespace)
+	vm.SetName(name)
+	return vm
+}
+
+// getVirtualMachineByMachine returns the virtual machine that linked to the machine
+func getVirtualMachineByMachine(c client.Client, machine *mapiv1.Machine) (*unstructured.Unstructured, error) {
+	vmKey, ok := machine.Annotations[consts.AnnotationKubeVirtVirtualMachine]
+	if !ok || vmKey == "" {
+		return nil, fmt.Errorf("machine does not have virtual machine annotation")
+	}
+
+	vmNamespace, vmName, err := cache.SplitMetaNamespaceKey(vmKey)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := newVirtualMachine(vmNamespace, vmName)
+	key := client.ObjectKey{
+		Name:      vmName,
+		Namespace: vmNamespace,
+	}
+	if err := c.Get(context.TODO(), key, vm); err != nil {
+		return nil, err
+	}
+	return vm, nil
+}
+
+// virtualMachineInstanceExists returns true when the virtual machine instance of the virtual machine exists
+func virtualMachineInstanceExists(c client.Client, vm *unstructured.Unstructured) (bool, error) {
+	vmi := &unstructured.Unstructured{}
+	vmi.SetGroupVersionKind(VirtualMachineInstanceGroupVersionKind)
+	key := client.ObjectKey{
+		Name:      vm.GetName(),
+		Namespace: vm.GetNamespace(),
+	}
+	if err := c.Get(context.TODO(), key, vmi); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// newSavedVirtualMachine returns the serialized virtual machine without the fields populated by the server.
+// The saved virtual machine always runs, even when the failed reboot left it stopped.
+func newSavedVirtualMachine(vm *unstructured.Unstructured) (string, error) {
+	spec, ok, err := unstructured.NestedMap(vm.Object, "spec")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("virtual machine %q does not have spec", vm.GetName())
+	}
+
+	// the virtual machine can not have both the running and the run strategy fields
+	if _, ok := spec["runStrategy"]; ok {
+		spec["runStrategy"] = "Always"
+	} else {
+		spec["running"] = true
+	}
+
+	annotations := map[string]string{}
+	for k, v := range vm.GetAnnotations() {
+		if k != consts.AnnotationRebootInProgress {
+			annotations[k] = v
+		}
+	}
+
+	savedVM := newVirtualMachine(vm.GetNamespace(), vm.GetName())
+	savedVM.SetLabels(vm.GetLabels())
+	savedVM.SetAnnotations(annotations)
+	savedVM.SetOwnerReferences(vm.GetOwnerReferences())
+	if err := unstructured.SetNestedMap(savedVM.Object, spec, "spec"); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(savedVM.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// getSavedVirtualMachine returns the virtual machine saved under the machine remediation object
+func getSavedVirtualMachine(machineRemediation *mrv1.MachineRemediation) (*unstructured.Unstructured, error) {
+	data, ok := machineRemediation.Annotations[consts.AnnotationSavedVirtualMachine]
+	if !ok || data == "" {
+		return nil, fmt.Errorf("machine remediation %q does not have saved virtual machine", machineRemediation.Name)
+	}
+
+	savedVM := &unstructured.Unstructured{}
+	if err := json.Unmarshal([]byte(data), &savedVM.Object); err != nil {
+		return nil, err
+	}
+	return savedVM, nil
+}
+
+// isVirtualMachineRunning returns true when the virtual machine should be running
+func isVirtualMachineRunning(vm *unstructured.Unstructured) (bool, error) {
+	running, ok, err := unstructured.NestedBool(vm.Object, "spec", "running")
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, fmt.Errorf("virtual machine %q does not have running field", vm.GetName())
+	}
+	return running, nil
+}
+
+// isRebootInProgress returns true when the VirtualMachine currently is rebooting
+func isRebootInProgress(vm *unstructured.Unstructured) bool {
+	rebootInProgress, ok := vm.GetAnnotations()[consts.AnnotationRebootInProgress]
+	if !ok || rebootInProgress != "true" {
+		return false
+	}
+	return true
+}
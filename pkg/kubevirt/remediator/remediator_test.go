@@ -0,0 +1,646 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func newFakeKubeVirtRemediator(recorder record.EventRecorder, objects []runtime.Object, infraObjects []runtime.Object) *KubeVirtRemediator {
+	return &KubeVirtRemediator{
+		client:      fake.NewFakeClient(objects...),
+		infraClient: fake.NewFakeClient(infraObjects...),
+		recorder:    recorder,
+		timeouts:    timeouts.NewDefaults(),
+	}
+}
+
+func newTestVirtualMachine(name string, running bool) *unstructured.Unstructured {
+	vm := newVirtualMachine(consts.NamespaceOpenshiftMachineAPI, name)
+	vm.SetAnnotations(map[string]string{})
+	vm.SetLabels(map[string]string{"kubevirt.io/vm": name})
+	unstructured.SetNestedField(vm.Object, running, "spec", "running")
+	unstructured.SetNestedStringMap(vm.Object, map[string]string{"kubevirt.io/vm": name}, "spec", "template", "metadata", "labels")
+	return vm
+}
+
+func newTestVirtualMachineInstance(name string) *unstructured.Unstructured {
+	vmi := &unstructured.Unstructured{}
+	vmi.SetGroupVersionKind(VirtualMachineInstanceGroupVersionKind)
+	vmi.SetNamespace(consts.NamespaceOpenshiftMachineAPI)
+	vmi.SetName(name)
+	return vmi
+}
+
+func newTestMachine(name string, nodeName string, vmName string) *mapiv1.Machine {
+	machine := mrtesting.NewMachine(name, nodeName, "")
+	delete(machine.Annotations, consts.AnnotationBareMetalHost)
+	machine.Annotations[consts.AnnotationKubeVirtVirtualMachine] = fmt.Sprintf("%s/%s", consts.NamespaceOpenshiftMachineAPI, vmName)
+	return machine
+}
+
+func getTestVirtualMachine(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	vm := newVirtualMachine(consts.NamespaceOpenshiftMachineAPI, name)
+	key := client.ObjectKey{
+		Namespace: consts.NamespaceOpenshiftMachineAPI,
+		Name:      name,
+	}
+	if err := c.Get(context.TODO(), key, vm); err != nil {
+		if !errors.IsNotFound(err) {
+			t.Errorf("expected no error, got: %v", err)
+		}
+		return nil
+	}
+	return vm
+}
+
+func timedOut(machineRemediation *mrv1.MachineRemediation, timeout time.Duration) *mrv1.MachineRemediation {
+	machineRemediation.Status.StartTime = &metav1.Time{
+		Time: machineRemediation.Status.StartTime.Time.Add(-timeout - time.Minute),
+	}
+	return machineRemediation
+}
+
+type expectedRebootResult struct {
+	state                           mrv1.RemediationState
+	hasEndTime                      bool
+	virtualMachineRunning           bool
+	nodeDeleted                     bool
+	machineRemediationDeleted       bool
+	rebootInProgressAnnotationExist bool
+	nodeRebootAnnotationExist       bool
+}
+
+func TestRemediationReboot(t *testing.T) {
+	nodeOnline := mrtesting.NewNode("nodeOnline", true, "machineOnline")
+	nodeOnline.Annotations[consts.AnnotationNodeMachineReboot] = ""
+	nodeOnline.Status.NodeInfo.BootID = "bootOnline"
+	nodeNotReady := mrtesting.NewNode("nodeNotReady", false, "machineNotReady")
+	nodeNotReady.Annotations[consts.AnnotationNodeMachineReboot] = ""
+
+	vmRunning := newTestVirtualMachine("vmRunning", true)
+	vmStopped := newTestVirtualMachine("vmStopped", false)
+	vmStoppedWithRebootAnnotation := newTestVirtualMachine("vmStoppedWithRebootAnnotation", false)
+	vmStoppedWithRebootAnnotation.SetAnnotations(map[string]string{consts.AnnotationRebootInProgress: "true"})
+	vmiRunning := newTestVirtualMachineInstance(vmRunning.GetName())
+
+	machineRunning := newTestMachine("machineRunning", nodeOnline.Name, vmRunning.GetName())
+	machineStopped := newTestMachine("machineStopped", nodeOnline.Name, vmStopped.GetName())
+	machineStoppedWithRebootAnnotation := newTestMachine("machineStoppedWithRebootAnnotation", nodeOnline.Name, vmStoppedWithRebootAnnotation.GetName())
+	machineNotReady := newTestMachine("machineNotReady", nodeNotReady.Name, vmRunning.GetName())
+
+	machineRemediationRebooted := mrtesting.NewPoweredOnMachineRemediation("mr", machineRunning.Name, time.Now())
+	machineRemediationRebooted.Status.NodeBootID = "bootBeforeReboot"
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		virtualMachine     *unstructured.Unstructured
+		node               *corev1.Node
+		expected           expectedRebootResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation started and stopped virtual machine",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineStopped.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			virtualMachine:     vmStopped,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateSucceeded,
+				hasEndTime:                true,
+				virtualMachineRunning:     false,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationSkippedOffline"},
+		},
+		{
+			name:               "with machine remediation started and running virtual machine",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineRunning.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			virtualMachine:     vmRunning,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                           mrv1.RemediationStatePowerOff,
+				virtualMachineRunning:           false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation started and stopped virtual machine with reboot in progress annotation",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineStoppedWithRebootAnnotation.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			virtualMachine:     vmStoppedWithRebootAnnotation,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                           mrv1.RemediationStatePowerOff,
+				virtualMachineRunning:           false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation in power off state and running virtual machine instance",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineRunning.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			virtualMachine:     vmRunning,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStatePowerOff,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power off state and stopped virtual machine instance",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineStoppedWithRebootAnnotation.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			virtualMachine:     vmStoppedWithRebootAnnotation,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                           mrv1.RemediationStatePowerOn,
+				virtualMachineRunning:           true,
+				nodeDeleted:                     true,
+				rebootInProgressAnnotationExist: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootPoweringOn"},
+		},
+		{
+			name:               "with machine remediation in power off state that timed out",
+			machineRemediation: timedOut(mrtesting.NewMachineRemediation("mr", machineStoppedWithRebootAnnotation.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff), timeouts.DefaultPowerOff),
+			virtualMachine:     vmStoppedWithRebootAnnotation,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                           mrv1.RemediationStateFailed,
+				hasEndTime:                      true,
+				virtualMachineRunning:           false,
+				rebootInProgressAnnotationExist: true,
+				nodeRebootAnnotationExist:       true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
+		{
+			name:               "with machine remediation in power on state and ready node",
+			machineRemediation: machineRemediationRebooted,
+			virtualMachine:     vmRunning,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateSucceeded,
+				hasEndTime:                true,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootSucceeded"},
+		},
+		{
+			name:               "with machine remediation in power on state and non ready node",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn),
+			virtualMachine:     vmRunning,
+			node:               nodeNotReady,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStatePowerOn,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state that timed out",
+			machineRemediation: timedOut(mrtesting.NewMachineRemediation("mr", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn), timeouts.DefaultBoot),
+			virtualMachine:     vmRunning,
+			node:               nodeNotReady,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateFailed,
+				hasEndTime:                true,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
+		{
+			name:               "with machine remediation in power on state that timed out on node ready",
+			machineRemediation: mrtesting.NewPoweredOnMachineRemediation("mr", machineNotReady.Name, time.Now().Add(-timeouts.DefaultNodeReady-time.Minute)),
+			virtualMachine:     vmRunning,
+			node:               nodeNotReady,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateFailed,
+				hasEndTime:                true,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
+		{
+			name:               "with machine remediation in succeeded state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineRunning.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded),
+			virtualMachine:     vmRunning,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateSucceeded,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in failed state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineRunning.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateFailed),
+			virtualMachine:     vmRunning,
+			node:               nodeOnline,
+			expected: expectedRebootResult{
+				state:                     mrv1.RemediationStateFailed,
+				virtualMachineRunning:     true,
+				nodeRebootAnnotationExist: false,
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		infraObjects := []runtime.Object{tc.virtualMachine}
+		// the running virtual machine always has the instance
+		if running, _ := isVirtualMachineRunning(tc.virtualMachine); running {
+			infraObjects = append(infraObjects, vmiRunning)
+		}
+		kvr := newFakeKubeVirtRemediator(
+			recorder,
+			[]runtime.Object{
+				tc.node,
+				machineRunning,
+				machineStopped,
+				machineStoppedWithRebootAnnotation,
+				machineNotReady,
+				tc.machineRemediation,
+			},
+			infraObjects,
+		)
+
+		if err := kvr.Reboot(context.TODO(), tc.machineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		err := kvr.client.Get(context.TODO(), key, newMachineRemediation)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineRemediationDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine remediation deleted: %t, got: %v", tc.name, tc.expected.machineRemediationDeleted, err)
+		}
+
+		if !tc.expected.machineRemediationDeleted {
+			if newMachineRemediation.Status.State != tc.expected.state {
+				t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+			}
+
+			if tc.expected.hasEndTime != (newMachineRemediation.Status.EndTime != nil) {
+				t.Errorf("%s failed, expected endTime: %t, got: %v", tc.name, tc.expected.hasEndTime, newMachineRemediation.Status.EndTime)
+			}
+		}
+
+		newVirtualMachine := getTestVirtualMachine(t, kvr.infraClient, tc.virtualMachine.GetName())
+		if newVirtualMachine == nil {
+			t.Errorf("%s failed, expected virtual machine %q to exist", tc.name, tc.virtualMachine.GetName())
+			continue
+		}
+
+		running, err := isVirtualMachineRunning(newVirtualMachine)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+		if running != tc.expected.virtualMachineRunning {
+			t.Errorf("%s failed, expected virtual machine running: %t, got: %t", tc.name, tc.expected.virtualMachineRunning, running)
+		}
+
+		if isRebootInProgress(newVirtualMachine) != tc.expected.rebootInProgressAnnotationExist {
+			t.Errorf("%s failed, expected virtual machine to have %q annotation: %t", tc.name, consts.AnnotationRebootInProgress, tc.expected.rebootInProgressAnnotationExist)
+		}
+
+		node := &corev1.Node{}
+		key = types.NamespacedName{
+			Namespace: tc.node.Namespace,
+			Name:      tc.node.Name,
+		}
+		err = kvr.client.Get(context.TODO(), key, node)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.nodeDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected node deleted: %t, got: %v", tc.name, tc.expected.nodeDeleted, err)
+		}
+
+		if err == nil {
+			_, ok := node.Annotations[consts.AnnotationNodeMachineReboot]
+			if ok != tc.expected.nodeRebootAnnotationExist {
+				t.Errorf("%s failed, expected node to have %q annotation: %t, got: %t", tc.name, consts.AnnotationNodeMachineReboot, tc.expected.nodeRebootAnnotationExist, ok)
+			}
+		}
+	}
+}
+
+type expectedRecreateResult struct {
+	state                     mrv1.RemediationState
+	hasEndTime                bool
+	virtualMachineExists      bool
+	nodeDeleted               bool
+	machineRemediationDeleted bool
+	savedVirtualMachineExist  bool
+	savedVirtualMachine       string
+}
+
+func TestRemediationRecreate(t *testing.T) {
+	nodeOnline := mrtesting.NewNode("nodeOnline", true, "machineRunning")
+	nodeOnline.Status.NodeInfo.BootID = "bootOnline"
+	nodeNotReady := mrtesting.NewNode("nodeNotReady", false, "machineNotReady")
+
+	vmRunning := newTestVirtualMachine("vmRunning", true)
+	vmiRunning := newTestVirtualMachineInstance(vmRunning.GetName())
+	savedVM, err := newSavedVirtualMachine(vmRunning)
+	if err != nil {
+		t.Fatalf("failed to save virtual machine: %v", err)
+	}
+
+	machineRunning := newTestMachine("machineRunning", nodeOnline.Name, vmRunning.GetName())
+	machineNotReady := newTestMachine("machineNotReady", nodeNotReady.Name, vmRunning.GetName())
+
+	// the virtual machine that the failed reboot left stopped
+	vmRebooting := newTestVirtualMachine(vmRunning.GetName(), false)
+	vmRebooting.SetAnnotations(map[string]string{consts.AnnotationRebootInProgress: "true"})
+
+	// the virtual machine that changed after the previous reconcile saved it
+	vmChanged := newTestVirtualMachine(vmRunning.GetName(), true)
+	vmChanged.SetLabels(map[string]string{"kubevirt.io/vm": vmRunning.GetName(), "changed": "true"})
+
+	newRecreateRemediation := func(machineName string, state mrv1.RemediationState) *mrv1.MachineRemediation {
+		mr := mrtesting.NewMachineRemediation("mr", machineName, mrv1.RemediationTypeRecreate, state)
+		if state != mrv1.RemediationStateStarted {
+			mr.Annotations = map[string]string{consts.AnnotationSavedVirtualMachine: savedVM}
+			mr.Status.NodeBootID = "bootBeforeRecreate"
+		}
+		return mr
+	}
+
+	newSavedRecreateRemediation := func(machineName string) *mrv1.MachineRemediation {
+		mr := newRecreateRemediation(machineName, mrv1.RemediationStateStarted)
+		mr.Annotations = map[string]string{consts.AnnotationSavedVirtualMachine: savedVM}
+		return mr
+	}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		infraObjects       []runtime.Object
+		node               *corev1.Node
+		expected           expectedRecreateResult
+		expectedEvents     []string
+	}{
+		{
+			name:               "with machine remediation started",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStateStarted),
+			infraObjects:       []runtime.Object{vmRunning, vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation started and virtual machine stopped by the reboot",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStateStarted),
+			infraObjects:       []runtime.Object{vmRebooting},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+				savedVirtualMachine:      savedVM,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation started that already saved the virtual machine",
+			machineRemediation: newSavedRecreateRemediation(machineRunning.Name),
+			infraObjects:       []runtime.Object{vmChanged, vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+				savedVirtualMachine:      savedVM,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation started that already deleted the virtual machine",
+			machineRemediation: newSavedRecreateRemediation(machineRunning.Name),
+			infraObjects:       []runtime.Object{},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+				savedVirtualMachine:      savedVM,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateStarted"},
+		},
+		{
+			name:               "with machine remediation in power off state and existing virtual machine",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStatePowerOff),
+			infraObjects:       []runtime.Object{vmRunning, vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     true,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power off state and existing virtual machine instance",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStatePowerOff),
+			infraObjects:       []runtime.Object{vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOff,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power off state and deleted virtual machine",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStatePowerOff),
+			infraObjects:       []runtime.Object{},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOn,
+				virtualMachineExists:     true,
+				nodeDeleted:              true,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateVirtualMachineCreated"},
+		},
+		{
+			name:               "with machine remediation in power off state that timed out",
+			machineRemediation: timedOut(newRecreateRemediation(machineRunning.Name, mrv1.RemediationStatePowerOff), timeouts.DefaultRecreate),
+			infraObjects:       []runtime.Object{},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStateFailed,
+				hasEndTime:               true,
+				virtualMachineExists:     false,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateTimedOut"},
+		},
+		{
+			name:               "with machine remediation in power on state and ready node",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStatePowerOn),
+			infraObjects:       []runtime.Object{vmRunning, vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStateSucceeded,
+				hasEndTime:               true,
+				virtualMachineExists:     true,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{"MachineRemediationRecreateSucceeded"},
+		},
+		{
+			name:               "with machine remediation in power on state and non ready node",
+			machineRemediation: newRecreateRemediation(machineNotReady.Name, mrv1.RemediationStatePowerOn),
+			infraObjects:       []runtime.Object{vmRunning, vmiRunning},
+			node:               nodeNotReady,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStatePowerOn,
+				virtualMachineExists:     true,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in succeeded state",
+			machineRemediation: newRecreateRemediation(machineRunning.Name, mrv1.RemediationStateSucceeded),
+			infraObjects:       []runtime.Object{vmRunning, vmiRunning},
+			node:               nodeOnline,
+			expected: expectedRecreateResult{
+				state:                    mrv1.RemediationStateSucceeded,
+				virtualMachineExists:     true,
+				savedVirtualMachineExist: true,
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		recorder := record.NewFakeRecorder(10)
+		kvr := newFakeKubeVirtRemediator(
+			recorder,
+			[]runtime.Object{
+				tc.node,
+				machineRunning,
+				machineNotReady,
+				tc.machineRemediation,
+			},
+			tc.infraObjects,
+		)
+
+		if err := kvr.Recreate(context.TODO(), tc.machineRemediation); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		err := kvr.client.Get(context.TODO(), key, newMachineRemediation)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineRemediationDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine remediation deleted: %t, got: %v", tc.name, tc.expected.machineRemediationDeleted, err)
+		}
+
+		if !tc.expected.machineRemediationDeleted {
+			if newMachineRemediation.Status.State != tc.expected.state {
+				t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+			}
+
+			if tc.expected.hasEndTime != (newMachineRemediation.Status.EndTime != nil) {
+				t.Errorf("%s failed, expected endTime: %t, got: %v", tc.name, tc.expected.hasEndTime, newMachineRemediation.Status.EndTime)
+			}
+
+			if _, err := getSavedVirtualMachine(newMachineRemediation); (err == nil) != tc.expected.savedVirtualMachineExist {
+				t.Errorf("%s failed, expected saved virtual machine: %t, got: %v", tc.name, tc.expected.savedVirtualMachineExist, err)
+			}
+
+			if tc.expected.savedVirtualMachine != "" && newMachineRemediation.Annotations[consts.AnnotationSavedVirtualMachine] != tc.expected.savedVirtualMachine {
+				t.Errorf("%s failed, expected saved virtual machine: %s, got: %s", tc.name, tc.expected.savedVirtualMachine, newMachineRemediation.Annotations[consts.AnnotationSavedVirtualMachine])
+			}
+		}
+
+		newVirtualMachine := getTestVirtualMachine(t, kvr.infraClient, vmRunning.GetName())
+		if (newVirtualMachine != nil) != tc.expected.virtualMachineExists {
+			t.Errorf("%s failed, expected virtual machine exists: %t", tc.name, tc.expected.virtualMachineExists)
+		}
+
+		if newVirtualMachine != nil {
+			if running, _ := isVirtualMachineRunning(newVirtualMachine); !running {
+				t.Errorf("%s failed, expected virtual machine to be running", tc.name)
+			}
+		}
+
+		node := &corev1.Node{}
+		key = types.NamespacedName{
+			Namespace: tc.node.Namespace,
+			Name:      tc.node.Name,
+		}
+		err = kvr.client.Get(context.TODO(), key, node)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.nodeDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected node deleted: %t, got: %v", tc.name, tc.expected.nodeDeleted, err)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package machineapi
+
+import (
+	"context"
+	"fmt"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterAPIControlPlaneLabel contains the label of upstream Cluster API control plane machines
+const clusterAPIControlPlaneLabel = "cluster.x-k8s.io/control-plane"
+
+// NewClient returns the client that serves machine.openshift.io machines for the machine API flavor,
+// under the Cluster API flavor it reads cluster.x-k8s.io machines with the reader, converts them to machine.openshift.io
+// machines and deletes cluster.x-k8s.io machines instead of them, so the code typed against machine.openshift.io
+// machines handles both flavors, other objects pass to the client as is
+func NewClient(c client.Client, reader client.Reader, flavor Flavor) client.Client {
+	if flavor != FlavorClusterAPI {
+		return c
+	}
+	return &clusterAPIClient{
+		Client: c,
+		reader: reader,
+	}
+}
+
+// clusterAPIClient serves machine.openshift.io machines from upstream Cluster API machines
+type clusterAPIClient struct {
+	client.Client
+	// reader reads Cluster API machines and Metal3Machines, it should be the manager cache,
+	// because the default manager client reads unstructured objects directly from the API server
+	reader client.Reader
+}
+
+// Get retrieves the machine.openshift.io machine converted from the Cluster API machine, or any other object
+func (c *clusterAPIClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	machine, ok := obj.(*mapiv1.Machine)
+	if !ok {
+		return c.Client.Get(ctx, key, obj)
+	}
+
+	u := newUnstructured(ClusterAPIMachineGVK)
+	if err := c.reader.Get(ctx, key, u); err != nil {
+		return err
+	}
+
+	var metal3Machine *unstructured.Unstructured
+	if ref := getInfrastructureRef(u); ref != nil && ref.Kind == Metal3MachineGVK.Kind {
+		metal3Machine = newUnstructured(Metal3MachineGVK)
+		metal3MachineKey := types.NamespacedName{Namespace: u.GetNamespace(), Name: ref.Name}
+		if err := c.reader.Get(ctx, metal3MachineKey, metal3Machine); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			metal3Machine = nil
+		}
+	}
+
+	converted, err := ConvertMachine(u, metal3Machine)
+	if err != nil {
+		return err
+	}
+	converted.DeepCopyInto(machine)
+	return nil
+}
+
+// List retrieves machine.openshift.io machines converted from Cluster API machines, or any other list,
+// the API server does not support field selectors of machine fields, so listed machines are filtered
+// by field indexers of machine field indexes
+func (c *clusterAPIClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	machines, ok := list.(*mapiv1.MachineList)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	fieldSelector := listOpts.FieldSelector
+	listOpts.FieldSelector = nil
+
+	u := &unstructured.UnstructuredList{}
+	u.SetGroupVersionKind(ClusterAPIMachineListGVK)
+	if err := c.reader.List(ctx, u, listOpts); err != nil {
+		return err
+	}
+
+	metal3MachinesList := &unstructured.UnstructuredList{}
+	metal3MachinesList.SetGroupVersionKind(Metal3MachineGVK.GroupVersion().WithKind(Metal3MachineGVK.Kind + "List"))
+	if err := c.reader.List(ctx, metal3MachinesList, client.InNamespace(listOpts.Namespace)); err != nil {
+		return err
+	}
+	metal3Machines := map[types.NamespacedName]*unstructured.Unstructured{}
+	for i := range metal3MachinesList.Items {
+		metal3Machine := &metal3MachinesList.Items[i]
+		metal3Machines[types.NamespacedName{Namespace: metal3Machine.GetNamespace(), Name: metal3Machine.GetName()}] = metal3Machine
+	}
+
+	machines.Items = nil
+	for i := range u.Items {
+		var metal3Machine *unstructured.Unstructured
+		if ref := getInfrastructureRef(&u.Items[i]); ref != nil && ref.Kind == Metal3MachineGVK.Kind {
+			metal3Machine = metal3Machines[types.NamespacedName{Namespace: u.Items[i].GetNamespace(), Name: ref.Name}]
+		}
+
+		machine, err := ConvertMachine(&u.Items[i], metal3Machine)
+		if err != nil {
+			return err
+		}
+
+		matches, err := matchesFields(machine, fieldSelector)
+		if err != nil {
+			return err
+		}
+		if matches {
+			machines.Items = append(machines.Items, *machine)
+		}
+	}
+	return nil
+}
+
+// Delete deletes the Cluster API machine of the machine.openshift.io machine, or any other object
+func (c *clusterAPIClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	machine, ok := obj.(*mapiv1.Machine)
+	if !ok {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+
+	u := newUnstructured(ClusterAPIMachineGVK)
+	u.SetNamespace(machine.Namespace)
+	u.SetName(machine.Name)
+	return c.Client.Delete(ctx, u, opts...)
+}
+
+// Create creates the object, machines are read-only under the Cluster API flavor
+func (c *clusterAPIClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*mapiv1.Machine); ok {
+		return fmt.Errorf("create of machines is not supported under the %s machine API", FlavorClusterAPI)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Update updates the object, machines are read-only under the Cluster API flavor
+func (c *clusterAPIClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*mapiv1.Machine); ok {
+		return fmt.Errorf("update of machines is not supported under the %s machine API", FlavorClusterAPI)
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch patches the object, machines are read-only under the Cluster API flavor
+func (c *clusterAPIClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*mapiv1.Machine); ok {
+		return fmt.Errorf("patch of machines is not supported under the %s machine API", FlavorClusterAPI)
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// ConvertMachine converts the Cluster API machine to the machine.openshift.io machine, the machine keeps
+// the metadata, the provider ID, the node reference and addresses, and gets the bare metal host annotation
+// of its Metal3Machine, that has the same key as the annotation of machine.openshift.io machines,
+// the control plane machine gets the master role label
+func ConvertMachine(u *unstructured.Unstructured, metal3Machine *unstructured.Unstructured) (*mapiv1.Machine, error) {
+	machine := &mapiv1.Machine{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), machine); err != nil {
+		return nil, fmt.Errorf("failed to convert machine %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+	}
+
+	if metal3Machine != nil {
+		if bmhKey, ok := metal3Machine.GetAnnotations()[consts.AnnotationBareMetalHost]; ok {
+			if machine.Annotations == nil {
+				machine.Annotations = map[string]string{}
+			}
+			machine.Annotations[consts.AnnotationBareMetalHost] = bmhKey
+		}
+	}
+
+	if _, ok := machine.Labels[clusterAPIControlPlaneLabel]; ok {
+		machine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+	}
+	return machine, nil
+}
+
+// GetMachineByConsumerRef returns the machine that consumes the bare metal host under the host consumer reference,
+// under the Cluster API flavor the host consumer is the Metal3Machine owned by the machine, so it returns nil
+// when the Metal3Machine does not have the machine owner yet
+func GetMachineByConsumerRef(c client.Client, ref *corev1.ObjectReference) (*mapiv1.Machine, error) {
+	key := types.NamespacedName{
+		Namespace: ref.Namespace,
+		Name:      ref.Name,
+	}
+
+	if ref.Kind == Metal3MachineGVK.Kind {
+		metal3Machine := newUnstructured(Metal3MachineGVK)
+		if err := c.Get(context.TODO(), key, metal3Machine); err != nil {
+			return nil, err
+		}
+
+		owner := GetMachineOwner(metal3Machine)
+		if owner == nil {
+			return nil, nil
+		}
+		key.Name = owner.Name
+	}
+
+	machine := &mapiv1.Machine{}
+	if err := c.Get(context.TODO(), key, machine); err != nil {
+		return nil, err
+	}
+	return machine, nil
+}
+
+// NewMachineObject returns the empty machine object of the machine API flavor, that controllers watch machines with,
+// under the Cluster API flavor it is the unstructured Cluster API machine
+func NewMachineObject(flavor Flavor) runtime.Object {
+	if flavor == FlavorClusterAPI {
+		return newUnstructured(ClusterAPIMachineGVK)
+	}
+	return &mapiv1.Machine{}
+}
+
+func newUnstructured(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+// getInfrastructureRef returns the infrastructure machine reference of the Cluster API machine
+func getInfrastructureRef(u *unstructured.Unstructured) *corev1.ObjectReference {
+	ref, ok, err := unstructured.NestedMap(u.UnstructuredContent(), "spec", "infrastructureRef")
+	if err != nil || !ok {
+		return nil
+	}
+
+	infrastructureRef := &corev1.ObjectReference{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(ref, infrastructureRef); err != nil {
+		return nil
+	}
+	return infrastructureRef
+}
+
+// GetMachineOwner returns the owner reference of the Cluster API machine, that owns the infrastructure machine,
+// or the machine remediation created by the MachineHealthCheck from the remediation template
+func GetMachineOwner(obj metav1.Object) *metav1.OwnerReference {
+	for _, owner := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(owner.APIVersion)
+		if err != nil {
+			continue
+		}
+		if gv.Group == ClusterAPIMachineGVK.Group && owner.Kind == ClusterAPIMachineGVK.Kind {
+			return &owner
+		}
+	}
+	return nil
+}
+
+// matchesFields returns true when the machine has field values required by the field selector,
+// machine fields are extracted by field indexers of machine field indexes
+func matchesFields(machine *mapiv1.Machine, selector fields.Selector) (bool, error) {
+	if selector == nil || selector.Empty() {
+		return true, nil
+	}
+
+	for _, requirement := range selector.Requirements() {
+		indexer, ok := indexes.MachineIndexers[requirement.Field]
+		if !ok {
+			return false, fmt.Errorf("unsupported machine field selector %q", requirement.Field)
+		}
+
+		found := false
+		for _, value := range indexer(machine) {
+			if value == requirement.Value {
+				found = true
+				break
+			}
+		}
+		if found != (requirement.Operator != selection.NotEquals) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
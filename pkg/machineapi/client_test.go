@@ -0,0 +1,187 @@
+package machineapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "metal3"
+
+// unstructuredReader lists unstructured objects from the slice, because the fake client
+// can not list unstructured objects of kinds unknown to the scheme
+type unstructuredReader struct {
+	client.Reader
+	objects []runtime.Object
+}
+
+func (r *unstructuredReader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	u, ok := list.(*unstructured.UnstructuredList)
+	if !ok {
+		return r.Reader.List(ctx, list, opts...)
+	}
+
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	gvk := u.GroupVersionKind()
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	for _, obj := range r.objects {
+		item := obj.(*unstructured.Unstructured)
+		if item.GroupVersionKind() != gvk || (listOpts.Namespace != "" && item.GetNamespace() != listOpts.Namespace) {
+			continue
+		}
+		u.Items = append(u.Items, *item.DeepCopy())
+	}
+	return nil
+}
+
+// newFakeClient returns the Cluster API client with the fake client that has objects
+func newFakeClient(objects ...runtime.Object) (client.Client, client.Client) {
+	fakeClient := fake.NewFakeClient(objects...)
+	return NewClient(fakeClient, &unstructuredReader{Reader: fakeClient, objects: objects}, FlavorClusterAPI), fakeClient
+}
+
+func newClusterAPIMachine(name string, nodeName string, metal3MachineName string) *unstructured.Unstructured {
+	u := newUnstructured(ClusterAPIMachineGVK)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetLabels(map[string]string{"cluster.x-k8s.io/cluster-name": "cluster"})
+	u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "MachineSet", Name: "workers", UID: "1"}})
+	unstructured.SetNestedField(u.Object, "metal3://"+name, "spec", "providerID")
+	unstructured.SetNestedMap(u.Object, map[string]interface{}{
+		"apiVersion": Metal3MachineGVK.GroupVersion().String(),
+		"kind":       Metal3MachineGVK.Kind,
+		"name":       metal3MachineName,
+	}, "spec", "infrastructureRef")
+	if nodeName != "" {
+		unstructured.SetNestedMap(u.Object, map[string]interface{}{"kind": "Node", "name": nodeName}, "status", "nodeRef")
+	}
+	unstructured.SetNestedField(u.Object, "Running", "status", "phase")
+	return u
+}
+
+func newMetal3Machine(name string, machineName string, bmhName string) *unstructured.Unstructured {
+	u := newUnstructured(Metal3MachineGVK)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetAnnotations(map[string]string{consts.AnnotationBareMetalHost: namespace + "/" + bmhName})
+	if machineName != "" {
+		u.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Machine", Name: machineName, UID: "2"}})
+	}
+	return u
+}
+
+func TestClusterAPIClient(t *testing.T) {
+	controlPlane := newClusterAPIMachine("control-plane-0", "node-0", "control-plane-0-m3")
+	controlPlane.SetLabels(map[string]string{clusterAPIControlPlaneLabel: ""})
+	worker := newClusterAPIMachine("worker-0", "node-1", "worker-0-m3")
+
+	c, fakeClient := newFakeClient(
+		controlPlane,
+		newMetal3Machine("control-plane-0-m3", controlPlane.GetName(), "host-0"),
+		worker,
+		newMetal3Machine("worker-0-m3", worker.GetName(), "host-1"),
+	)
+
+	machine := &mapiv1.Machine{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "worker-0"}, machine); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if machine.Annotations[consts.AnnotationBareMetalHost] != namespace+"/host-1" {
+		t.Errorf("Expected: the bare metal host annotation of the Metal3Machine, got: %v", machine.Annotations)
+	}
+	if machine.Status.NodeRef == nil || machine.Status.NodeRef.Name != "node-1" {
+		t.Errorf("Expected: the node reference of node-1, got: %v", machine.Status.NodeRef)
+	}
+	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID != "metal3://worker-0" {
+		t.Errorf("Expected: the provider ID of the machine, got: %v", machine.Spec.ProviderID)
+	}
+	if len(machine.OwnerReferences) != 1 || machine.OwnerReferences[0].Kind != "MachineSet" {
+		t.Errorf("Expected: the MachineSet owner, got: %v", machine.OwnerReferences)
+	}
+
+	machines := &mapiv1.MachineList{}
+	if err := c.List(context.TODO(), machines, client.MatchingField(indexes.MachineNodeNameField, "node-0")); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if len(machines.Items) != 1 || machines.Items[0].Name != "control-plane-0" {
+		t.Fatalf("Expected: only the control plane machine, got: %v", machines.Items)
+	}
+	if machines.Items[0].Labels[consts.MachineRoleLabel] != consts.MachineRoleMaster {
+		t.Errorf("Expected: the master role label of the control plane machine, got: %v", machines.Items[0].Labels)
+	}
+	if machines.Items[0].Annotations[consts.AnnotationBareMetalHost] != namespace+"/host-0" {
+		t.Errorf("Expected: the bare metal host annotation of the Metal3Machine, got: %v", machines.Items[0].Annotations)
+	}
+
+	if err := c.Delete(context.TODO(), machine); err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	deleted := newUnstructured(ClusterAPIMachineGVK)
+	if err := fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "worker-0"}, deleted); !errors.IsNotFound(err) {
+		t.Errorf("Expected: the Cluster API machine deleted, got: %v", err)
+	}
+
+	if err := c.Update(context.TODO(), &machines.Items[0]); err == nil {
+		t.Errorf("Expected: error on the machine update, got: nil")
+	}
+}
+
+func TestGetMachineByConsumerRef(t *testing.T) {
+	machine := newClusterAPIMachine("worker-0", "node-0", "worker-0-m3")
+	c, _ := newFakeClient(
+		machine,
+		newMetal3Machine("worker-0-m3", machine.GetName(), "host-0"),
+		newMetal3Machine("worker-1-m3", "", "host-1"),
+	)
+
+	testsCases := []struct {
+		name            string
+		consumerRef     *corev1.ObjectReference
+		expectedMachine string
+	}{
+		{
+			name:            "with Metal3Machine owned by machine",
+			consumerRef:     &corev1.ObjectReference{Kind: Metal3MachineGVK.Kind, Namespace: namespace, Name: "worker-0-m3"},
+			expectedMachine: machine.GetName(),
+		},
+		{
+			name:        "with Metal3Machine without owner",
+			consumerRef: &corev1.ObjectReference{Kind: Metal3MachineGVK.Kind, Namespace: namespace, Name: "worker-1-m3"},
+		},
+		{
+			name:            "with machine",
+			consumerRef:     &corev1.ObjectReference{Kind: "Machine", Namespace: namespace, Name: machine.GetName()},
+			expectedMachine: machine.GetName(),
+		},
+	}
+
+	for _, tc := range testsCases {
+		consumer, err := GetMachineByConsumerRef(c, tc.consumerRef)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			continue
+		}
+
+		var name string
+		if consumer != nil {
+			name = consumer.Name
+		}
+		if name != tc.expectedMachine {
+			t.Errorf("Test case: %s. Expected machine: %q, got: %q", tc.name, tc.expectedMachine, name)
+		}
+	}
+}
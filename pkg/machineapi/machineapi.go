@@ -0,0 +1,80 @@
+package machineapi
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+)
+
+// Flavor contains the machine API flavor of the cluster
+type Flavor string
+
+const (
+	// FlavorOpenShift is the OpenShift machine API under the machine.openshift.io group
+	FlavorOpenShift Flavor = "openshift"
+	// FlavorClusterAPI is the upstream Cluster API under the cluster.x-k8s.io group,
+	// with the Metal3Machine infrastructure provider that links machines to bare metal hosts
+	FlavorClusterAPI Flavor = "cluster-api"
+)
+
+var (
+	// ClusterAPIMachineGVK contains the group version kind of the upstream Cluster API machine
+	ClusterAPIMachineGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1alpha3", Kind: "Machine"}
+	// ClusterAPIMachineListGVK contains the group version kind of the upstream Cluster API machine list
+	ClusterAPIMachineListGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1alpha3", Kind: "MachineList"}
+	// Metal3MachineGVK contains the group version kind of the Metal3Machine, the infrastructure machine
+	// of the upstream Cluster API machine, that consumes the bare metal host
+	Metal3MachineGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha3", Kind: "Metal3Machine"}
+)
+
+// ParseFlavor returns the machine API flavor by its name
+func ParseFlavor(name string) (Flavor, error) {
+	switch flavor := Flavor(name); flavor {
+	case FlavorOpenShift, FlavorClusterAPI:
+		return flavor, nil
+	}
+	return "", fmt.Errorf("unknown machine API flavor %q, supported flavors are %q and %q", name, FlavorOpenShift, FlavorClusterAPI)
+}
+
+// DetectFlavor returns the machine API flavor by API groups that the API server serves, it prefers
+// the OpenShift machine API, when the server serves both of them
+func DetectFlavor(client discovery.DiscoveryInterface) (Flavor, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return "", err
+	}
+
+	served := sets.NewString()
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served.Insert(version.GroupVersion)
+		}
+	}
+
+	candidates := []struct {
+		flavor       Flavor
+		groupVersion schema.GroupVersion
+	}{
+		{
+			flavor:       FlavorOpenShift,
+			groupVersion: mapiv1.SchemeGroupVersion,
+		},
+		{
+			flavor:       FlavorClusterAPI,
+			groupVersion: ClusterAPIMachineGVK.GroupVersion(),
+		},
+	}
+	for _, candidate := range candidates {
+		if served.Has(candidate.groupVersion.String()) {
+			glog.Infof("Detected %s machine API under %s", candidate.flavor, candidate.groupVersion)
+			return candidate.flavor, nil
+		}
+	}
+	return "", fmt.Errorf("the API server does not serve machines under %s or %s", mapiv1.SchemeGroupVersion, ClusterAPIMachineGVK.GroupVersion())
+}
@@ -0,0 +1,65 @@
+package machineapi
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestDetectFlavor(t *testing.T) {
+	testsCases := []struct {
+		name           string
+		groupVersions  []string
+		expectedFlavor Flavor
+		expectedError  bool
+	}{
+		{
+			name:           "with OpenShift machine API",
+			groupVersions:  []string{"v1", "machine.openshift.io/v1beta1"},
+			expectedFlavor: FlavorOpenShift,
+		},
+		{
+			name:           "with upstream Cluster API",
+			groupVersions:  []string{"v1", "cluster.x-k8s.io/v1alpha3", "infrastructure.cluster.x-k8s.io/v1alpha3"},
+			expectedFlavor: FlavorClusterAPI,
+		},
+		{
+			name:           "with both machine APIs",
+			groupVersions:  []string{"cluster.x-k8s.io/v1alpha3", "machine.openshift.io/v1beta1"},
+			expectedFlavor: FlavorOpenShift,
+		},
+		{
+			name:          "without machine API",
+			groupVersions: []string{"v1"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+		for _, groupVersion := range tc.groupVersions {
+			discovery.Resources = append(discovery.Resources, &metav1.APIResourceList{GroupVersion: groupVersion})
+		}
+
+		flavor, err := DetectFlavor(discovery)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.name, tc.expectedError, err)
+		}
+
+		if flavor != tc.expectedFlavor {
+			t.Errorf("Test case: %s. Expected flavor: %q, got: %q", tc.name, tc.expectedFlavor, flavor)
+		}
+	}
+}
+
+func TestParseFlavor(t *testing.T) {
+	if flavor, err := ParseFlavor("cluster-api"); err != nil || flavor != FlavorClusterAPI {
+		t.Errorf("Expected: %q flavor, got: %q, %v", FlavorClusterAPI, flavor, err)
+	}
+
+	if _, err := ParseFlavor("unknown"); err == nil {
+		t.Errorf("Expected: error on the unknown flavor, got: nil")
+	}
+}
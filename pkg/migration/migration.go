@@ -0,0 +1,125 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// CRDMachineRemediation contains the name of the MachineRemediation CRD
+	CRDMachineRemediation = "machineremediations.machineremediation.kubevirt.io"
+	// ConversionWebhookPath contains the path that the conversion webhook serves
+	ConversionWebhookPath = "/convert"
+
+	// annotationInjectCABundle contains the annotation that makes the OpenShift service CA operator
+	// to inject the CA bundle into the conversion webhook client config of the CRD
+	annotationInjectCABundle = "service.beta.openshift.io/inject-cabundle"
+)
+
+// EnsureConversionWebhook configures the MachineRemediation CRD to convert objects between versions
+// by the conversion webhook behind the service
+func EnsureConversionWebhook(c client.Client, service types.NamespacedName) error {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: CRDMachineRemediation}, crd); err != nil {
+		return err
+	}
+
+	// Copy the CRD object to prevent modification of the original one
+	crdCopy := crd.DeepCopy()
+
+	if crdCopy.Annotations == nil {
+		crdCopy.Annotations = map[string]string{}
+	}
+	crdCopy.Annotations[annotationInjectCABundle] = "true"
+
+	// the webhook conversion requires the pruning of unknown fields
+	crdCopy.Spec.PreserveUnknownFields = pointer.BoolPtr(false)
+
+	// keep the CA bundle that was injected by the service CA operator
+	var caBundle []byte
+	if crd.Spec.Conversion != nil && crd.Spec.Conversion.WebhookClientConfig != nil {
+		caBundle = crd.Spec.Conversion.WebhookClientConfig.CABundle
+	}
+	crdCopy.Spec.Conversion = &apiextensionsv1beta1.CustomResourceConversion{
+		Strategy: apiextensionsv1beta1.WebhookConverter,
+		WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+			Service: &apiextensionsv1beta1.ServiceReference{
+				Namespace: service.Namespace,
+				Name:      service.Name,
+				Path:      pointer.StringPtr(ConversionWebhookPath),
+			},
+			CABundle: caBundle,
+		},
+		ConversionReviewVersions: []string{"v1beta1"},
+	}
+
+	if reflect.DeepEqual(crd, crdCopy) {
+		return nil
+	}
+
+	glog.Infof("Configure the conversion webhook of CRD %q with service %q", crd.Name, service)
+	return c.Update(context.TODO(), crdCopy)
+}
+
+// MigrateStorageVersion rewrites all MachineRemediation objects in the storage version of the CRD and
+// removes other versions from stored versions of the CRD, so they can be dropped from the CRD later
+func MigrateStorageVersion(c client.Client) error {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: CRDMachineRemediation}, crd); err != nil {
+		return err
+	}
+
+	storageVersion := getStorageVersion(crd)
+	if storageVersion == "" {
+		return fmt.Errorf("CRD %q does not have storage version", crd.Name)
+	}
+
+	if reflect.DeepEqual(crd.Status.StoredVersions, []string{storageVersion}) {
+		glog.V(4).Infof("CRD %q objects already stored in version %q", crd.Name, storageVersion)
+		return nil
+	}
+
+	mrs := &mrv1.MachineRemediationList{}
+	if err := c.List(context.TODO(), mrs); err != nil {
+		return err
+	}
+
+	// the update without changes makes the API server to write the object in the storage version
+	for i := range mrs.Items {
+		mr := &mrs.Items[i]
+		if err := c.Update(context.TODO(), mr); err != nil {
+			// the object was deleted or updated by someone else, so it already has the storage version
+			if errors.IsNotFound(err) || errors.IsConflict(err) {
+				continue
+			}
+			return err
+		}
+	}
+	glog.Infof("Migrated %d MachineRemediation objects to the storage version %q", len(mrs.Items), storageVersion)
+
+	crdCopy := crd.DeepCopy()
+	crdCopy.Status.StoredVersions = []string{storageVersion}
+	return c.Status().Update(context.TODO(), crdCopy)
+}
+
+// getStorageVersion returns the version that the CRD uses to store objects
+func getStorageVersion(crd *apiextensionsv1beta1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return crd.Spec.Version
+}
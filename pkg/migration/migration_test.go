@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	apiextensionsv1beta1.AddToScheme(scheme.Scheme)
+}
+
+func newCRD(storedVersions []string, conversion *apiextensionsv1beta1.CustomResourceConversion) *apiextensionsv1beta1.CustomResourceDefinition {
+	return &apiextensionsv1beta1.CustomResourceDefinition{
+		TypeMeta:   metav1.TypeMeta{Kind: "CustomResourceDefinition"},
+		ObjectMeta: metav1.ObjectMeta{Name: CRDMachineRemediation},
+		Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1beta1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Served: true},
+				{Name: "v1beta1", Served: true, Storage: true},
+			},
+			Conversion: conversion,
+		},
+		Status: apiextensionsv1beta1.CustomResourceDefinitionStatus{
+			StoredVersions: storedVersions,
+		},
+	}
+}
+
+func TestEnsureConversionWebhook(t *testing.T) {
+	service := types.NamespacedName{Namespace: "default", Name: "webhook"}
+
+	testCases := []struct {
+		name             string
+		crd              *apiextensionsv1beta1.CustomResourceDefinition
+		expectedCABundle []byte
+	}{
+		{
+			name:             "with CRD without conversion",
+			crd:              newCRD(nil, nil),
+			expectedCABundle: nil,
+		},
+		{
+			name: "with CRD that has injected CA bundle",
+			crd: newCRD(nil, &apiextensionsv1beta1.CustomResourceConversion{
+				Strategy: apiextensionsv1beta1.WebhookConverter,
+				WebhookClientConfig: &apiextensionsv1beta1.WebhookClientConfig{
+					CABundle: []byte("ca"),
+				},
+			}),
+			expectedCABundle: []byte("ca"),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(tc.crd)
+		if err := EnsureConversionWebhook(c, service); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: CRDMachineRemediation}, crd); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if crd.Spec.PreserveUnknownFields == nil || *crd.Spec.PreserveUnknownFields {
+			t.Errorf("%s failed, expected CRD to prune unknown fields", tc.name)
+		}
+
+		if crd.Annotations[annotationInjectCABundle] != "true" {
+			t.Errorf("%s failed, expected CRD to have %q annotation", tc.name, annotationInjectCABundle)
+		}
+
+		conversion := crd.Spec.Conversion
+		if conversion == nil || conversion.Strategy != apiextensionsv1beta1.WebhookConverter {
+			t.Errorf("%s failed, expected webhook conversion, got: %v", tc.name, conversion)
+			continue
+		}
+
+		serviceReference := conversion.WebhookClientConfig.Service
+		if serviceReference == nil || serviceReference.Name != service.Name || serviceReference.Namespace != service.Namespace {
+			t.Errorf("%s failed, expected service %q, got: %v", tc.name, service, serviceReference)
+		}
+
+		if !reflect.DeepEqual(conversion.WebhookClientConfig.CABundle, tc.expectedCABundle) {
+			t.Errorf("%s failed, expected CA bundle: %q, got: %q", tc.name, tc.expectedCABundle, conversion.WebhookClientConfig.CABundle)
+		}
+	}
+}
+
+func TestMigrateStorageVersion(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		crd                    *apiextensionsv1beta1.CustomResourceDefinition
+		expectedStoredVersions []string
+	}{
+		{
+			name:                   "with objects stored in old version",
+			crd:                    newCRD([]string{"v1alpha1", "v1beta1"}, nil),
+			expectedStoredVersions: []string{"v1beta1"},
+		},
+		{
+			name:                   "with objects stored in storage version",
+			crd:                    newCRD([]string{"v1beta1"}, nil),
+			expectedStoredVersions: []string{"v1beta1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		objects := []runtime.Object{
+			tc.crd,
+			mrtesting.NewMachineRemediation("mr1", "machine1", mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			mrtesting.NewMachineRemediation("mr2", "machine2", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+		}
+		c := fake.NewFakeClient(objects...)
+		if err := MigrateStorageVersion(c); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: CRDMachineRemediation}, crd); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if !reflect.DeepEqual(crd.Status.StoredVersions, tc.expectedStoredVersions) {
+			t.Errorf("%s failed, expected stored versions: %v, got: %v", tc.name, tc.expectedStoredVersions, crd.Status.StoredVersions)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package redfish
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SystemsPath contains the path of the Redfish systems collection
+	SystemsPath = "/redfish/v1/Systems"
+
+	// ResetActionName contains the name of the Redfish computer system reset action
+	ResetActionName = "#ComputerSystem.Reset"
+
+	defaultRequestTimeout = 30 * time.Second
+)
+
+// PowerState contains the power state of the computer system
+type PowerState string
+
+const (
+	// PowerStateOn contains the power state of the system that powered on
+	PowerStateOn PowerState = "On"
+	// PowerStateOff contains the power state of the system that powered off
+	PowerStateOff PowerState = "Off"
+	// PowerStatePoweringOn contains the power state of the system that powering on
+	PowerStatePoweringOn PowerState = "PoweringOn"
+	// PowerStatePoweringOff contains the power state of the system that powering off
+	PowerStatePoweringOff PowerState = "PoweringOff"
+)
+
+// ResetType contains the type of the computer system reset
+type ResetType string
+
+const (
+	// ResetTypeOn turns the system on
+	ResetTypeOn ResetType = "On"
+	// ResetTypeGracefulShutdown shuts down the system gracefully and powers it off
+	ResetTypeGracefulShutdown ResetType = "GracefulShutdown"
+	// ResetTypeForceOff turns the system off immediately
+	ResetTypeForceOff ResetType = "ForceOff"
+)
+
+// ComputerSystem contains the part of the Redfish computer system resource used by the client
+type ComputerSystem struct {
+	ID         string                  `json:"Id,omitempty"`
+	ODataID    string                  `json:"@odata.id,omitempty"`
+	PowerState PowerState              `json:"PowerState,omitempty"`
+	Actions    map[string]ActionTarget `json:"Actions,omitempty"`
+}
+
+// ActionTarget contains the target of the Redfish action
+type ActionTarget struct {
+	Target string `json:"target"`
+}
+
+// ResetRequest contains the body of the computer system reset action
+type ResetRequest struct {
+	ResetType ResetType `json:"ResetType"`
+}
+
+// Collection contains the Redfish resource collection
+type Collection struct {
+	Members []Link `json:"Members"`
+}
+
+// Link contains the reference to the Redfish resource
+type Link struct {
+	ODataID string `json:"@odata.id"`
+}
+
+// Client is the client to the Redfish computer system behind the BMC
+type Client struct {
+	endpoint   *url.URL
+	systemPath string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+// NewClient returns new Client for the BMC address in the format that the baremetal-operator uses,
+// for example redfish://host/redfish/v1/Systems/1 or redfish+http://host:8000/redfish/v1/Systems/1.
+// When the address does not contain the system path, the client uses the first system of the BMC.
+func NewClient(address string, username string, password string, insecure bool) (*Client, error) {
+	endpoint, systemPath, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: insecure,
+		},
+	}
+
+	return &Client{
+		endpoint:   endpoint,
+		systemPath: systemPath,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   defaultRequestTimeout,
+		},
+	}, nil
+}
+
+// ParseAddress returns the Redfish endpoint and the system path from the BMC address
+func ParseAddress(address string) (*url.URL, string, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse BMC address %q: %v", address, err)
+	}
+
+	endpoint := &url.URL{Host: u.Host}
+	switch u.Scheme {
+	case "redfish", "redfish+https":
+		endpoint.Scheme = "https"
+	case "redfish+http":
+		endpoint.Scheme = "http"
+	default:
+		return nil, "", fmt.Errorf("unsupported BMC address scheme %q", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, "", fmt.Errorf("BMC address %q does not have host", address)
+	}
+
+	systemPath := strings.TrimSuffix(u.Path, "/")
+	if systemPath == SystemsPath {
+		systemPath = ""
+	}
+	return endpoint, systemPath, nil
+}
+
+// GetSystem returns the computer system
+func (c *Client) GetSystem(ctx context.Context) (*ComputerSystem, error) {
+	systemPath, err := c.getSystemPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	system := &ComputerSystem{}
+	if err := c.do(ctx, http.MethodGet, systemPath, nil, system); err != nil {
+		return nil, err
+	}
+	return system, nil
+}
+
+// GetPowerState returns the power state of the computer system
+func (c *Client) GetPowerState(ctx context.Context) (PowerState, error) {
+	system, err := c.GetSystem(ctx)
+	if err != nil {
+		return "", err
+	}
+	return system.PowerState, nil
+}
+
+// Reset runs the reset action with the reset type on the computer system
+func (c *Client) Reset(ctx context.Context, resetType ResetType) error {
+	system, err := c.GetSystem(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := ""
+	if action, ok := system.Actions[ResetActionName]; ok {
+		target = action.Target
+	}
+	if target == "" {
+		target = system.ODataID + "/Actions/ComputerSystem.Reset"
+	}
+
+	return c.do(ctx, http.MethodPost, target, &ResetRequest{ResetType: resetType}, nil)
+}
+
+// getSystemPath returns the system path from the address or the path of the first system of the BMC
+func (c *Client) getSystemPath(ctx context.Context) (string, error) {
+	if c.systemPath != "" {
+		return c.systemPath, nil
+	}
+
+	systems := &Collection{}
+	if err := c.do(ctx, http.MethodGet, SystemsPath, nil, systems); err != nil {
+		return "", err
+	}
+	if len(systems.Members) == 0 {
+		return "", fmt.Errorf("BMC %q does not have computer systems", c.endpoint.Host)
+	}
+
+	c.systemPath = systems.Members[0].ODataID
+	return c.systemPath, nil
+}
+
+// do sends the request to the BMC and decodes the response into the out object
+func (c *Client) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	u := *c.endpoint
+	u.Path = path
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%s %s failed with status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
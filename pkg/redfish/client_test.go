@@ -0,0 +1,133 @@
+package redfish_test
+
+import (
+	"context"
+	"testing"
+
+	"kubevirt.io/machine-remediation/pkg/redfish"
+	"kubevirt.io/machine-remediation/pkg/redfish/simulator"
+)
+
+func TestParseAddress(t *testing.T) {
+	testsCases := []struct {
+		address            string
+		expectedEndpoint   string
+		expectedSystemPath string
+		expectedError      bool
+	}{
+		{
+			address:            "redfish://192.168.111.1/redfish/v1/Systems/1",
+			expectedEndpoint:   "https://192.168.111.1",
+			expectedSystemPath: "/redfish/v1/Systems/1",
+			expectedError:      false,
+		},
+		{
+			address:            "redfish+http://192.168.111.1:8000/redfish/v1/Systems/1/",
+			expectedEndpoint:   "http://192.168.111.1:8000",
+			expectedSystemPath: "/redfish/v1/Systems/1",
+			expectedError:      false,
+		},
+		{
+			address:            "redfish+https://192.168.111.1/redfish/v1/Systems",
+			expectedEndpoint:   "https://192.168.111.1",
+			expectedSystemPath: "",
+			expectedError:      false,
+		},
+		{
+			address:            "ipmi://192.168.111.1:6230",
+			expectedEndpoint:   "",
+			expectedSystemPath: "",
+			expectedError:      true,
+		},
+		{
+			address:            "redfish:///redfish/v1/Systems/1",
+			expectedEndpoint:   "",
+			expectedSystemPath: "",
+			expectedError:      true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		endpoint, systemPath, err := redfish.ParseAddress(tc.address)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.address, tc.expectedError, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if endpoint.String() != tc.expectedEndpoint {
+			t.Errorf("Test case: %s. Expected endpoint: %q, got: %q", tc.address, tc.expectedEndpoint, endpoint.String())
+		}
+
+		if systemPath != tc.expectedSystemPath {
+			t.Errorf("Test case: %s. Expected system path: %q, got: %q", tc.address, tc.expectedSystemPath, systemPath)
+		}
+	}
+}
+
+func TestClientReset(t *testing.T) {
+	s := simulator.NewSimulator("admin", "password")
+	defer s.Close()
+
+	c, err := redfish.NewClient(s.Address(), "admin", "password", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	powerState, err := c.GetPowerState(context.TODO())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if powerState != redfish.PowerStateOn {
+		t.Errorf("Expected power state %q, got: %q", redfish.PowerStateOn, powerState)
+	}
+
+	testsCases := []struct {
+		resetType          redfish.ResetType
+		expectedPowerState redfish.PowerState
+	}{
+		{
+			resetType:          redfish.ResetTypeGracefulShutdown,
+			expectedPowerState: redfish.PowerStateOff,
+		},
+		{
+			resetType:          redfish.ResetTypeOn,
+			expectedPowerState: redfish.PowerStateOn,
+		},
+		{
+			resetType:          redfish.ResetTypeForceOff,
+			expectedPowerState: redfish.PowerStateOff,
+		},
+	}
+
+	for _, tc := range testsCases {
+		if err := c.Reset(context.TODO(), tc.resetType); err != nil {
+			t.Errorf("Test case: %s. Expected no error, got: %v", tc.resetType, err)
+		}
+
+		powerState, err := c.GetPowerState(context.TODO())
+		if err != nil {
+			t.Errorf("Test case: %s. Expected no error, got: %v", tc.resetType, err)
+		}
+
+		if powerState != tc.expectedPowerState {
+			t.Errorf("Test case: %s. Expected power state %q, got: %q", tc.resetType, tc.expectedPowerState, powerState)
+		}
+	}
+}
+
+func TestClientWrongCredentials(t *testing.T) {
+	s := simulator.NewSimulator("admin", "password")
+	defer s.Close()
+
+	c, err := redfish.NewClient(s.Address(), "admin", "wrong", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if _, err := c.GetPowerState(context.TODO()); err == nil {
+		t.Errorf("Expected error on wrong credentials")
+	}
+}
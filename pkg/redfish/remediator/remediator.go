@@ -0,0 +1,499 @@
+package remediator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	bmremediator "kubevirt.io/machine-remediation/pkg/baremetal/remediator"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/redfish"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	"github.com/golang/glog"
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// gracefulPowerOffTimeout contains the time that the host has to power off gracefully,
+	// before the remediator will force the power off
+	gracefulPowerOffTimeout = 2 * time.Minute
+
+	secretUsernameKey = "username"
+	secretPasswordKey = "password"
+)
+
+// RedfishRemediator implements Remediator interface for bare metal machines,
+// it reboots hosts by talking directly to the Redfish BMC instead of waiting for the baremetal-operator
+type RedfishRemediator struct {
+	client   client.Client
+	recorder record.EventRecorder
+	// insecure disables the verification of the BMC certificate
+	insecure bool
+	// recreator recreates machines, because the recreate depends on the host provisioning by the baremetal-operator
+	recreator *bmremediator.BareMetalRemediator
+	// timeouts contains default timeouts of remediations
+	timeouts *timeouts.Defaults
+}
+
+// NewRedfishRemediator returns new RedfishRemediator object, the etcd client is passed to the recreate of machines
+// and can be nil
+func NewRedfishRemediator(mgr manager.Manager, insecure bool, etcdClient *etcd.Client, defaultTimeouts *timeouts.Defaults) *RedfishRemediator {
+	return &RedfishRemediator{
+		client:    mgr.GetClient(),
+		recorder:  mgr.GetEventRecorderFor("redfish-remediator"),
+		insecure:  insecure,
+		recreator: bmremediator.NewBareMetalRemediator(mgr, nil, nil, etcdClient, defaultTimeouts),
+		timeouts:  defaultTimeouts,
+	}
+}
+
+// Recreate recreates the bare metal machine under the cluster
+func (rr *RedfishRemediator) Recreate(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	return rr.recreator.Recreate(ctx, machineRemediation)
+}
+
+// Reboot reboots the bare metal machine via the Redfish BMC
+func (rr *RedfishRemediator) Reboot(ctx context.Context, machineRemediation *mrv1.MachineRemediation) error {
+	glog.V(4).Infof("MachineRemediation %q has state %q", machineRemediation.Name, machineRemediation.Status.State)
+
+	// Get the machine from the MachineRemediation
+	key := types.NamespacedName{
+		Namespace: machineRemediation.Namespace,
+		Name:      machineRemediation.Spec.MachineName,
+	}
+	machine := &mapiv1.Machine{}
+	if err := rr.client.Get(context.TODO(), key, machine); err != nil {
+		return err
+	}
+
+	// Get the bare metal host object
+	bmh, err := bmremediator.GetBareMetalHostByMachine(rr.client, machine)
+	if err != nil {
+		return err
+	}
+
+	// Copy the MachineRemediation object to prevent modification of the original one
+	mrCopy := machineRemediation.DeepCopy()
+
+	now := time.Now()
+	switch machineRemediation.Status.State {
+	// initiating the reboot action
+	case mrv1.RemediationStateStarted:
+		rebootInProgress := bmremediator.IsRebootInProgress(bmh)
+		// skip the reboot in case when the machine has power off state before the reboot action
+		// it can mean that an user power off the machine by purpose
+		if !bmh.Spec.Online && !rebootInProgress {
+			glog.V(4).Infof("Skip the remediation, machine %q has power off state before the remediation action", machine.Name)
+			rr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationSkippedOffline",
+				"Remediation of machine %q skipped because it was in power off state already",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateSucceeded
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSkippedOffline
+			mrCopy.Status.Message = "Skip the reboot, the machine power off by an user"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSkippedOffline,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+			return rr.client.Status().Update(context.TODO(), mrCopy)
+		}
+
+		// fail the remediation when the BMC address does not point to the Redfish BMC,
+		// for example an IPMI address, retries can not succeed until an user fixes the host
+		if bmh.Spec.BMC.Address != "" {
+			if _, _, err := redfish.ParseAddress(bmh.Spec.BMC.Address); err != nil {
+				return rr.failRebootUnsupported(machine, mrCopy, err, now)
+			}
+		}
+
+		// remove the forced power off annotation left by the previous attempt
+		if _, ok := mrCopy.Annotations[consts.AnnotationForcedPowerOff]; ok {
+			delete(mrCopy.Annotations, consts.AnnotationForcedPowerOff)
+			if err := rr.client.Update(context.TODO(), mrCopy); err != nil {
+				return err
+			}
+		}
+
+		bmc, err := rr.newBMCClient(bmh)
+		if err != nil {
+			return err
+		}
+
+		// power off the machine gracefully
+		glog.V(4).Infof("Power off gracefully machine %q", machine.Name)
+		if err := bmc.Reset(ctx, redfish.ResetTypeGracefulShutdown); err != nil {
+			return rr.reportBMCError(mrCopy, mrv1.MachineRemediationConditionFenced, err)
+		}
+
+		// Copy the BareMetalHost object to prevent modification of the original one
+		bmhCopy := bmh.DeepCopy()
+
+		// set the host offline the same way as the baremetal remediator does,
+		// so the baremetal-operator will not power on the host in the middle of the reboot
+		if !rebootInProgress {
+			if bmhCopy.Annotations == nil {
+				bmhCopy.Annotations = map[string]string{}
+			}
+			bmhCopy.Annotations[consts.AnnotationRebootInProgress] = "true"
+		}
+		bmhCopy.Spec.Online = false
+
+		if err := rr.client.Update(context.TODO(), bmhCopy); err != nil {
+			return err
+		}
+
+		rr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationRebootStarted",
+			"Reboot of machine %q has started",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStatePowerOff
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Starts the reboot process"
+		return rr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOff:
+		// failed the remediation on timeout
+		if phase := rr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return rr.failRebootOnTimeout(machine, mrCopy, phase, now)
+		}
+
+		bmc, err := rr.newBMCClient(bmh)
+		if err != nil {
+			return err
+		}
+
+		powerState, err := bmc.GetPowerState(ctx)
+		if err != nil {
+			return rr.reportBMCError(mrCopy, mrv1.MachineRemediationConditionFenced, err)
+		}
+
+		// host still has state on, we need to reconcile
+		if powerState != redfish.PowerStateOff {
+			glog.Warningf("machine %q still has power state %q", machine.Name, powerState)
+
+			// the host did not power off gracefully in time, force the power off
+			gracefulPowerOffTimedOut := timeouts.GetStateTransitionTime(machineRemediation).Time.Add(gracefulPowerOffTimeout).Before(now)
+			if gracefulPowerOffTimedOut && !isForcedPowerOff(machineRemediation) {
+				glog.V(4).Infof("Force power off machine %q", machine.Name)
+				if err := bmc.Reset(ctx, redfish.ResetTypeForceOff); err != nil {
+					return rr.reportBMCError(mrCopy, mrv1.MachineRemediationConditionFenced, err)
+				}
+
+				if mrCopy.Annotations == nil {
+					mrCopy.Annotations = map[string]string{}
+				}
+				mrCopy.Annotations[consts.AnnotationForcedPowerOff] = getForcedPowerOffValue(mrCopy)
+				if err := rr.client.Update(context.TODO(), mrCopy); err != nil {
+					return err
+				}
+
+				rr.recorder.Eventf(
+					machine,
+					corev1.EventTypeWarning,
+					"MachineRemediationRebootForcedPowerOff",
+					"Machine %q did not power off gracefully, forcing power off",
+					machine.Name,
+				)
+			}
+			return nil
+		}
+
+		// delete the node to release workloads, once we are sure that host has state power off
+		if err := nodes.DeleteNodeByMachine(rr.client, machine); err != nil {
+			return err
+		}
+
+		// power on the machine
+		glog.V(4).Infof("Power on machine %q", machine.Name)
+
+		// Copy the BareMetalHost object to prevent modification of the original one
+		bmhCopy := bmh.DeepCopy()
+		bmhCopy.Spec.Online = true
+		delete(bmhCopy.Annotations, consts.AnnotationRebootInProgress)
+		if err := rr.client.Update(context.TODO(), bmhCopy); err != nil {
+			return err
+		}
+
+		if err := bmc.Reset(ctx, redfish.ResetTypeOn); err != nil {
+			return rr.reportBMCError(mrCopy, mrv1.MachineRemediationConditionPoweredOn, err)
+		}
+
+		rr.recorder.Eventf(
+			machine,
+			corev1.EventTypeNormal,
+			"MachineRemediationRebootPoweringOn",
+			"Powering on machine %q",
+			machine.Name,
+		)
+
+		mrCopy.Status.State = mrv1.RemediationStatePowerOn
+		mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+		mrCopy.Status.Reason = mrv1.RemediationReasonInProgress
+		mrCopy.Status.Message = "Reboot in progress"
+		conditions.SetMachineRemediationCondition(
+			mrCopy,
+			mrv1.MachineRemediationConditionFenced,
+			corev1.ConditionTrue,
+			mrv1.RemediationReasonInProgress,
+			mrCopy.Status.Message,
+		)
+		return rr.client.Status().Update(context.TODO(), mrCopy)
+
+	case mrv1.RemediationStatePowerOn:
+		// failed the remediation on timeout
+		if phase := rr.timeouts.Expired(machineRemediation, now); phase != "" {
+			return rr.failRebootOnTimeout(machine, mrCopy, phase, now)
+		}
+
+		// wait for the host boot, the node ready timeout is measured from the boot
+		if !conditions.MachineRemediationHasCondition(machineRemediation, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue) {
+			bmc, err := rr.newBMCClient(bmh)
+			if err != nil {
+				return err
+			}
+
+			powerState, err := bmc.GetPowerState(ctx)
+			if err != nil {
+				return rr.reportBMCError(mrCopy, mrv1.MachineRemediationConditionPoweredOn, err)
+			}
+
+			// host still has state off, we need to reconcile
+			if powerState != redfish.PowerStateOn {
+				glog.Warningf("machine %q still has power state %q", machine.Name, powerState)
+				return nil
+			}
+
+			glog.V(4).Infof("Machine %q powered on", machine.Name)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonInProgress,
+				"Host powered on",
+			)
+			return rr.client.Status().Update(context.TODO(), mrCopy)
+		}
+
+		node, err := nodes.GetNodeByMachine(rr.client, machine)
+		if err != nil {
+			// we want to reconcile with delay of 10 seconds when the machine does not have node reference
+			// or node does not exist
+			if errors.IsNotFound(err) {
+				glog.Warningf("The machine %q node does not exist", machine.Name)
+				return nil
+			}
+			return err
+		}
+
+		// Node back to Ready under the cluster, and it booted again
+		if conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) && nodes.IsRebooted(node, machineRemediation) {
+			glog.V(4).Infof("Remediation of machine %q succeeded", machine.Name)
+			nodeCopy := node.DeepCopy()
+			nodes.RestoreSavedMetadata(nodeCopy, machineRemediation)
+			delete(nodeCopy.Annotations, consts.AnnotationNodeMachineReboot)
+			if err := rr.client.Update(context.TODO(), nodeCopy); err != nil {
+				return err
+			}
+
+			glog.V(4).Infof("Reapplied labels, annotations and taints to node %q", node.Name)
+
+			rr.recorder.Eventf(
+				machine,
+				corev1.EventTypeNormal,
+				"MachineRemediationRebootSucceeded",
+				"Remediation of machine %q succeeded",
+				machine.Name,
+			)
+			mrCopy.Status.State = mrv1.RemediationStateSucceeded
+			mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+			mrCopy.Status.Reason = mrv1.RemediationReasonSucceeded
+			mrCopy.Status.Message = "Reboot succeeded"
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionPoweredOn,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionNodeReady,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			conditions.SetMachineRemediationCondition(
+				mrCopy,
+				mrv1.MachineRemediationConditionSucceeded,
+				corev1.ConditionTrue,
+				mrv1.RemediationReasonSucceeded,
+				mrCopy.Status.Message,
+			)
+			mrCopy.Status.EndTime = &metav1.Time{Time: now}
+			return rr.client.Status().Update(context.TODO(), mrCopy)
+		}
+		return nil
+
+	case mrv1.RemediationStateFailed:
+		node, err := nodes.GetNodeByMachine(rr.client, machine)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// remove the reboot annotation from the node, to initiate the reboot again
+		return nodes.RemoveRebootAnnotation(rr.client, node)
+	}
+	return nil
+}
+
+// failRebootOnTimeout moves the machine remediation to the failed state
+func (rr *RedfishRemediator) failRebootOnTimeout(machine *mapiv1.Machine, mrCopy *mrv1.MachineRemediation, phase timeouts.Phase, now time.Time) error {
+	glog.Errorf("Remediation of machine %q failed on %s timeout", machine.Name, phase)
+	rr.recorder.Eventf(
+		machine,
+		corev1.EventTypeWarning,
+		"MachineRemediationRebootTimedOut",
+		"Remediation of machine %q timed out, %s timeout expired",
+		machine.Name,
+		phase,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateFailed
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonTimedOut
+	mrCopy.Status.Message = fmt.Sprintf("Reboot failed on %s timeout", phase)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonTimedOut,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return rr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// failRebootUnsupported moves the machine remediation to the failed state, when the host BMC is not a Redfish BMC
+func (rr *RedfishRemediator) failRebootUnsupported(machine *mapiv1.Machine, mrCopy *mrv1.MachineRemediation, addressErr error, now time.Time) error {
+	glog.Errorf("Remediation of machine %q failed: %v", machine.Name, addressErr)
+	rr.recorder.Eventf(
+		machine,
+		corev1.EventTypeWarning,
+		"MachineRemediationRebootUnsupported",
+		"Remediation of machine %q failed, the host does not have a Redfish BMC: %v",
+		machine.Name,
+		addressErr,
+	)
+	mrCopy.Status.State = mrv1.RemediationStateFailed
+	mrCopy.Status.StateTransitionTime = &metav1.Time{Time: now}
+	mrCopy.Status.Reason = mrv1.RemediationReasonUnsupported
+	mrCopy.Status.Message = fmt.Sprintf("Reboot is not supported: %v", addressErr)
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		mrv1.MachineRemediationConditionSucceeded,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonUnsupported,
+		mrCopy.Status.Message,
+	)
+	mrCopy.Status.EndTime = &metav1.Time{Time: now}
+	return rr.client.Status().Update(context.TODO(), mrCopy)
+}
+
+// reportBMCError sets the condition of the machine remediation to false with the BMC error,
+// it returns the original error to retry the BMC operation
+func (rr *RedfishRemediator) reportBMCError(mrCopy *mrv1.MachineRemediation, conditionType mrv1.MachineRemediationConditionType, bmcErr error) error {
+	conditions.SetMachineRemediationCondition(
+		mrCopy,
+		conditionType,
+		corev1.ConditionFalse,
+		mrv1.RemediationReasonBMCError,
+		bmcErr.Error(),
+	)
+	if err := rr.client.Status().Update(context.TODO(), mrCopy); err != nil {
+		glog.Errorf("Failed to update MachineRemediation %q status: %v", mrCopy.Name, err)
+	}
+	return bmcErr
+}
+
+// newBMCClient returns the Redfish client for the BMC of the bare metal host
+func (rr *RedfishRemediator) newBMCClient(bmh *bmov1.BareMetalHost) (*redfish.Client, error) {
+	if bmh.Spec.BMC.Address == "" {
+		return nil, fmt.Errorf("bare metal host %q does not have BMC address", bmh.Name)
+	}
+
+	if bmh.Spec.BMC.CredentialsName == "" {
+		return nil, fmt.Errorf("bare metal host %q does not have BMC credentials", bmh.Name)
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{
+		Namespace: bmh.Namespace,
+		Name:      bmh.Spec.BMC.CredentialsName,
+	}
+	if err := rr.client.Get(context.TODO(), key, secret); err != nil {
+		return nil, err
+	}
+
+	username, ok := secret.Data[secretUsernameKey]
+	if !ok {
+		return nil, fmt.Errorf("BMC credentials secret %q does not have %q key", secret.Name, secretUsernameKey)
+	}
+
+	password, ok := secret.Data[secretPasswordKey]
+	if !ok {
+		return nil, fmt.Errorf("BMC credentials secret %q does not have %q key", secret.Name, secretPasswordKey)
+	}
+
+	return redfish.NewClient(bmh.Spec.BMC.Address, string(username), string(password), rr.insecure)
+}
+
+// isForcedPowerOff returns true when the remediator already forced the host power off during the current attempt
+func isForcedPowerOff(machineRemediation *mrv1.MachineRemediation) bool {
+	forcedPowerOff, ok := machineRemediation.Annotations[consts.AnnotationForcedPowerOff]
+	if !ok || forcedPowerOff != getForcedPowerOffValue(machineRemediation) {
+		return false
+	}
+	return true
+}
+
+// getForcedPowerOffValue returns the value of the forced power off annotation,
+// the annotation stores the start time of the attempt, so it does not affect next attempts
+func getForcedPowerOffValue(machineRemediation *mrv1.MachineRemediation) string {
+	startTime := strategies.GetAttemptStartTime(machineRemediation)
+	if startTime == nil {
+		return "true"
+	}
+	return startTime.UTC().Format(time.RFC3339)
+}
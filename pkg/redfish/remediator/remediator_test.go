@@ -0,0 +1,487 @@
+package remediator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/redfish"
+	"kubevirt.io/machine-remediation/pkg/redfish/simulator"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	bmcUsername = "admin"
+	bmcPassword = "password"
+)
+
+func init() {
+	// Add types to scheme
+	bmov1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func newFakeRedfishRemediator(recorder record.EventRecorder, objects ...runtime.Object) *RedfishRemediator {
+	fakeClient := fake.NewFakeClient(objects...)
+	return &RedfishRemediator{
+		client:   fakeClient,
+		recorder: recorder,
+		timeouts: timeouts.NewDefaults(),
+	}
+}
+
+func newBMCSecret(name string, username string, password string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+		},
+		Data: map[string][]byte{
+			secretUsernameKey: []byte(username),
+			secretPasswordKey: []byte(password),
+		},
+	}
+}
+
+func startedBefore(machineRemediation *mrv1.MachineRemediation, d time.Duration) *mrv1.MachineRemediation {
+	machineRemediation.Status.StartTime = &metav1.Time{
+		Time: machineRemediation.Status.StartTime.Time.Add(-d),
+	}
+	return machineRemediation
+}
+
+type expectedRemediationResult struct {
+	state                         mrv1.RemediationState
+	hasEndTime                    bool
+	powerState                    redfish.PowerState
+	resets                        []redfish.ResetType
+	nodeDeleted                   bool
+	machineRemediationDeleted     bool
+	forcedPowerOffAnnotationExist bool
+	hostOnline                    bool
+	error                         bool
+}
+
+func TestRemediationReboot(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	node.Annotations[consts.AnnotationNodeMachineReboot] = ""
+	node.Status.NodeInfo.BootID = "boot"
+	nodeNotReady := mrtesting.NewNode("nodeNotReady", false, "machineNotReady")
+
+	machine := mrtesting.NewMachine("machine", node.Name, "bareMetalHost")
+	machineOffline := mrtesting.NewMachine("machineOffline", node.Name, "bareMetalHostOffline")
+	machineWithoutCredentials := mrtesting.NewMachine("machineWithoutCredentials", node.Name, "bareMetalHostWithoutCredentials")
+	machineNotReady := mrtesting.NewMachine("machineNotReady", nodeNotReady.Name, "bareMetalHost")
+
+	machineRemediationForcedPowerOff := startedBefore(mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff), 3*time.Minute)
+	machineRemediationForcedPowerOff.Annotations = map[string]string{consts.AnnotationForcedPowerOff: getForcedPowerOffValue(machineRemediationForcedPowerOff)}
+	machineRemediationForcedPowerOffBefore := startedBefore(mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff), 3*time.Minute)
+	machineRemediationForcedPowerOffBefore.Annotations = map[string]string{consts.AnnotationForcedPowerOff: "2019-01-01T00:00:00Z"}
+	machineRemediationStartedAgain := mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
+	machineRemediationStartedAgain.Annotations = map[string]string{consts.AnnotationForcedPowerOff: "2019-01-01T00:00:00Z"}
+	machineRemediationRebooted := mrtesting.NewPoweredOnMachineRemediation("mr", machine.Name, time.Now())
+	machineRemediationRebooted.Status.NodeBootID = "bootBeforeReboot"
+
+	testCases := []struct {
+		name                   string
+		machineRemediation     *mrv1.MachineRemediation
+		bareMetalHost          string
+		online                 bool
+		rebootInProgress       bool
+		bmcAddress             string
+		powerState             redfish.PowerState
+		ignoreGracefulShutdown bool
+		node                   *corev1.Node
+		expected               expectedRemediationResult
+		expectedEvents         []string
+	}{
+		{
+			name:               "with machine remediation started and host has power off state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineOffline.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			bareMetalHost:      "bareMetalHostOffline",
+			online:             false,
+			powerState:         redfish.PowerStateOff,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateSucceeded,
+				hasEndTime: true,
+				powerState: redfish.PowerStateOff,
+				resets:     []redfish.ResetType{},
+				hostOnline: false,
+			},
+			expectedEvents: []string{"MachineRemediationSkippedOffline"},
+		},
+		{
+			name:               "with machine remediation started and host has power on state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOff,
+				powerState: redfish.PowerStateOff,
+				resets:     []redfish.ResetType{redfish.ResetTypeGracefulShutdown},
+				hostOnline: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation started and host that the remediator already set offline",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			bareMetalHost:      "bareMetalHost",
+			online:             false,
+			rebootInProgress:   true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOff,
+				powerState: redfish.PowerStateOff,
+				resets:     []redfish.ResetType{redfish.ResetTypeGracefulShutdown},
+				hostOnline: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation started again after the forced power off",
+			machineRemediation: machineRemediationStartedAgain,
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOff,
+				powerState: redfish.PowerStateOff,
+				resets:     []redfish.ResetType{redfish.ResetTypeGracefulShutdown},
+				hostOnline: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootStarted"},
+		},
+		{
+			name:               "with machine remediation started and host with IPMI BMC",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			bmcAddress:         "ipmi://192.168.111.1:6230",
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateFailed,
+				hasEndTime: true,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootUnsupported"},
+		},
+		{
+			name:               "with machine remediation started and host without BMC credentials",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineWithoutCredentials.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted),
+			bareMetalHost:      "bareMetalHostWithoutCredentials",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateStarted,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+				error:      true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power off state and host has power off state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			bareMetalHost:      "bareMetalHost",
+			online:             false,
+			rebootInProgress:   true,
+			powerState:         redfish.PowerStateOff,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:       mrv1.RemediationStatePowerOn,
+				powerState:  redfish.PowerStateOn,
+				resets:      []redfish.ResetType{redfish.ResetTypeOn},
+				nodeDeleted: true,
+				hostOnline:  true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootPoweringOn"},
+		},
+		{
+			name:                   "with machine remediation in power off state and host that still powering off gracefully",
+			machineRemediation:     mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			bareMetalHost:          "bareMetalHost",
+			online:                 false,
+			rebootInProgress:       true,
+			powerState:             redfish.PowerStateOn,
+			ignoreGracefulShutdown: true,
+			node:                   node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOff,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: false,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:                   "with machine remediation in power off state and host that did not power off gracefully in time",
+			machineRemediation:     startedBefore(mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff), 3*time.Minute),
+			bareMetalHost:          "bareMetalHost",
+			online:                 false,
+			rebootInProgress:       true,
+			powerState:             redfish.PowerStateOn,
+			ignoreGracefulShutdown: true,
+			node:                   node,
+			expected: expectedRemediationResult{
+				state:                         mrv1.RemediationStatePowerOff,
+				powerState:                    redfish.PowerStateOff,
+				resets:                        []redfish.ResetType{redfish.ResetTypeForceOff},
+				forcedPowerOffAnnotationExist: true,
+				hostOnline:                    false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootForcedPowerOff"},
+		},
+		{
+			name:                   "with machine remediation in power off state that already forced the power off",
+			machineRemediation:     machineRemediationForcedPowerOff,
+			bareMetalHost:          "bareMetalHost",
+			online:                 false,
+			rebootInProgress:       true,
+			powerState:             redfish.PowerStatePoweringOff,
+			ignoreGracefulShutdown: true,
+			node:                   node,
+			expected: expectedRemediationResult{
+				state:                         mrv1.RemediationStatePowerOff,
+				powerState:                    redfish.PowerStatePoweringOff,
+				resets:                        []redfish.ResetType{},
+				forcedPowerOffAnnotationExist: true,
+				hostOnline:                    false,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:                   "with machine remediation in power off state that forced the power off during the previous attempt",
+			machineRemediation:     machineRemediationForcedPowerOffBefore,
+			bareMetalHost:          "bareMetalHost",
+			online:                 false,
+			rebootInProgress:       true,
+			powerState:             redfish.PowerStateOn,
+			ignoreGracefulShutdown: true,
+			node:                   node,
+			expected: expectedRemediationResult{
+				state:                         mrv1.RemediationStatePowerOff,
+				powerState:                    redfish.PowerStateOff,
+				resets:                        []redfish.ResetType{redfish.ResetTypeForceOff},
+				forcedPowerOffAnnotationExist: true,
+				hostOnline:                    false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootForcedPowerOff"},
+		},
+		{
+			name:                   "with machine remediation in power off state that timed out",
+			machineRemediation:     startedBefore(mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff), 6*time.Minute),
+			bareMetalHost:          "bareMetalHost",
+			online:                 false,
+			rebootInProgress:       true,
+			powerState:             redfish.PowerStateOn,
+			ignoreGracefulShutdown: true,
+			node:                   node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateFailed,
+				hasEndTime: true,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: false,
+			},
+			expectedEvents: []string{"MachineRemediationRebootTimedOut"},
+		},
+		{
+			name:               "with machine remediation in power on state and ready node",
+			machineRemediation: machineRemediationRebooted,
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateSucceeded,
+				hasEndTime: true,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+			},
+			expectedEvents: []string{"MachineRemediationRebootSucceeded"},
+		},
+		{
+			name:               "with machine remediation in power on state and host that still has power off state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn),
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOff,
+			node:               nodeNotReady,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOn,
+				powerState: redfish.PowerStateOff,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in power on state and non ready node",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machineNotReady.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn),
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               nodeNotReady,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStatePowerOn,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+			},
+			expectedEvents: []string{},
+		},
+		{
+			name:               "with machine remediation in succeeded state",
+			machineRemediation: mrtesting.NewMachineRemediation("mr", machine.Name, mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded),
+			bareMetalHost:      "bareMetalHost",
+			online:             true,
+			powerState:         redfish.PowerStateOn,
+			node:               node,
+			expected: expectedRemediationResult{
+				state:      mrv1.RemediationStateSucceeded,
+				powerState: redfish.PowerStateOn,
+				resets:     []redfish.ResetType{},
+				hostOnline: true,
+			},
+			expectedEvents: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		s := simulator.NewSimulator(bmcUsername, bmcPassword)
+		s.SetPowerState(tc.powerState)
+		s.SetIgnoreGracefulShutdown(tc.ignoreGracefulShutdown)
+
+		bmh := mrtesting.NewBareMetalHost(tc.bareMetalHost, tc.online, tc.powerState == redfish.PowerStateOn)
+		bmh.Spec.BMC = bmov1.BMCDetails{
+			Address:         s.Address(),
+			CredentialsName: "bmc-secret",
+		}
+		if tc.bareMetalHost == "bareMetalHostWithoutCredentials" {
+			bmh.Spec.BMC.CredentialsName = "wrong-secret"
+		}
+		if tc.bmcAddress != "" {
+			bmh.Spec.BMC.Address = tc.bmcAddress
+		}
+		if tc.rebootInProgress {
+			bmh.Annotations[consts.AnnotationRebootInProgress] = "true"
+		}
+
+		recorder := record.NewFakeRecorder(10)
+		rr := newFakeRedfishRemediator(
+			recorder,
+			newBMCSecret("bmc-secret", bmcUsername, bmcPassword),
+			tc.node,
+			machine,
+			machineOffline,
+			machineWithoutCredentials,
+			machineNotReady,
+			bmh,
+			tc.machineRemediation,
+		)
+
+		err := rr.Reboot(context.TODO(), tc.machineRemediation)
+		if tc.expected.error != (err != nil) {
+			t.Errorf("%s failed, expected error: %t, got: %v", tc.name, tc.expected.error, err)
+		}
+
+		mrtesting.AssertEvents(t, tc.name, tc.expectedEvents, recorder.Events)
+
+		if s.PowerState() != tc.expected.powerState {
+			t.Errorf("%s failed, expected power state: %q, got: %q", tc.name, tc.expected.powerState, s.PowerState())
+		}
+
+		resets := s.Resets()
+		if !reflect.DeepEqual(resets, tc.expected.resets) {
+			t.Errorf("%s failed, expected resets: %v, got: %v", tc.name, tc.expected.resets, resets)
+		}
+		s.Close()
+
+		newMachineRemediation := &mrv1.MachineRemediation{}
+		key := types.NamespacedName{
+			Namespace: tc.machineRemediation.Namespace,
+			Name:      tc.machineRemediation.Name,
+		}
+		err = rr.client.Get(context.TODO(), key, newMachineRemediation)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.machineRemediationDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected machine remediation deleted: %t, got: %v", tc.name, tc.expected.machineRemediationDeleted, err)
+		}
+
+		if !tc.expected.machineRemediationDeleted {
+			if newMachineRemediation.Status.State != tc.expected.state {
+				t.Errorf("%s failed, expected MachineRemediation state: %s, got: %s", tc.name, tc.expected.state, newMachineRemediation.Status.State)
+			}
+
+			if tc.expected.hasEndTime != (newMachineRemediation.Status.EndTime != nil) {
+				t.Errorf("%s failed, expected endTime: %t, got: %v", tc.name, tc.expected.hasEndTime, newMachineRemediation.Status.EndTime)
+			}
+
+			_, ok := newMachineRemediation.Annotations[consts.AnnotationForcedPowerOff]
+			if ok != tc.expected.forcedPowerOffAnnotationExist || ok && !isForcedPowerOff(newMachineRemediation) {
+				t.Errorf("%s failed, expected %q annotation of the current attempt: %t", tc.name, consts.AnnotationForcedPowerOff, tc.expected.forcedPowerOffAnnotationExist)
+			}
+		}
+
+		newBareMetalHost := &bmov1.BareMetalHost{}
+		key = types.NamespacedName{
+			Namespace: bmh.Namespace,
+			Name:      bmh.Name,
+		}
+		if err := rr.client.Get(context.TODO(), key, newBareMetalHost); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if newBareMetalHost.Spec.Online != tc.expected.hostOnline {
+			t.Errorf("%s failed, expected host online: %t, got: %t", tc.name, tc.expected.hostOnline, newBareMetalHost.Spec.Online)
+		}
+
+		newNode := &corev1.Node{}
+		key = types.NamespacedName{
+			Namespace: tc.node.Namespace,
+			Name:      tc.node.Name,
+		}
+		err = rr.client.Get(context.TODO(), key, newNode)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if tc.expected.nodeDeleted != errors.IsNotFound(err) {
+			t.Errorf("%s failed, expected node deleted: %t, got: %v", tc.name, tc.expected.nodeDeleted, err)
+		}
+	}
+}
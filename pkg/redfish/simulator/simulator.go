@@ -0,0 +1,157 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"kubevirt.io/machine-remediation/pkg/redfish"
+)
+
+const (
+	// SystemID contains the ID of the computer system served by the simulator
+	SystemID = "1"
+)
+
+// Simulator is the local Redfish BMC, that serves single computer system and can be used for testing
+type Simulator struct {
+	server   *httptest.Server
+	username string
+	password string
+
+	lock                   sync.Mutex
+	powerState             redfish.PowerState
+	ignoreGracefulShutdown bool
+	resets                 []redfish.ResetType
+}
+
+// NewSimulator starts new Redfish simulator with the powered on computer system,
+// the simulator accepts only requests with the specified credentials
+func NewSimulator(username string, password string) *Simulator {
+	s := &Simulator{
+		username:   username,
+		password:   password,
+		powerState: redfish.PowerStateOn,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close stops the simulator
+func (s *Simulator) Close() {
+	s.server.Close()
+}
+
+// Address returns the BMC address of the simulated computer system
+// in the format that the baremetal-operator uses
+func (s *Simulator) Address() string {
+	return fmt.Sprintf("redfish+%s%s", s.server.URL, systemPath())
+}
+
+// PowerState returns the current power state of the computer system
+func (s *Simulator) PowerState() redfish.PowerState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.powerState
+}
+
+// SetPowerState sets the power state of the computer system
+func (s *Simulator) SetPowerState(powerState redfish.PowerState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.powerState = powerState
+}
+
+// SetIgnoreGracefulShutdown configures the computer system to ignore the graceful shutdown,
+// like the operating system that hangs
+func (s *Simulator) SetIgnoreGracefulShutdown(ignore bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ignoreGracefulShutdown = ignore
+}
+
+// Resets returns all reset actions that the computer system received
+func (s *Simulator) Resets() []redfish.ResetType {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]redfish.ResetType{}, s.resets...)
+}
+
+func systemPath() string {
+	return fmt.Sprintf("%s/%s", redfish.SystemsPath, SystemID)
+}
+
+func resetPath() string {
+	return fmt.Sprintf("%s/Actions/ComputerSystem.Reset", systemPath())
+}
+
+func (s *Simulator) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok || username != s.username || password != s.password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == redfish.SystemsPath && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, &redfish.Collection{
+			Members: []redfish.Link{{ODataID: systemPath()}},
+		})
+
+	case path == systemPath() && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, &redfish.ComputerSystem{
+			ID:         SystemID,
+			ODataID:    systemPath(),
+			PowerState: s.PowerState(),
+			Actions: map[string]redfish.ActionTarget{
+				redfish.ResetActionName: {Target: resetPath()},
+			},
+		})
+
+	case path == resetPath() && r.Method == http.MethodPost:
+		request := &redfish.ResetRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.reset(request.ResetType); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// reset applies the reset action on the computer system
+func (s *Simulator) reset(resetType redfish.ResetType) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch resetType {
+	case redfish.ResetTypeOn:
+		s.powerState = redfish.PowerStateOn
+	case redfish.ResetTypeForceOff:
+		s.powerState = redfish.PowerStateOff
+	case redfish.ResetTypeGracefulShutdown:
+		if !s.ignoreGracefulShutdown {
+			s.powerState = redfish.PowerStateOff
+		}
+	default:
+		return fmt.Errorf("unsupported reset type %q", resetType)
+	}
+	s.resets = append(s.resets, resetType)
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(obj)
+}
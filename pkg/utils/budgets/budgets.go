@@ -0,0 +1,164 @@
+package budgets
+
+import (
+	"context"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetMachineBudgets returns machine disruption budgets that select the machine
+func GetMachineBudgets(c client.Client, machine *mapiv1.Machine) ([]mrv1.MachineDisruptionBudget, error) {
+	mdbs := &mrv1.MachineDisruptionBudgetList{}
+	if err := c.List(context.TODO(), mdbs, client.InNamespace(machine.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var selected []mrv1.MachineDisruptionBudget
+	for _, mdb := range mdbs.Items {
+		matched, err := IsMachineSelected(&mdb, machine)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			selected = append(selected, mdb)
+		}
+	}
+	return selected, nil
+}
+
+// IsMachineSelected returns true when the budget selector matches labels of the machine,
+// the empty selector does not match any machine
+func IsMachineSelected(mdb *mrv1.MachineDisruptionBudget, machine *mapiv1.Machine) (bool, error) {
+	if mdb.Spec.Selector == nil {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(mdb.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	if selector.Empty() {
+		return false, nil
+	}
+	return selector.Matches(labels.Set(machine.Labels)), nil
+}
+
+// GetStatus returns the status of the machine disruption budget and the set of healthy machines that the budget
+// selects, the machine is healthy when its node is ready and the machine does not have the remediation in progress
+func GetStatus(c client.Client, mdb *mrv1.MachineDisruptionBudget) (*mrv1.MachineDisruptionBudgetStatus, map[string]bool, error) {
+	return getStatus(c, c, mdb)
+}
+
+// getStatus returns the status of the machine disruption budget.
+// It lists machine remediations with the reader.
+func getStatus(c client.Client, reader client.Reader, mdb *mrv1.MachineDisruptionBudget) (*mrv1.MachineDisruptionBudgetStatus, map[string]bool, error) {
+	var selectedMachines []mapiv1.Machine
+	if mdb.Spec.Selector != nil {
+		machineList, err := machines.GetMachinesByLabelSelector(c, mdb.Spec.Selector, mdb.Namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		if machineList != nil {
+			selectedMachines = machineList.Items
+		}
+	}
+
+	mrs := &mrv1.MachineRemediationList{}
+	if err := reader.List(context.TODO(), mrs, client.InNamespace(mdb.Namespace)); err != nil {
+		return nil, nil, err
+	}
+
+	remediated := map[string]bool{}
+	for _, mr := range mrs.Items {
+		if policies.IsActive(&mr) {
+			remediated[mr.Spec.MachineName] = true
+		}
+	}
+
+	healthy := map[string]bool{}
+	for i := range selectedMachines {
+		machine := &selectedMachines[i]
+		if machine.DeletionTimestamp != nil || remediated[machine.Name] {
+			continue
+		}
+
+		node, err := nodes.GetNodeByMachine(c, machine)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) {
+			healthy[machine.Name] = true
+		}
+	}
+
+	total := int32(len(selectedMachines))
+	desiredHealthy := getDesiredHealthy(mdb, total)
+	currentHealthy := int32(len(healthy))
+
+	disruptionsAllowed := currentHealthy - desiredHealthy
+	if disruptionsAllowed < 0 {
+		disruptionsAllowed = 0
+	}
+
+	return &mrv1.MachineDisruptionBudgetStatus{
+		ObservedGeneration: mdb.Generation,
+		DisruptionsAllowed: disruptionsAllowed,
+		CurrentHealthy:     currentHealthy,
+		DesiredHealthy:     desiredHealthy,
+		Total:              total,
+	}, healthy, nil
+}
+
+// getDesiredHealthy returns the number of selected machines that must stay healthy
+func getDesiredHealthy(mdb *mrv1.MachineDisruptionBudget, total int32) int32 {
+	if mdb.Spec.MinAvailable != nil {
+		return *mdb.Spec.MinAvailable
+	}
+
+	if mdb.Spec.MaxUnavailable != nil {
+		desiredHealthy := total - *mdb.Spec.MaxUnavailable
+		if desiredHealthy < 0 {
+			return 0
+		}
+		return desiredHealthy
+	}
+	return 0
+}
+
+// GetExceededBudget returns the machine disruption budget that does not allow the disruption of the machine,
+// or nil when all budgets of the machine allow it, the remediation of the unhealthy machine does not
+// reduce the number of healthy machines, so budgets always allow it. The reader should read machine remediations
+// directly from the API server, so the remediation that just started counts even before the cache observes it.
+func GetExceededBudget(c client.Client, reader client.Reader, machine *mapiv1.Machine) (*mrv1.MachineDisruptionBudget, error) {
+	mdbs, err := GetMachineBudgets(c, machine)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range mdbs {
+		status, healthy, err := getStatus(c, reader, &mdbs[i])
+		if err != nil {
+			return nil, err
+		}
+		if healthy[machine.Name] && status.DisruptionsAllowed < 1 {
+			return &mdbs[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,175 @@
+package budgets
+
+import (
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// newObjects returns three selected machines, two of them with ready nodes and one with the not ready node
+func newObjects() []runtime.Object {
+	return []runtime.Object{
+		mrtesting.NewMachine("machine1", "node1", ""),
+		mrtesting.NewNode("node1", true, "machine1"),
+		mrtesting.NewMachine("machine2", "node2", ""),
+		mrtesting.NewNode("node2", true, "machine2"),
+		mrtesting.NewMachine("machine3", "node3", ""),
+		mrtesting.NewNode("node3", false, "machine3"),
+	}
+}
+
+func TestGetStatus(t *testing.T) {
+	mrActive := mrtesting.NewMachineRemediation("mrActive", "machine2", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mrPending := mrtesting.NewMachineRemediation("mrPending", "machine2", mrv1.RemediationTypeReboot, mrv1.RemediationStatePending)
+
+	mdbWithEmptySelector := mrtesting.NewMachineDisruptionBudget("mdbWithEmptySelector", int32Ptr(1), nil)
+	mdbWithEmptySelector.Spec.Selector = nil
+
+	testCases := []struct {
+		name           string
+		mdb            *mrv1.MachineDisruptionBudget
+		extraObjects   []runtime.Object
+		expectedStatus mrv1.MachineDisruptionBudgetStatus
+	}{
+		{
+			name: "with min available",
+			mdb:  mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(1), nil),
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DisruptionsAllowed: 1,
+				CurrentHealthy:     2,
+				DesiredHealthy:     1,
+				Total:              3,
+			},
+		},
+		{
+			name: "with max unavailable",
+			mdb:  mrtesting.NewMachineDisruptionBudget("mdb", nil, int32Ptr(1)),
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DisruptionsAllowed: 0,
+				CurrentHealthy:     2,
+				DesiredHealthy:     2,
+				Total:              3,
+			},
+		},
+		{
+			name:         "with active machine remediation",
+			mdb:          mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(1), nil),
+			extraObjects: []runtime.Object{mrActive},
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DisruptionsAllowed: 0,
+				CurrentHealthy:     1,
+				DesiredHealthy:     1,
+				Total:              3,
+			},
+		},
+		{
+			name:         "with pending machine remediation",
+			mdb:          mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(1), nil),
+			extraObjects: []runtime.Object{mrPending},
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DisruptionsAllowed: 1,
+				CurrentHealthy:     2,
+				DesiredHealthy:     1,
+				Total:              3,
+			},
+		},
+		{
+			name: "with empty selector",
+			mdb:  mdbWithEmptySelector,
+			expectedStatus: mrv1.MachineDisruptionBudgetStatus{
+				DesiredHealthy: 1,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(append(newObjects(), tc.extraObjects...)...)
+		status, _, err := GetStatus(c, tc.mdb)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			continue
+		}
+
+		if *status != tc.expectedStatus {
+			t.Errorf("Test case: %s. Expected status: %+v, got: %+v", tc.name, tc.expectedStatus, *status)
+		}
+	}
+}
+
+func TestGetExceededBudget(t *testing.T) {
+	machineHealthy := mrtesting.NewMachine("machine1", "node1", "")
+	machineUnhealthy := mrtesting.NewMachine("machine3", "node3", "")
+
+	// the remediation of the other healthy machine started, but the cache does not have it yet
+	mrStarted := mrtesting.NewMachineRemediation("mrStarted", "machine2", mrv1.RemediationTypeReboot, mrv1.RemediationStateStarted)
+
+	testCases := []struct {
+		name        string
+		machine     *mapiv1.Machine
+		mdb         *mrv1.MachineDisruptionBudget
+		started     *mrv1.MachineRemediation
+		expectedMdb string
+	}{
+		{
+			name:    "with healthy machine and allowed disruption",
+			machine: machineHealthy,
+			mdb:     mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(1), nil),
+		},
+		{
+			name:        "with healthy machine and exceeded budget",
+			machine:     machineHealthy,
+			mdb:         mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(2), nil),
+			expectedMdb: "mdb",
+		},
+		{
+			name:        "with healthy machine and disruption taken by the remediation that the cache does not have",
+			machine:     machineHealthy,
+			mdb:         mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(1), nil),
+			started:     mrStarted,
+			expectedMdb: "mdb",
+		},
+		{
+			name:    "with unhealthy machine and exceeded budget",
+			machine: machineUnhealthy,
+			mdb:     mrtesting.NewMachineDisruptionBudget("mdb", int32Ptr(2), nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(append(newObjects(), tc.mdb)...)
+		reader := c
+		if tc.started != nil {
+			reader = fake.NewFakeClient(append(newObjects(), tc.mdb, tc.started)...)
+		}
+		mdb, err := GetExceededBudget(c, reader, tc.machine)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			continue
+		}
+
+		name := ""
+		if mdb != nil {
+			name = mdb.Name
+		}
+		if name != tc.expectedMdb {
+			t.Errorf("Test case: %s. Expected budget: %q, got: %q", tc.name, tc.expectedMdb, name)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package conditions
 
 import (
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // GetNodeCondition returns node condition by type
@@ -23,3 +26,67 @@ func NodeHasCondition(node *corev1.Node, conditionType corev1.NodeConditionType,
 	}
 	return false
 }
+
+// NodeConditionsChanged returns true when the new node has conditions with other types or statuses than the old node,
+// heartbeats of the node do not change them
+func NodeConditionsChanged(oldNode *corev1.Node, newNode *corev1.Node) bool {
+	if len(oldNode.Status.Conditions) != len(newNode.Status.Conditions) {
+		return true
+	}
+	for _, cond := range newNode.Status.Conditions {
+		if !NodeHasCondition(oldNode, cond.Type, cond.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMachineRemediationCondition returns machine remediation condition by type
+func GetMachineRemediationCondition(mr *mrv1.MachineRemediation, conditionType mrv1.MachineRemediationConditionType) *mrv1.MachineRemediationCondition {
+	for i := range mr.Status.Conditions {
+		if mr.Status.Conditions[i].Type == conditionType {
+			return &mr.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// MachineRemediationHasCondition returns true when the machine remediation has condition of the specific type and status
+func MachineRemediationHasCondition(mr *mrv1.MachineRemediation, conditionType mrv1.MachineRemediationConditionType, conditionStatus corev1.ConditionStatus) bool {
+	cond := GetMachineRemediationCondition(mr, conditionType)
+	return cond != nil && cond.Status == conditionStatus
+}
+
+// SetMachineRemediationCondition sets the machine remediation condition, the last transition time
+// changes only when the condition status changes
+func SetMachineRemediationCondition(
+	mr *mrv1.MachineRemediation,
+	conditionType mrv1.MachineRemediationConditionType,
+	status corev1.ConditionStatus,
+	reason mrv1.RemediationReason,
+	message string,
+) {
+	cond := GetMachineRemediationCondition(mr, conditionType)
+	if cond == nil {
+		mr.Status.Conditions = append(mr.Status.Conditions, mrv1.MachineRemediationCondition{Type: conditionType})
+		cond = &mr.Status.Conditions[len(mr.Status.Conditions)-1]
+	}
+
+	if cond.Status != status {
+		cond.LastTransitionTime = metav1.Now()
+	}
+	cond.Status = status
+	cond.Reason = reason
+	cond.Message = message
+}
+
+// RemoveMachineRemediationCondition removes the machine remediation condition of the specific type
+func RemoveMachineRemediationCondition(mr *mrv1.MachineRemediation, conditionType mrv1.MachineRemediationConditionType) {
+	var conds []mrv1.MachineRemediationCondition
+	for _, cond := range mr.Status.Conditions {
+		if cond.Type != conditionType {
+			conds = append(conds, cond)
+		}
+	}
+	mr.Status.Conditions = conds
+}
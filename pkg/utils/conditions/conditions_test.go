@@ -3,11 +3,14 @@ package conditions
 import (
 	"reflect"
 	"testing"
+	"time"
 
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -57,3 +60,207 @@ func TestGetNodeCondition(t *testing.T) {
 		}
 	}
 }
+
+func TestNodeConditionsChanged(t *testing.T) {
+	heartbeat := node("heartbeat", true)
+	heartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+
+	pressure := node("pressure", true)
+	pressure.Status.Conditions = append(pressure.Status.Conditions, corev1.NodeCondition{
+		Type:   corev1.NodeMemoryPressure,
+		Status: corev1.ConditionTrue,
+	})
+
+	testsCases := []struct {
+		name     string
+		newNode  *corev1.Node
+		expected bool
+	}{
+		{
+			name:     "with heartbeat",
+			newNode:  heartbeat,
+			expected: false,
+		},
+		{
+			name:     "with changed status",
+			newNode:  node("notReady", false),
+			expected: true,
+		},
+		{
+			name:     "with new condition",
+			newNode:  pressure,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		if got := NodeConditionsChanged(node("ready", true), tc.newNode); got != tc.expected {
+			t.Errorf("Test case: %s. Expected: %t, got: %t", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestSetMachineRemediationCondition(t *testing.T) {
+	mr := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mr.Status.Conditions = []mrv1.MachineRemediationCondition{
+		{
+			Type:               mrv1.MachineRemediationConditionSucceeded,
+			Status:             corev1.ConditionUnknown,
+			LastTransitionTime: mrtesting.KnownDate,
+			Reason:             mrv1.RemediationReasonInProgress,
+		},
+	}
+
+	testsCases := []struct {
+		name                  string
+		conditionType         mrv1.MachineRemediationConditionType
+		status                corev1.ConditionStatus
+		reason                mrv1.RemediationReason
+		expectedConditions    int
+		expectedTransitionSet bool
+	}{
+		{
+			name:                  "with the same status",
+			conditionType:         mrv1.MachineRemediationConditionSucceeded,
+			status:                corev1.ConditionUnknown,
+			reason:                mrv1.RemediationReasonInProgress,
+			expectedConditions:    1,
+			expectedTransitionSet: false,
+		},
+		{
+			name:                  "with the new status",
+			conditionType:         mrv1.MachineRemediationConditionSucceeded,
+			status:                corev1.ConditionFalse,
+			reason:                mrv1.RemediationReasonTimedOut,
+			expectedConditions:    1,
+			expectedTransitionSet: true,
+		},
+		{
+			name:                  "with the new condition",
+			conditionType:         mrv1.MachineRemediationConditionFenced,
+			status:                corev1.ConditionTrue,
+			reason:                mrv1.RemediationReasonInProgress,
+			expectedConditions:    2,
+			expectedTransitionSet: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		SetMachineRemediationCondition(mr, tc.conditionType, tc.status, tc.reason, "message")
+		if len(mr.Status.Conditions) != tc.expectedConditions {
+			t.Errorf("Test case: %s. Expected: %d conditions, got: %v", tc.name, tc.expectedConditions, mr.Status.Conditions)
+		}
+
+		cond := GetMachineRemediationCondition(mr, tc.conditionType)
+		if cond == nil {
+			t.Errorf("Test case: %s. Expected: condition %q, got: nil", tc.name, tc.conditionType)
+			continue
+		}
+
+		if cond.Status != tc.status || cond.Reason != tc.reason {
+			t.Errorf("Test case: %s. Expected: status %q and reason %q, got: %v", tc.name, tc.status, tc.reason, cond)
+		}
+
+		if tc.expectedTransitionSet == cond.LastTransitionTime.Equal(&mrtesting.KnownDate) {
+			t.Errorf("Test case: %s. Expected: last transition time updated %t, got: %v", tc.name, tc.expectedTransitionSet, cond.LastTransitionTime)
+		}
+	}
+}
+
+func TestParseUnhealthyConditions(t *testing.T) {
+	testsCases := []struct {
+		name          string
+		cm            *corev1.ConfigMap
+		expected      []mrv1.UnhealthyCondition
+		expectedError bool
+	}{
+		{
+			name: "with correct data",
+			cm:   mrtesting.NewUnhealthyConditionsConfigMap(mrv1.ConfigMapNodeUnhealthyConditions, correctData),
+			expected: []mrv1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 60 * time.Second},
+				},
+			},
+		},
+		{
+			name:          "with wrong timeout",
+			cm:            mrtesting.NewUnhealthyConditionsConfigMap(mrv1.ConfigMapNodeUnhealthyConditions, "items:\n- name: Ready\n  timeout: wrong\n  status: Unknown"),
+			expectedError: true,
+		},
+		{
+			name: "without conditions key",
+			cm: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mrv1.ConfigMapNodeUnhealthyConditions,
+					Namespace: namespace,
+				},
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		conds, err := ParseUnhealthyConditions(tc.cm)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.name, tc.expectedError, err)
+		}
+
+		if !reflect.DeepEqual(conds, tc.expected) {
+			t.Errorf("Test case: %s. Expected: %v, got: %v", tc.name, tc.expected, conds)
+		}
+	}
+}
+
+func TestGetUnhealthyCondition(t *testing.T) {
+	now := mrtesting.KnownDate.Add(time.Minute)
+
+	testsCases := []struct {
+		name              string
+		node              *corev1.Node
+		timeout           time.Duration
+		expectedUnhealthy bool
+		expectedExpiresIn time.Duration
+	}{
+		{
+			name:              "with healthy node",
+			node:              node("healthy", true),
+			timeout:           5 * time.Minute,
+			expectedUnhealthy: false,
+		},
+		{
+			name:              "with unhealthy condition before the timeout",
+			node:              node("notReady", false),
+			timeout:           5 * time.Minute,
+			expectedUnhealthy: false,
+			expectedExpiresIn: 4 * time.Minute,
+		},
+		{
+			name:              "with unhealthy condition after the timeout",
+			node:              node("notReady", false),
+			timeout:           30 * time.Second,
+			expectedUnhealthy: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		unhealthyConditions := []mrv1.UnhealthyCondition{
+			{
+				Type:    corev1.NodeReady,
+				Status:  corev1.ConditionUnknown,
+				Timeout: metav1.Duration{Duration: tc.timeout},
+			},
+		}
+
+		cond, expiresIn := GetUnhealthyCondition(tc.node, unhealthyConditions, now)
+		if tc.expectedUnhealthy != (cond != nil) {
+			t.Errorf("Test case: %s. Expected unhealthy: %t, got: %v", tc.name, tc.expectedUnhealthy, cond)
+		}
+
+		if expiresIn != tc.expectedExpiresIn {
+			t.Errorf("Test case: %s. Expected expires in: %v, got: %v", tc.name, tc.expectedExpiresIn, expiresIn)
+		}
+	}
+}
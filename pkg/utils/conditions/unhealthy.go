@@ -0,0 +1,99 @@
+package conditions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// configMapUnhealthyConditionsKey contains the key of unhealthy conditions under the config map data
+	configMapUnhealthyConditionsKey = "conditions"
+	// defaultUnhealthyTimeout contains the time that the node can be not ready, before the node is unhealthy
+	defaultUnhealthyTimeout = 5 * time.Minute
+)
+
+// UnhealthyConditions contains unhealthy conditions of nodes under the node-unhealthy-conditions config map
+type UnhealthyConditions struct {
+	Items []UnhealthyCondition `json:"items"`
+}
+
+// UnhealthyCondition contains the node condition under the node-unhealthy-conditions config map,
+// the timeout has the Go duration format
+type UnhealthyCondition struct {
+	Name    corev1.NodeConditionType `json:"name"`
+	Status  corev1.ConditionStatus   `json:"status"`
+	Timeout string                   `json:"timeout"`
+}
+
+// DefaultUnhealthyConditions returns unhealthy conditions of nodes, that the controller uses when neither
+// the machine health check nor the config map specify them
+func DefaultUnhealthyConditions() []mrv1.UnhealthyCondition {
+	return []mrv1.UnhealthyCondition{
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionFalse,
+			Timeout: metav1.Duration{Duration: defaultUnhealthyTimeout},
+		},
+		{
+			Type:    corev1.NodeReady,
+			Status:  corev1.ConditionUnknown,
+			Timeout: metav1.Duration{Duration: defaultUnhealthyTimeout},
+		},
+	}
+}
+
+// ParseUnhealthyConditions returns unhealthy conditions of nodes under the node-unhealthy-conditions config map
+func ParseUnhealthyConditions(cm *corev1.ConfigMap) ([]mrv1.UnhealthyCondition, error) {
+	data, ok := cm.Data[configMapUnhealthyConditionsKey]
+	if !ok {
+		return nil, fmt.Errorf("the config map %q does not have the %q key", cm.Name, configMapUnhealthyConditionsKey)
+	}
+
+	unhealthyConditions := &UnhealthyConditions{}
+	if err := yaml.Unmarshal([]byte(data), unhealthyConditions); err != nil {
+		return nil, err
+	}
+
+	var parsed []mrv1.UnhealthyCondition
+	for _, item := range unhealthyConditions.Items {
+		timeout, err := time.ParseDuration(item.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("the condition %q has wrong timeout: %v", item.Name, err)
+		}
+		parsed = append(parsed, mrv1.UnhealthyCondition{
+			Type:    item.Name,
+			Status:  item.Status,
+			Timeout: metav1.Duration{Duration: timeout},
+		})
+	}
+	return parsed, nil
+}
+
+// GetUnhealthyCondition returns the unhealthy condition that the node has longer than its timeout, otherwise
+// it returns nil and the time until the first of unhealthy conditions that the node has will expire
+func GetUnhealthyCondition(node *corev1.Node, unhealthyConditions []mrv1.UnhealthyCondition, now time.Time) (*mrv1.UnhealthyCondition, time.Duration) {
+	var expiresIn time.Duration
+	for i := range unhealthyConditions {
+		unhealthyCondition := &unhealthyConditions[i]
+		cond := GetNodeCondition(node, unhealthyCondition.Type)
+		if cond == nil || cond.Status != unhealthyCondition.Status {
+			continue
+		}
+
+		left := cond.LastTransitionTime.Add(unhealthyCondition.Timeout.Duration).Sub(now)
+		if left <= 0 {
+			return unhealthyCondition, 0
+		}
+		if expiresIn == 0 || left < expiresIn {
+			expiresIn = left
+		}
+	}
+	return nil, expiresIn
+}
@@ -0,0 +1,187 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Quorum contains the state of etcd members, that run on control plane machines
+type Quorum struct {
+	// Members contains the number of etcd members, or the number of control plane machines
+	// when the controller does not have the etcd client
+	Members int
+	// HealthyMembers contains names of control plane machines with healthy etcd members
+	HealthyMembers map[string]bool
+}
+
+// Required returns the number of healthy etcd members that the etcd cluster needs to keep the quorum
+func (q *Quorum) Required() int {
+	return q.Members/2 + 1
+}
+
+// IsSafeWithout returns true when the etcd cluster keeps the quorum without the member of the machine
+func (q *Quorum) IsSafeWithout(machineName string) bool {
+	healthy := len(q.HealthyMembers)
+	if q.HealthyMembers[machineName] {
+		healthy--
+	}
+	return healthy >= q.Required()
+}
+
+// String returns the human-readable state of etcd members
+func (q *Quorum) String() string {
+	return fmt.Sprintf("%d of %d etcd members are healthy, the quorum requires %d", len(q.HealthyMembers), q.Members, q.Required())
+}
+
+// GetMachines returns control plane machines under the namespace
+func GetMachines(c client.Client, namespace string) ([]mapiv1.Machine, error) {
+	machineList := &mapiv1.MachineList{}
+	if err := c.List(
+		context.TODO(),
+		machineList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{consts.MachineRoleLabel: consts.MachineRoleMaster},
+	); err != nil {
+		return nil, err
+	}
+
+	var machines []mapiv1.Machine
+	for _, machine := range machineList.Items {
+		if machine.DeletionTimestamp == nil {
+			machines = append(machines, machine)
+		}
+	}
+	return machines, nil
+}
+
+// GetQuorum returns the state of etcd members on control plane machines. The etcd member is healthy when
+// the node of the machine is ready and, when the cluster runs etcd member pods, the etcd pod on the node is ready.
+// With the etcd client, the etcd member list gives the number of members, and the member of the machine
+// should be started. The reader should read pods directly from the API server.
+func GetQuorum(c client.Client, reader client.Reader, etcdClient *etcd.Client, machines []mapiv1.Machine) (*Quorum, error) {
+	pods := &corev1.PodList{}
+	if err := reader.List(
+		context.TODO(),
+		pods,
+		client.InNamespace(consts.NamespaceOpenshiftEtcd),
+		client.MatchingLabels{consts.EtcdPodLabelKey: consts.EtcdPodLabelValue},
+	); err != nil {
+		return nil, err
+	}
+
+	// without etcd member pods, etcd members follow readiness of their nodes
+	readyPods := map[string]bool{}
+	for _, pod := range pods.Items {
+		if isPodReady(&pod) {
+			readyPods[pod.Spec.NodeName] = true
+		}
+	}
+
+	quorum := &Quorum{
+		Members:        len(machines),
+		HealthyMembers: map[string]bool{},
+	}
+
+	// the etcd member list also counts members that do not run on control plane machines
+	var members []etcd.Member
+	if etcdClient != nil {
+		var err error
+		if members, err = etcdClient.MemberList(context.TODO()); err != nil {
+			return nil, err
+		}
+		quorum.Members = len(members)
+	}
+
+	for i := range machines {
+		node, err := nodes.GetNodeByMachine(c, &machines[i])
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		if !conditions.NodeHasCondition(node, corev1.NodeReady, corev1.ConditionTrue) {
+			continue
+		}
+		if len(pods.Items) != 0 && !readyPods[node.Name] {
+			continue
+		}
+		if etcdClient != nil {
+			if member := FindMember(members, &machines[i], node); member == nil || !member.IsStarted() {
+				continue
+			}
+		}
+		quorum.HealthyMembers[machines[i].Name] = true
+	}
+	return quorum, nil
+}
+
+// FindMember returns the etcd member that runs on the machine, or nil when the etcd cluster does not have it.
+// The member is looked up by names and internal addresses of the machine and its node, the node can be nil.
+func FindMember(members []etcd.Member, machine *mapiv1.Machine, node *corev1.Node) *etcd.Member {
+	names := []string{machine.Name}
+	var addresses []string
+	for _, address := range machine.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			addresses = append(addresses, address.Address)
+		}
+	}
+
+	if node != nil {
+		names = append(names, node.Name)
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				addresses = append(addresses, address.Address)
+			}
+		}
+	}
+	return etcd.FindMember(members, names, addresses)
+}
+
+// GetActiveRemediation returns the name of the machine remediation in progress of other control plane machine,
+// or an empty string when no other control plane machine has the remediation in progress.
+// The reader should read machine remediations directly from the API server.
+func GetActiveRemediation(reader client.Reader, mr *mrv1.MachineRemediation, machines []mapiv1.Machine) (string, error) {
+	mrs := &mrv1.MachineRemediationList{}
+	if err := reader.List(context.TODO(), mrs, client.InNamespace(mr.Namespace)); err != nil {
+		return "", err
+	}
+
+	controlPlane := map[string]bool{}
+	for _, machine := range machines {
+		controlPlane[machine.Name] = true
+	}
+
+	for _, other := range mrs.Items {
+		if other.Name == mr.Name || other.Spec.MachineName == mr.Spec.MachineName {
+			continue
+		}
+		if controlPlane[other.Spec.MachineName] && policies.IsActive(&other) {
+			return other.Name, nil
+		}
+	}
+	return "", nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
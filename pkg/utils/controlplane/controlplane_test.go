@@ -0,0 +1,208 @@
+package controlplane
+
+import (
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/etcd"
+	"kubevirt.io/machine-remediation/pkg/etcd/simulator"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func newMasterMachine(name string, nodeName string) *mapiv1.Machine {
+	machine := mrtesting.NewMachine(name, nodeName, "")
+	machine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+	return machine
+}
+
+func newEtcdPod(nodeName string, ready bool) *corev1.Pod {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "etcd-member-" + nodeName,
+			Namespace: consts.NamespaceOpenshiftEtcd,
+			Labels:    map[string]string{consts.EtcdPodLabelKey: consts.EtcdPodLabelValue},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{
+				{
+					Type:   corev1.PodReady,
+					Status: status,
+				},
+			},
+		},
+	}
+}
+
+func TestGetQuorum(t *testing.T) {
+	objects := []runtime.Object{
+		newMasterMachine("master1", "node1"),
+		mrtesting.NewNode("node1", true, "master1"),
+		newMasterMachine("master2", "node2"),
+		mrtesting.NewNode("node2", true, "master2"),
+		newMasterMachine("master3", "node3"),
+		mrtesting.NewNode("node3", false, "master3"),
+		mrtesting.NewMachine("worker", "workerNode", ""),
+		mrtesting.NewNode("workerNode", true, "worker"),
+	}
+
+	testCases := []struct {
+		name            string
+		pods            []runtime.Object
+		expectedHealthy []string
+		expectedSafe    map[string]bool
+	}{
+		{
+			name:            "without etcd pods",
+			expectedHealthy: []string{"master1", "master2"},
+			expectedSafe:    map[string]bool{"master1": false, "master2": false, "master3": true},
+		},
+		{
+			name:            "with not ready etcd pod",
+			pods:            []runtime.Object{newEtcdPod("node1", true), newEtcdPod("node2", false), newEtcdPod("node3", true)},
+			expectedHealthy: []string{"master1"},
+			expectedSafe:    map[string]bool{"master1": false, "master2": false, "master3": false},
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(append(objects, tc.pods...)...)
+		machines, err := GetMachines(c, consts.NamespaceOpenshiftMachineAPI)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			continue
+		}
+		if len(machines) != 3 {
+			t.Errorf("Test case: %s. Expected: 3 control plane machines, got: %d", tc.name, len(machines))
+		}
+
+		quorum, err := GetQuorum(c, c, nil, machines)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+			continue
+		}
+
+		if quorum.Members != 3 || quorum.Required() != 2 {
+			t.Errorf("Test case: %s. Expected: 3 members with the quorum 2, got: %s", tc.name, quorum)
+		}
+
+		if len(quorum.HealthyMembers) != len(tc.expectedHealthy) {
+			t.Errorf("Test case: %s. Expected healthy members: %v, got: %v", tc.name, tc.expectedHealthy, quorum.HealthyMembers)
+		}
+		for _, name := range tc.expectedHealthy {
+			if !quorum.HealthyMembers[name] {
+				t.Errorf("Test case: %s. Expected: member %q healthy, got: %v", tc.name, name, quorum.HealthyMembers)
+			}
+		}
+
+		for name, safe := range tc.expectedSafe {
+			if quorum.IsSafeWithout(name) != safe {
+				t.Errorf("Test case: %s. Expected: safe without member %q %t, got: %t", tc.name, name, safe, !safe)
+			}
+		}
+	}
+}
+
+func TestGetActiveRemediation(t *testing.T) {
+	machines := []mapiv1.Machine{
+		*newMasterMachine("master1", "node1"),
+		*newMasterMachine("master2", "node2"),
+	}
+	mr := mrtesting.NewMachineRemediation("mr", "master1", mrv1.RemediationTypeReboot, "")
+
+	testCases := []struct {
+		name           string
+		other          *mrv1.MachineRemediation
+		expectedActive string
+	}{
+		{
+			name:           "with active remediation of other control plane machine",
+			other:          mrtesting.NewMachineRemediation("other", "master2", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+			expectedActive: "other",
+		},
+		{
+			name:  "with pending remediation of other control plane machine",
+			other: mrtesting.NewMachineRemediation("other", "master2", mrv1.RemediationTypeReboot, mrv1.RemediationStatePending),
+		},
+		{
+			name:  "with active remediation of worker machine",
+			other: mrtesting.NewMachineRemediation("other", "worker", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(mr, tc.other)
+		active, err := GetActiveRemediation(c, mr, machines)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected: no error, got: %v", tc.name, err)
+		}
+		if active != tc.expectedActive {
+			t.Errorf("Test case: %s. Expected active: %q, got: %q", tc.name, tc.expectedActive, active)
+		}
+	}
+}
+
+func TestGetQuorumWithEtcd(t *testing.T) {
+	master1 := newMasterMachine("master1", "node1")
+	master2 := newMasterMachine("master2", "node2")
+	master2.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.111.21"}}
+	master3 := newMasterMachine("master3", "node3")
+	c := fake.NewFakeClient(
+		master1,
+		mrtesting.NewNode("node1", true, "master1"),
+		master2,
+		mrtesting.NewNode("node2", true, "master2"),
+		master3,
+		mrtesting.NewNode("node3", false, "master3"),
+	)
+
+	// the member of the second node was added, but it did not start yet
+	s := simulator.NewSimulator(
+		simulator.NewMember(1, "etcd-member-node1", "192.168.111.20"),
+		etcd.Member{ID: etcd.MemberID(2), PeerURLs: []string{"https://192.168.111.21:2380"}},
+		simulator.NewMember(3, "etcd-member-node3", "192.168.111.22"),
+		simulator.NewMember(4, "etcd-member-node4", "192.168.111.23"),
+	)
+	defer s.Close()
+
+	etcdClient, err := etcd.NewClient([]string{s.Endpoint()}, nil)
+	if err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+
+	quorum, err := GetQuorum(c, c, etcdClient, []mapiv1.Machine{*master1, *master2, *master3})
+	if err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+
+	if quorum.Members != 4 || quorum.Required() != 3 {
+		t.Errorf("Expected: 4 members with the quorum 3, got: %s", quorum)
+	}
+
+	if len(quorum.HealthyMembers) != 1 || !quorum.HealthyMembers["master1"] {
+		t.Errorf("Expected: only member of master1 healthy, got: %v", quorum.HealthyMembers)
+	}
+}
@@ -0,0 +1,154 @@
+package drain
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	policyclient "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// annotationMirrorPod contains the annotation that the kubelet sets on mirror pods of static pods
+	annotationMirrorPod = "kubernetes.io/config.mirror"
+)
+
+// Drainer cordons nodes and evicts their pods via the eviction API,
+// so pod disruption budgets are respected by the drain
+type Drainer struct {
+	client    client.Client
+	reader    client.Reader
+	evictions policyclient.EvictionsGetter
+	// Timeout contains the time that the node has to be drained, before the remediation continues without the drain
+	Timeout time.Duration
+}
+
+// NewDrainer returns new Drainer object
+func NewDrainer(mgr manager.Manager, timeout time.Duration) (*Drainer, error) {
+	policyClient, err := policyclient.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Drainer{
+		client: mgr.GetClient(),
+		// pods are read directly from the API server, to avoid the cache of all pods under the cluster
+		reader:    mgr.GetAPIReader(),
+		evictions: policyClient,
+		Timeout:   timeout,
+	}, nil
+}
+
+// NewFakeDrainer returns new Drainer object with provided clients, it should be used only for tests
+func NewFakeDrainer(c client.Client, evictions policyclient.EvictionsGetter, timeout time.Duration) *Drainer {
+	return &Drainer{
+		client:    c,
+		reader:    c,
+		evictions: evictions,
+		Timeout:   timeout,
+	}
+}
+
+// Drain cordons the node and evicts all its pods, except mirror and DaemonSet pods,
+// it returns true once the node does not have any pods that should be evicted
+func (d *Drainer) Drain(node *corev1.Node) (bool, error) {
+	if err := d.cordon(node); err != nil {
+		return false, err
+	}
+
+	pods, err := d.getPodsForEviction(node)
+	if err != nil {
+		return false, err
+	}
+
+	drained := true
+	for i := range pods {
+		pod := &pods[i]
+		drained = false
+
+		// the pod already evicted and still terminating
+		if pod.DeletionTimestamp != nil {
+			glog.V(4).Infof("Pod %s/%s on node %q still terminating", pod.Namespace, pod.Name, node.Name)
+			continue
+		}
+
+		eviction := &policyv1beta1.Eviction{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			},
+		}
+		glog.V(4).Infof("Evict pod %s/%s from node %q", pod.Namespace, pod.Name, node.Name)
+		if err := d.evictions.Evictions(pod.Namespace).Evict(eviction); err != nil {
+			// the eviction is not allowed right now because of the pod disruption budget, retry on the next reconcile
+			if errors.IsTooManyRequests(err) {
+				glog.Warningf("Eviction of pod %s/%s blocked by the disruption budget: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+	}
+	return drained, nil
+}
+
+// cordon marks the node as unschedulable
+func (d *Drainer) cordon(node *corev1.Node) error {
+	if node.Spec.Unschedulable {
+		return nil
+	}
+
+	glog.V(4).Infof("Cordon node %q", node.Name)
+	nodeCopy := node.DeepCopy()
+	nodeCopy.Spec.Unschedulable = true
+	return d.client.Update(context.TODO(), nodeCopy)
+}
+
+// getPodsForEviction returns pods of the node that should be evicted
+func (d *Drainer) getPodsForEviction(node *corev1.Node) ([]corev1.Pod, error) {
+	allPods := &corev1.PodList{}
+	if err := d.reader.List(context.TODO(), allPods, client.MatchingField("spec.nodeName", node.Name)); err != nil {
+		return nil, err
+	}
+
+	pods := []corev1.Pod{}
+	for _, pod := range allPods.Items {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+
+		// the pod already finished and does not have running containers
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		// the kubelet manages mirror pods, they can not be evicted via the API server
+		if _, ok := pod.Annotations[annotationMirrorPod]; ok {
+			continue
+		}
+
+		// the DaemonSet controller ignores the unschedulable node and will create pod again
+		if isOwnedByDaemonSet(&pod) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	return pods, nil
+}
+
+// isOwnedByDaemonSet returns true when the pod has DaemonSet controller
+func isOwnedByDaemonSet(pod *corev1.Pod) bool {
+	controllerRef := metav1.GetControllerOf(pod)
+	return controllerRef != nil && controllerRef.Kind == "DaemonSet"
+}
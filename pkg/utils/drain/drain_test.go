@@ -0,0 +1,202 @@
+package drain
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	policyclient "k8s.io/client-go/kubernetes/typed/policy/v1beta1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	namespace = "default"
+	nodeName  = "node"
+)
+
+// fakeEvictions deletes evicted pods, except pods with the blocked label,
+// for them it returns the error like the pod disruption budget does
+type fakeEvictions struct {
+	client  client.Client
+	evicted []string
+}
+
+func (f *fakeEvictions) Evictions(namespace string) policyclient.EvictionInterface {
+	return f
+}
+
+func (f *fakeEvictions) Evict(eviction *policyv1beta1.Eviction) error {
+	pod := &corev1.Pod{}
+	key := client.ObjectKey{Namespace: eviction.Namespace, Name: eviction.Name}
+	if err := f.client.Get(context.TODO(), key, pod); err != nil {
+		return err
+	}
+
+	if _, ok := pod.Labels["blocked"]; ok {
+		return errors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 10)
+	}
+
+	f.evicted = append(f.evicted, pod.Name)
+	return f.client.Delete(context.TODO(), pod)
+}
+
+func newNode(unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Spec: corev1.NodeSpec{
+			Unschedulable: unschedulable,
+		},
+	}
+}
+
+func newPod(name string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+		},
+	}
+}
+
+func TestDrain(t *testing.T) {
+	podBlocked := newPod("blocked", nodeName)
+	podBlocked.Labels["blocked"] = ""
+
+	podTerminating := newPod("terminating", nodeName)
+	podTerminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	podSucceeded := newPod("succeeded", nodeName)
+	podSucceeded.Status.Phase = corev1.PodSucceeded
+
+	podMirror := newPod("mirror", nodeName)
+	podMirror.Annotations = map[string]string{annotationMirrorPod: ""}
+
+	isController := true
+	podDaemonSet := newPod("daemonset", nodeName)
+	podDaemonSet.OwnerReferences = []metav1.OwnerReference{
+		{
+			Kind:       "DaemonSet",
+			Name:       "daemonset",
+			Controller: &isController,
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		node            *corev1.Node
+		pods            []runtime.Object
+		expectedDrained bool
+		expectedEvicted []string
+		expectedPods    []string
+	}{
+		{
+			name:            "with node without pods",
+			node:            newNode(false),
+			pods:            []runtime.Object{},
+			expectedDrained: true,
+			expectedEvicted: nil,
+			expectedPods:    []string{},
+		},
+		{
+			name:            "with cordoned node with pods",
+			node:            newNode(true),
+			pods:            []runtime.Object{newPod("pod1", nodeName), newPod("pod2", nodeName)},
+			expectedDrained: false,
+			expectedEvicted: []string{"pod1", "pod2"},
+			expectedPods:    []string{},
+		},
+		{
+			name:            "with pods under other node",
+			node:            newNode(false),
+			pods:            []runtime.Object{newPod("pod1", "other")},
+			expectedDrained: true,
+			expectedEvicted: nil,
+			expectedPods:    []string{"pod1"},
+		},
+		{
+			name:            "with pods that should not be evicted",
+			node:            newNode(false),
+			pods:            []runtime.Object{podSucceeded, podMirror, podDaemonSet},
+			expectedDrained: true,
+			expectedEvicted: nil,
+			expectedPods:    []string{"daemonset", "mirror", "succeeded"},
+		},
+		{
+			name:            "with pod blocked by disruption budget",
+			node:            newNode(false),
+			pods:            []runtime.Object{podBlocked, newPod("pod1", nodeName)},
+			expectedDrained: false,
+			expectedEvicted: []string{"pod1"},
+			expectedPods:    []string{"blocked"},
+		},
+		{
+			name:            "with terminating pod",
+			node:            newNode(false),
+			pods:            []runtime.Object{podTerminating},
+			expectedDrained: false,
+			expectedEvicted: nil,
+			expectedPods:    []string{"terminating"},
+		},
+	}
+
+	for _, tc := range testCases {
+		objects := append([]runtime.Object{tc.node}, tc.pods...)
+		fakeClient := fake.NewFakeClient(objects...)
+		evictions := &fakeEvictions{client: fakeClient}
+		drainer := NewFakeDrainer(fakeClient, evictions, time.Minute)
+
+		drained, err := drainer.Drain(tc.node)
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if drained != tc.expectedDrained {
+			t.Errorf("%s failed, expected drained: %t, got: %t", tc.name, tc.expectedDrained, drained)
+		}
+
+		if !reflect.DeepEqual(evictions.evicted, tc.expectedEvicted) {
+			t.Errorf("%s failed, expected evicted pods: %v, got: %v", tc.name, tc.expectedEvicted, evictions.evicted)
+		}
+
+		pods := &corev1.PodList{}
+		if err := fakeClient.List(context.TODO(), pods); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		podNames := []string{}
+		for _, pod := range pods.Items {
+			podNames = append(podNames, pod.Name)
+		}
+		sort.Strings(podNames)
+		if !reflect.DeepEqual(podNames, tc.expectedPods) {
+			t.Errorf("%s failed, expected pods: %v, got: %v", tc.name, tc.expectedPods, podNames)
+		}
+
+		node := &corev1.Node{}
+		if err := fakeClient.Get(context.TODO(), client.ObjectKey{Name: nodeName}, node); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if !node.Spec.Unschedulable {
+			t.Errorf("%s failed, expected node to be cordoned", tc.name)
+		}
+	}
+}
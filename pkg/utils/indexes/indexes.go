@@ -0,0 +1,94 @@
+package indexes
+
+import (
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// MachineRemediationMachineNameField contains the name of the machine remediation field index,
+	// that indexes machine remediations by the name of the remediated machine
+	MachineRemediationMachineNameField = "spec.machineName"
+	// MachineNodeNameField contains the name of the machine field index,
+	// that indexes machines by the name of the node referenced under the machine status
+	MachineNodeNameField = "status.nodeRef.name"
+	// MachineProviderIDField contains the name of the machine field index,
+	// that indexes machines by the provider ID of the machine
+	MachineProviderIDField = "spec.providerID"
+	// MachineAddressesField contains the name of the machine field index,
+	// that indexes machines by each address under the machine status
+	MachineAddressesField = "status.addresses"
+)
+
+// MachineIndexers contains field indexers of machine field indexes by names of indexes
+var MachineIndexers = map[string]client.IndexerFunc{
+	MachineNodeNameField:   MachineNodeName,
+	MachineProviderIDField: MachineProviderID,
+	MachineAddressesField:  MachineAddresses,
+}
+
+// AddToManager adds the machine remediation field index to the manager cache, it should run before the manager starts,
+// controllers that list objects with the client.MatchingField option require field indexes
+func AddToManager(mgr manager.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(&mrv1.MachineRemediation{}, MachineRemediationMachineNameField, MachineRemediationMachineName)
+}
+
+// AddMachineIndexesToManager adds machine field indexes to the manager cache, the cache can index
+// only machine.openshift.io machines, machines of other machine API flavors should be filtered by MachineIndexers
+func AddMachineIndexesToManager(mgr manager.Manager) error {
+	for field, indexer := range MachineIndexers {
+		if err := mgr.GetFieldIndexer().IndexField(&mapiv1.Machine{}, field, indexer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MachineRemediationMachineName returns the name of the machine remediated by the machine remediation
+func MachineRemediationMachineName(obj runtime.Object) []string {
+	mr, ok := obj.(*mrv1.MachineRemediation)
+	if !ok || mr.Spec.MachineName == "" {
+		return nil
+	}
+	return []string{mr.Spec.MachineName}
+}
+
+// MachineNodeName returns the name of the node referenced by the machine
+func MachineNodeName(obj runtime.Object) []string {
+	machine, ok := obj.(*mapiv1.Machine)
+	if !ok || machine.Status.NodeRef == nil || machine.Status.NodeRef.Name == "" {
+		return nil
+	}
+	return []string{machine.Status.NodeRef.Name}
+}
+
+// MachineProviderID returns the provider ID of the machine
+func MachineProviderID(obj runtime.Object) []string {
+	machine, ok := obj.(*mapiv1.Machine)
+	if !ok || machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
+		return nil
+	}
+	return []string{*machine.Spec.ProviderID}
+}
+
+// MachineAddresses returns addresses of the machine, a machine address matches the node
+// with the same address or the node with the host name under the node name
+func MachineAddresses(obj runtime.Object) []string {
+	machine, ok := obj.(*mapiv1.Machine)
+	if !ok {
+		return nil
+	}
+
+	var addresses []string
+	for _, address := range machine.Status.Addresses {
+		if address.Address != "" {
+			addresses = append(addresses, address.Address)
+		}
+	}
+	return addresses
+}
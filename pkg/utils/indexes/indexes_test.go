@@ -0,0 +1,88 @@
+package indexes
+
+import (
+	"reflect"
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestIndexerFuncs(t *testing.T) {
+	machineWithoutNode := mrtesting.NewMachine("machineWithoutNode", "", "")
+
+	providerID := "baremetalhost:///openshift-machine-api/host"
+	machineWithProviderID := mrtesting.NewMachine("machineWithProviderID", "", "host")
+	machineWithProviderID.Spec.ProviderID = &providerID
+
+	machineWithAddresses := mrtesting.NewMachine("machineWithAddresses", "", "")
+	machineWithAddresses.Status.Addresses = []corev1.NodeAddress{
+		{Type: corev1.NodeHostName, Address: "worker-0"},
+		{Type: corev1.NodeInternalIP, Address: "192.168.111.20"},
+	}
+
+	testsCases := []struct {
+		name     string
+		indexer  func(runtime.Object) []string
+		obj      runtime.Object
+		expected []string
+	}{
+		{
+			name:     "machine remediation machine name",
+			indexer:  MachineRemediationMachineName,
+			obj:      mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			expected: []string{"machine"},
+		},
+		{
+			name:     "machine remediation without machine name",
+			indexer:  MachineRemediationMachineName,
+			obj:      mrtesting.NewMachineRemediation("mr", "", mrv1.RemediationTypeReboot, ""),
+			expected: nil,
+		},
+		{
+			name:     "machine node name",
+			indexer:  MachineNodeName,
+			obj:      mrtesting.NewMachine("machine", "node", ""),
+			expected: []string{"node"},
+		},
+		{
+			name:     "machine without node",
+			indexer:  MachineNodeName,
+			obj:      machineWithoutNode,
+			expected: nil,
+		},
+		{
+			name:     "machine provider ID",
+			indexer:  MachineProviderID,
+			obj:      machineWithProviderID,
+			expected: []string{providerID},
+		},
+		{
+			name:     "machine without provider ID",
+			indexer:  MachineProviderID,
+			obj:      machineWithoutNode,
+			expected: nil,
+		},
+		{
+			name:     "machine addresses",
+			indexer:  MachineAddresses,
+			obj:      machineWithAddresses,
+			expected: []string{"worker-0", "192.168.111.20"},
+		},
+		{
+			name:     "unexpected object",
+			indexer:  MachineNodeName,
+			obj:      mrtesting.NewNode("node", true, "machine"),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testsCases {
+		if values := tc.indexer(tc.obj); !reflect.DeepEqual(values, tc.expected) {
+			t.Errorf("Test case: %s. Expected: %v, got: %v", tc.name, tc.expected, values)
+		}
+	}
+}
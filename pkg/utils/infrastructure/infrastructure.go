@@ -0,0 +1,30 @@
+package infrastructure
+
+import (
+	"context"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetPlatformType returns the platform type of the cluster infrastructure
+func GetPlatformType(c client.Client) (osconfigv1.PlatformType, error) {
+	infra := &osconfigv1.Infrastructure{}
+	key := types.NamespacedName{
+		Name: consts.InfrastructureName,
+	}
+	if err := c.Get(context.TODO(), key, infra); err != nil {
+		return "", err
+	}
+
+	// the platform status contains the most actual information, the platform field is deprecated
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.Type != "" {
+		return infra.Status.PlatformStatus.Type, nil
+	}
+	return infra.Status.Platform, nil
+}
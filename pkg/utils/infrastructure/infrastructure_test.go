@@ -0,0 +1,68 @@
+package infrastructure
+
+import (
+	"testing"
+
+	osconfigv1 "github.com/openshift/api/config/v1"
+
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	osconfigv1.AddToScheme(scheme.Scheme)
+}
+
+func TestGetPlatformType(t *testing.T) {
+	infraBareMetal := mrtesting.NewInfrastructure(consts.InfrastructureName, osconfigv1.BareMetalPlatformType)
+
+	infraWithPlatformStatus := mrtesting.NewInfrastructure(consts.InfrastructureName, osconfigv1.NonePlatformType)
+	infraWithPlatformStatus.Status.PlatformStatus = &osconfigv1.PlatformStatus{
+		Type: osconfigv1.BareMetalPlatformType,
+	}
+
+	infraWithWrongName := mrtesting.NewInfrastructure("wrongName", osconfigv1.BareMetalPlatformType)
+
+	testsCases := []struct {
+		name             string
+		infrastructure   *osconfigv1.Infrastructure
+		expectedPlatform osconfigv1.PlatformType
+		expectedError    bool
+	}{
+		{
+			name:             "with platform",
+			infrastructure:   infraBareMetal,
+			expectedPlatform: osconfigv1.BareMetalPlatformType,
+			expectedError:    false,
+		},
+		{
+			name:             "with platform status",
+			infrastructure:   infraWithPlatformStatus,
+			expectedPlatform: osconfigv1.BareMetalPlatformType,
+			expectedError:    false,
+		},
+		{
+			name:             "without cluster infrastructure",
+			infrastructure:   infraWithWrongName,
+			expectedPlatform: "",
+			expectedError:    true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		c := fake.NewFakeClient(tc.infrastructure)
+		platform, err := GetPlatformType(c)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.name, tc.expectedError, err)
+		}
+
+		if platform != tc.expectedPlatform {
+			t.Errorf("Test case: %s. Expected platform: %q, got: %q", tc.name, tc.expectedPlatform, platform)
+		}
+	}
+}
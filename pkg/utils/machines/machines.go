@@ -3,14 +3,18 @@ package machines
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/golang/glog"
 
 	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/indexes"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/cache"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
@@ -56,11 +60,77 @@ func GetNodeByMachine(c client.Client, machine *mapiv1.Machine) (*v1.Node, error
 	return node, nil
 }
 
-// GetMachineByNode get the machine object by node object
+// MachineNotFoundError reports that none of machines matches the node
+type MachineNotFoundError struct {
+	NodeName string
+}
+
+func (e *MachineNotFoundError) Error() string {
+	return fmt.Sprintf("failed to find the machine of node %s by the machine annotation, the provider ID, the NodeRef or addresses", e.NodeName)
+}
+
+// IsMachineNotFound returns true when the error reports that none of machines matches the node
+func IsMachineNotFound(err error) bool {
+	_, ok := err.(*MachineNotFoundError)
+	return ok
+}
+
+// GetMachineByNode get the machine object by node object, it looks for the machine under the node machine annotation,
+// then for the machine with the provider ID of the node, and then for the machine that references the node under
+// its NodeRef or has one of node addresses, the client should read machines from the manager cache with machine field indexes
 func GetMachineByNode(c client.Client, node *v1.Node) (*mapiv1.Machine, error) {
+	machine, err := getMachineByAnnotation(c, node)
+	if err != nil || machine != nil {
+		return machine, err
+	}
+
+	if node.Spec.ProviderID != "" {
+		machine, err = getMachineByField(c, node, indexes.MachineProviderIDField, []string{node.Spec.ProviderID}, func(m *mapiv1.Machine) bool {
+			return m.Spec.ProviderID != nil && *m.Spec.ProviderID == node.Spec.ProviderID
+		})
+		if err != nil || machine != nil {
+			return machine, err
+		}
+	}
+
+	machine, err = getMachineByField(c, node, indexes.MachineNodeNameField, []string{node.Name}, func(m *mapiv1.Machine) bool {
+		return m.Status.NodeRef != nil && m.Status.NodeRef.Name == node.Name
+	})
+	if err != nil || machine != nil {
+		return machine, err
+	}
+
+	nodeAddresses := sets.NewString(node.Name)
+	for _, address := range node.Status.Addresses {
+		if address.Address != "" {
+			nodeAddresses.Insert(address.Address)
+		}
+	}
+	machine, err = getMachineByField(c, node, indexes.MachineAddressesField, nodeAddresses.List(), func(m *mapiv1.Machine) bool {
+		for _, address := range m.Status.Addresses {
+			if nodeAddresses.Has(address.Address) {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil || machine != nil {
+		return machine, err
+	}
+	return nil, &MachineNotFoundError{NodeName: node.Name}
+}
+
+// getMachineByAnnotation returns the machine under the node machine annotation of OpenShift or upstream Cluster API,
+// or nil when the node does not have the annotation or the annotated machine does not exist
+func getMachineByAnnotation(c client.Client, node *v1.Node) (*mapiv1.Machine, error) {
 	machineKey, ok := node.Annotations[consts.AnnotationMachine]
 	if !ok {
-		return nil, fmt.Errorf("No machine annotation for node %s", node.Name)
+		// upstream Cluster API keeps the machine name and the namespace under separate annotations
+		machineName, ok := node.Annotations[consts.AnnotationClusterAPIMachine]
+		if !ok {
+			return nil, nil
+		}
+		machineKey = fmt.Sprintf("%s/%s", node.Annotations[consts.AnnotationClusterAPINamespace], machineName)
 	}
 	glog.Infof("Node %s is annotated with machine %s", node.Name, machineKey)
 
@@ -74,7 +144,61 @@ func GetMachineByNode(c client.Client, node *v1.Node) (*mapiv1.Machine, error) {
 		Name:      machineName,
 	}
 	if err := c.Get(context.TODO(), *key, machine); err != nil {
+		if errors.IsNotFound(err) {
+			glog.Warningf("Machine %s annotated on node %s does not exist", machineKey, node.Name)
+			return nil, nil
+		}
 		return nil, err
 	}
 	return machine, nil
 }
+
+// getMachineByField returns the machine that has one of values under the indexed field, or nil when no machine matches,
+// the match function verifies listed machines, the deleted machine matches only when it is the single matching machine
+func getMachineByField(c client.Client, node *v1.Node, field string, values []string, match func(*mapiv1.Machine) bool) (*mapiv1.Machine, error) {
+	matched := map[types.NamespacedName]*mapiv1.Machine{}
+	for _, value := range values {
+		machines := &mapiv1.MachineList{}
+		if err := c.List(context.TODO(), machines, client.MatchingField(field, value)); err != nil {
+			return nil, err
+		}
+
+		for i := range machines.Items {
+			machine := &machines.Items[i]
+			if match(machine) {
+				matched[types.NamespacedName{Namespace: machine.Namespace, Name: machine.Name}] = machine
+			}
+		}
+	}
+
+	var candidates []*mapiv1.Machine
+	var deleted *mapiv1.Machine
+	for _, machine := range matched {
+		if machine.DeletionTimestamp != nil {
+			deleted = machine
+			continue
+		}
+		candidates = append(candidates, machine)
+	}
+
+	switch {
+	case len(candidates) == 1:
+		glog.V(4).Infof("Node %s matches machine %s by %s", node.Name, candidates[0].Name, field)
+		return candidates[0], nil
+	case len(candidates) > 1:
+		var names []string
+		for _, machine := range candidates {
+			names = append(names, machine.Name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("node %s matches several machines %v by %s", node.Name, names, field)
+	case len(matched) == 1:
+		return deleted, nil
+	}
+	return nil, nil
+}
+
+// IsControlPlane returns true when the machine has the master role
+func IsControlPlane(machine *mapiv1.Machine) bool {
+	return machine.Labels[consts.MachineRoleLabel] == consts.MachineRoleMaster
+}
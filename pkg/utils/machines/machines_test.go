@@ -158,3 +158,117 @@ func TestGetNodeByMachine(t *testing.T) {
 		}
 	}
 }
+
+func TestGetMachineByNode(t *testing.T) {
+	nodeWithAnnotation := mrtesting.NewNode("nodeWithAnnotation", true, "machineWithAnnotation")
+	machineWithAnnotation := mrtesting.NewMachine("machineWithAnnotation", "", "bareMetalHost1")
+
+	nodeWithClusterAPIAnnotations := newNodeWithoutAnnotation("nodeWithClusterAPIAnnotations")
+	nodeWithClusterAPIAnnotations.Annotations[consts.AnnotationClusterAPIMachine] = "machineWithClusterAPIAnnotations"
+	nodeWithClusterAPIAnnotations.Annotations[consts.AnnotationClusterAPINamespace] = consts.NamespaceOpenshiftMachineAPI
+	machineWithClusterAPIAnnotations := mrtesting.NewMachine("machineWithClusterAPIAnnotations", "", "bareMetalHost8")
+
+	nodeWithProviderID := newNodeWithoutAnnotation("nodeWithProviderID")
+	nodeWithProviderID.Spec.ProviderID = "baremetalhost:///openshift-machine-api/bareMetalHost2"
+	machineWithProviderID := mrtesting.NewMachine("machineWithProviderID", "", "bareMetalHost2")
+	machineWithProviderID.Spec.ProviderID = &nodeWithProviderID.Spec.ProviderID
+
+	nodeWithNodeRef := newNodeWithoutAnnotation("nodeWithNodeRef")
+	machineWithNodeRef := mrtesting.NewMachine("machineWithNodeRef", nodeWithNodeRef.Name, "bareMetalHost3")
+
+	nodeWithAddress := newNodeWithoutAnnotation("nodeWithAddress")
+	nodeWithAddress.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.111.24"}}
+	machineWithAddress := mrtesting.NewMachine("machineWithAddress", "", "bareMetalHost4")
+	machineWithAddress.Status.Addresses = []corev1.NodeAddress{
+		{Type: corev1.NodeHostName, Address: "worker-4"},
+		{Type: corev1.NodeInternalIP, Address: "192.168.111.24"},
+	}
+
+	nodeWithStaleAnnotation := mrtesting.NewNode("nodeWithStaleAnnotation", true, "deletedMachine")
+	machineWithHostName := mrtesting.NewMachine("machineWithHostName", "", "bareMetalHost5")
+	machineWithHostName.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeHostName, Address: nodeWithStaleAnnotation.Name}}
+
+	nodeWithSeveralMachines := newNodeWithoutAnnotation("nodeWithSeveralMachines")
+	nodeWithSeveralMachines.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.111.26"}}
+	machineWithSameAddress1 := mrtesting.NewMachine("machineWithSameAddress1", "", "bareMetalHost6")
+	machineWithSameAddress1.Status.Addresses = nodeWithSeveralMachines.Status.Addresses
+	machineWithSameAddress2 := mrtesting.NewMachine("machineWithSameAddress2", "", "bareMetalHost7")
+	machineWithSameAddress2.Status.Addresses = nodeWithSeveralMachines.Status.Addresses
+
+	nodeWithoutMachine := newNodeWithoutAnnotation("nodeWithoutMachine")
+	nodeWithoutMachine.Spec.ProviderID = "baremetalhost:///openshift-machine-api/unknown"
+
+	fakeClient := fake.NewFakeClient(
+		machineWithAnnotation,
+		machineWithClusterAPIAnnotations,
+		machineWithProviderID,
+		machineWithNodeRef,
+		machineWithAddress,
+		machineWithHostName,
+		machineWithSameAddress1,
+		machineWithSameAddress2,
+	)
+
+	testsCases := []struct {
+		node             *corev1.Node
+		expectedMachine  string
+		expectedError    bool
+		expectedNotFound bool
+	}{
+		{
+			node:            nodeWithAnnotation,
+			expectedMachine: machineWithAnnotation.Name,
+		},
+		{
+			node:            nodeWithClusterAPIAnnotations,
+			expectedMachine: machineWithClusterAPIAnnotations.Name,
+		},
+		{
+			node:            nodeWithProviderID,
+			expectedMachine: machineWithProviderID.Name,
+		},
+		{
+			node:            nodeWithNodeRef,
+			expectedMachine: machineWithNodeRef.Name,
+		},
+		{
+			node:            nodeWithAddress,
+			expectedMachine: machineWithAddress.Name,
+		},
+		{
+			node:            nodeWithStaleAnnotation,
+			expectedMachine: machineWithHostName.Name,
+		},
+		{
+			node:          nodeWithSeveralMachines,
+			expectedError: true,
+		},
+		{
+			node:             nodeWithoutMachine,
+			expectedError:    true,
+			expectedNotFound: true,
+		},
+	}
+
+	for _, tc := range testsCases {
+		machine, err := GetMachineByNode(fakeClient, tc.node)
+		if tc.expectedError != (err != nil) {
+			t.Errorf("Test case: %s. Expected error: %t, got: %v", tc.node.Name, tc.expectedError, err)
+		}
+
+		if IsMachineNotFound(err) != tc.expectedNotFound {
+			t.Errorf("Test case: %s. Expected machine not found error: %t, got: %v", tc.node.Name, tc.expectedNotFound, err)
+		}
+
+		if !tc.expectedError && (machine == nil || machine.Name != tc.expectedMachine) {
+			t.Errorf("Test case: %s. Expected machine: %q, got: %v", tc.node.Name, tc.expectedMachine, machine)
+		}
+	}
+}
+
+// newNodeWithoutAnnotation returns the node without the machine annotation, like the freshly joined node
+func newNodeWithoutAnnotation(name string) *corev1.Node {
+	node := mrtesting.NewNode(name, true, "")
+	delete(node.Annotations, consts.AnnotationMachine)
+	return node
+}
@@ -0,0 +1,153 @@
+package nodes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// nodeLifecycleTaintPrefix contains the prefix of taints that the node lifecycle controller manages
+	nodeLifecycleTaintPrefix = "node.kubernetes.io/"
+)
+
+// GetNodeByMachine returns the node object referenced by machine, it returns NotFound error
+// when the machine does not have the node reference or the node does not exist
+func GetNodeByMachine(c client.Client, machine *mapiv1.Machine) (*corev1.Node, error) {
+	if machine.Status.NodeRef == nil {
+		return nil, errors.NewNotFound(corev1.Resource("ObjectReference"), machine.Name)
+	}
+
+	node := &corev1.Node{}
+	key := client.ObjectKey{
+		Name:      machine.Status.NodeRef.Name,
+		Namespace: machine.Status.NodeRef.Namespace,
+	}
+
+	if err := c.Get(context.TODO(), key, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// DeleteNodeByMachine deletes the node that mapped to specified machine
+func DeleteNodeByMachine(c client.Client, machine *mapiv1.Machine) error {
+	node, err := GetNodeByMachine(c, machine)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Warningf("The machine %q node does not exist", machine.Name)
+			return nil
+		}
+		return err
+	}
+
+	return c.Delete(context.TODO(), node)
+}
+
+// GetBootIDByMachine returns the boot ID of the node referenced by machine, or an empty string
+// when the node does not exist
+func GetBootIDByMachine(c client.Client, machine *mapiv1.Machine) (string, error) {
+	node, err := GetNodeByMachine(c, machine)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return node.Status.NodeInfo.BootID, nil
+}
+
+// IsRebooted returns true when the node boot ID differs from the boot ID recorded at the start of the attempt.
+// Without the recorded boot ID, it requires the powered on host and the node that became ready after the attempt start.
+func IsRebooted(node *corev1.Node, mr *mrv1.MachineRemediation) bool {
+	if mr.Status.NodeBootID == "" {
+		if !conditions.MachineRemediationHasCondition(mr, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue) {
+			return false
+		}
+
+		ready := conditions.GetNodeCondition(node, corev1.NodeReady)
+		startTime := strategies.GetAttemptStartTime(mr)
+		return ready != nil &&
+			ready.Status == corev1.ConditionTrue &&
+			startTime != nil &&
+			ready.LastTransitionTime.After(startTime.Time)
+	}
+
+	bootID := node.Status.NodeInfo.BootID
+	return bootID != "" && bootID != mr.Status.NodeBootID
+}
+
+// RemoveRebootAnnotation removes the reboot annotation from the node
+func RemoveRebootAnnotation(c client.Client, node *corev1.Node) error {
+	nodeCopy := node.DeepCopy()
+
+	if nodeCopy.Annotations == nil {
+		return nil
+	}
+
+	if _, ok := nodeCopy.Annotations[consts.AnnotationNodeMachineReboot]; !ok {
+		return nil
+	}
+
+	delete(nodeCopy.Annotations, consts.AnnotationNodeMachineReboot)
+	return c.Update(context.TODO(), nodeCopy)
+}
+
+// GetSavedTaints returns taints of the node that should be restored after the remediation,
+// taints managed by the node lifecycle controller reflect the unhealthy node, so they are skipped
+func GetSavedTaints(node *corev1.Node) []corev1.Taint {
+	var taints []corev1.Taint
+	for _, taint := range node.Spec.Taints {
+		if strings.HasPrefix(taint.Key, nodeLifecycleTaintPrefix) {
+			continue
+		}
+		taints = append(taints, taint)
+	}
+	return taints
+}
+
+// RestoreSavedMetadata restores labels, annotations and taints of the node saved under the machine remediation,
+// the node keeps its own labels and annotations when the machine remediation does not have saved ones
+func RestoreSavedMetadata(node *corev1.Node, mr *mrv1.MachineRemediation) {
+	if mr.Spec.SavedLabels != nil {
+		node.Labels = map[string]string{}
+		for k, v := range mr.Spec.SavedLabels {
+			node.Labels[k] = v
+		}
+	}
+
+	if mr.Spec.SavedAnnotations != nil {
+		node.Annotations = map[string]string{}
+		for k, v := range mr.Spec.SavedAnnotations {
+			node.Annotations[k] = v
+		}
+	}
+
+	for _, savedTaint := range mr.Spec.SavedTaints {
+		if !hasTaint(node, &savedTaint) {
+			node.Spec.Taints = append(node.Spec.Taints, savedTaint)
+		}
+	}
+}
+
+// hasTaint returns true when the node has the taint with the same key and effect
+func hasTaint(node *corev1.Node, taint *corev1.Taint) bool {
+	for i := range node.Spec.Taints {
+		if node.Spec.Taints[i].MatchTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package nodes
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func TestGetNodeByMachine(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	machine := mrtesting.NewMachine("machine", node.Name, "bareMetalHost")
+	machineWithoutNodeRef := mrtesting.NewMachine("machineWithoutNodeRef", "", "bareMetalHost")
+	machineWithoutNodeRef.Status.NodeRef = nil
+	machineWithWrongNode := mrtesting.NewMachine("machineWithWrongNode", "wrongNode", "bareMetalHost")
+
+	testsCases := []struct {
+		name             string
+		machine          *mapiv1.Machine
+		expectedNode     string
+		expectedNotFound bool
+	}{
+		{
+			name:             "with existing node",
+			machine:          machine,
+			expectedNode:     node.Name,
+			expectedNotFound: false,
+		},
+		{
+			name:             "without node reference",
+			machine:          machineWithoutNodeRef,
+			expectedNode:     "",
+			expectedNotFound: true,
+		},
+		{
+			name:             "with non existing node",
+			machine:          machineWithWrongNode,
+			expectedNode:     "",
+			expectedNotFound: true,
+		},
+	}
+
+	c := fake.NewFakeClient(node)
+	for _, tc := range testsCases {
+		node, err := GetNodeByMachine(c, tc.machine)
+		if tc.expectedNotFound != errors.IsNotFound(err) {
+			t.Errorf("Test case: %s. Expected NotFound error: %t, got: %v", tc.name, tc.expectedNotFound, err)
+		}
+
+		if node != nil && node.Name != tc.expectedNode {
+			t.Errorf("Test case: %s. Expected node: %q, got: %q", tc.name, tc.expectedNode, node.Name)
+		}
+	}
+}
+
+func TestRemoveRebootAnnotation(t *testing.T) {
+	node := mrtesting.NewNode("node", true, "machine")
+	node.Annotations[consts.AnnotationNodeMachineReboot] = ""
+
+	c := fake.NewFakeClient(node)
+	if err := RemoveRebootAnnotation(c, node); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	updatedNode := &corev1.Node{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: node.Name}, updatedNode); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if _, ok := updatedNode.Annotations[consts.AnnotationNodeMachineReboot]; ok {
+		t.Errorf("Expected node without %q annotation", consts.AnnotationNodeMachineReboot)
+	}
+}
+
+func TestGetSavedTaints(t *testing.T) {
+	node := mrtesting.NewNode("node", false, "machine")
+	node.Spec.Taints = []corev1.Taint{
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute},
+	}
+
+	taints := GetSavedTaints(node)
+	if len(taints) != 1 || taints[0].Key != "dedicated" {
+		t.Errorf("Expected only the dedicated taint, got: %v", taints)
+	}
+}
+
+func TestRestoreSavedMetadata(t *testing.T) {
+	dedicated := corev1.Taint{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}
+
+	testCases := []struct {
+		name                string
+		savedLabels         map[string]string
+		savedTaints         []corev1.Taint
+		nodeTaints          []corev1.Taint
+		expectedLabels      map[string]string
+		expectedTaintsCount int
+	}{
+		{
+			name:                "with saved labels and taints",
+			savedLabels:         mrtesting.FooBar(),
+			savedTaints:         []corev1.Taint{dedicated},
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:                "without saved labels",
+			savedTaints:         []corev1.Taint{dedicated},
+			nodeTaints:          []corev1.Taint{dedicated},
+			expectedLabels:      map[string]string{"node": "label"},
+			expectedTaintsCount: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		node := mrtesting.NewNode("node", true, "machine")
+		node.Labels = map[string]string{"node": "label"}
+		node.Spec.Taints = tc.nodeTaints
+
+		mr := mrtesting.NewMachineRemediation("mr", "machine", "", "")
+		mr.Spec.SavedLabels = tc.savedLabels
+		mr.Spec.SavedTaints = tc.savedTaints
+
+		RestoreSavedMetadata(node, mr)
+		if !reflect.DeepEqual(node.Labels, tc.expectedLabels) {
+			t.Errorf("Test case: %s. Expected labels: %v, got: %v", tc.name, tc.expectedLabels, node.Labels)
+		}
+		if len(node.Spec.Taints) != tc.expectedTaintsCount {
+			t.Errorf("Test case: %s. Expected %d taints, got: %v", tc.name, tc.expectedTaintsCount, node.Spec.Taints)
+		}
+	}
+}
+
+func TestIsRebooted(t *testing.T) {
+	testCases := []struct {
+		name           string
+		recordedBootID string
+		nodeBootID     string
+		poweredOn      bool
+		readyAfter     bool
+		expected       bool
+	}{
+		{
+			name:           "with different boot ID",
+			recordedBootID: "boot1",
+			nodeBootID:     "boot2",
+			expected:       true,
+		},
+		{
+			name:           "with the same boot ID",
+			recordedBootID: "boot1",
+			nodeBootID:     "boot1",
+			expected:       false,
+		},
+		{
+			name:           "with node without boot ID",
+			recordedBootID: "boot1",
+			expected:       false,
+		},
+		{
+			name:       "without recorded boot ID and with stale node readiness",
+			nodeBootID: "boot1",
+			poweredOn:  true,
+			expected:   false,
+		},
+		{
+			name:       "without recorded boot ID and without powered on host",
+			nodeBootID: "boot1",
+			readyAfter: true,
+			expected:   false,
+		},
+		{
+			name:       "without recorded boot ID and with node ready after the attempt start",
+			nodeBootID: "boot1",
+			poweredOn:  true,
+			readyAfter: true,
+			expected:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		node := mrtesting.NewNode("node", true, "machine")
+		node.Status.NodeInfo.BootID = tc.nodeBootID
+
+		mr := mrtesting.NewMachineRemediation("mr", "machine", "", "")
+		mr.Status.NodeBootID = tc.recordedBootID
+		if tc.poweredOn {
+			conditions.SetMachineRemediationCondition(mr, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue, mrv1.RemediationReasonInProgress, "")
+		}
+		if tc.readyAfter {
+			node.Status.Conditions[0].LastTransitionTime = metav1.Time{Time: mr.Status.StartTime.Add(time.Minute)}
+		}
+
+		if rebooted := IsRebooted(node, mr); rebooted != tc.expected {
+			t.Errorf("Test case: %s. Expected rebooted: %t, got: %t", tc.name, tc.expected, rebooted)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package policies
+
+import (
+	"context"
+	"sort"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetMachinePolicy returns the machine remediation policy that selects the machine, or nil when no policy
+// selects it, when several policies select the machine, the policy with the lowest name wins
+func GetMachinePolicy(c client.Client, machine *mapiv1.Machine) (*mrv1.MachineRemediationPolicy, error) {
+	policies := &mrv1.MachineRemediationPolicyList{}
+	if err := c.List(context.TODO(), policies, client.InNamespace(machine.Namespace)); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(policies.Items, func(i, j int) bool {
+		return policies.Items[i].Name < policies.Items[j].Name
+	})
+
+	for i := range policies.Items {
+		matched, err := IsMachineSelected(&policies.Items[i], machine)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			return &policies.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SetMachineStrategies replaces the type of the new machine remediation with strategies of the machine remediation
+// policy of the machine, when the policy has strategies
+func SetMachineStrategies(c client.Client, machine *mapiv1.Machine, mr *mrv1.MachineRemediation) error {
+	policy, err := GetMachinePolicy(c, machine)
+	if err != nil {
+		return err
+	}
+	if policy == nil || len(policy.Spec.Strategies) == 0 {
+		return nil
+	}
+
+	glog.Infof("Remediate machine %q with strategies of the policy %q", machine.Name, policy.Name)
+	mr.Spec.Type = ""
+	mr.Spec.Strategies = nil
+	for _, strategy := range policy.Spec.Strategies {
+		mr.Spec.Strategies = append(mr.Spec.Strategies, *strategy.DeepCopy())
+	}
+	return nil
+}
+
+// IsMachineSelected returns true when the policy selector matches labels of the machine,
+// the empty selector does not match any machine
+func IsMachineSelected(policy *mrv1.MachineRemediationPolicy, machine *mapiv1.Machine) (bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	if selector.Empty() {
+		return false, nil
+	}
+	return selector.Matches(labels.Set(machine.Labels)), nil
+}
+
+// GetSelectedMachines returns machines that the policy selects
+func GetSelectedMachines(c client.Client, policy *mrv1.MachineRemediationPolicy) ([]mapiv1.Machine, error) {
+	machineList, err := machines.GetMachinesByLabelSelector(c, &policy.Spec.Selector, policy.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if machineList == nil {
+		return nil, nil
+	}
+	return machineList.Items, nil
+}
+
+// CountActiveRemediations returns the number of machines with the machine remediation in progress.
+// The reader should read directly from the API server, so the remediation that just started counts.
+func CountActiveRemediations(reader client.Reader, namespace string, selectedMachines []mapiv1.Machine) (int, error) {
+	mrs := &mrv1.MachineRemediationList{}
+	if err := reader.List(context.TODO(), mrs, client.InNamespace(namespace)); err != nil {
+		return 0, err
+	}
+
+	selected := map[string]bool{}
+	for _, machine := range selectedMachines {
+		selected[machine.Name] = true
+	}
+
+	active := map[string]bool{}
+	for _, mr := range mrs.Items {
+		if selected[mr.Spec.MachineName] && IsActive(&mr) {
+			active[mr.Spec.MachineName] = true
+		}
+	}
+	return len(active), nil
+}
+
+// IsActive returns true when the machine remediation started and did not reach the final state yet
+func IsActive(mr *mrv1.MachineRemediation) bool {
+	switch mr.Status.State {
+	case "", mrv1.RemediationStatePending, mrv1.RemediationStateSucceeded, mrv1.RemediationStateFailed:
+		return false
+	default:
+		return true
+	}
+}
+
+// GetMaxConcurrent returns the number of selected machines that can be remediated at the same time,
+// and false when the policy does not limit the concurrency
+func GetMaxConcurrent(policy *mrv1.MachineRemediationPolicy, selectedMachines int) (int, bool, error) {
+	if policy.Spec.MaxConcurrent == nil {
+		return 0, false, nil
+	}
+
+	maxConcurrent, err := intstr.GetValueFromIntOrPercent(policy.Spec.MaxConcurrent, selectedMachines, true)
+	if err != nil {
+		return 0, false, err
+	}
+	return maxConcurrent, true, nil
+}
+
+// GetDisallowedType returns the type of the machine remediation strategy, that the policy does not allow,
+// or an empty type when the policy allows all strategies of the machine remediation
+func GetDisallowedType(policy *mrv1.MachineRemediationPolicy, mr *mrv1.MachineRemediation) mrv1.RemediationType {
+	// the policy without strategies does not restrict remediation types
+	if len(policy.Spec.Strategies) == 0 {
+		return ""
+	}
+
+	allowed := map[mrv1.RemediationType]bool{}
+	for _, strategy := range policy.Spec.Strategies {
+		allowed[strategy.Type] = true
+	}
+
+	for _, strategy := range strategies.GetStrategies(mr) {
+		if !allowed[strategy.Type] {
+			return strategy.Type
+		}
+	}
+	return ""
+}
@@ -0,0 +1,151 @@
+package policies
+
+import (
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+func TestGetMachinePolicy(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	otherMachine := mrtesting.NewMachine("otherMachine", "otherNode", "")
+	otherMachine.Labels = map[string]string{"pool": "other"}
+
+	policyWithEmptySelector := mrtesting.NewMachineRemediationPolicy("a-empty", nil)
+	policyWithEmptySelector.Spec.Selector = *mrtesting.NewSelector(nil)
+
+	c := fake.NewFakeClient(
+		mrtesting.NewMachineRemediationPolicy("c-policy", nil),
+		mrtesting.NewMachineRemediationPolicy("b-policy", nil),
+		policyWithEmptySelector,
+	)
+
+	testCases := []struct {
+		name           string
+		machine        *mapiv1.Machine
+		expectedPolicy string
+	}{
+		{
+			name:           "with machine selected by several policies",
+			machine:        machine,
+			expectedPolicy: "b-policy",
+		},
+		{
+			name:           "with machine not selected by any policy",
+			machine:        otherMachine,
+			expectedPolicy: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		policy, err := GetMachinePolicy(c, tc.machine)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected no error, got: %v", tc.name, err)
+		}
+
+		var name string
+		if policy != nil {
+			name = policy.Name
+		}
+		if name != tc.expectedPolicy {
+			t.Errorf("Test case: %s. Expected policy: %q, got: %q", tc.name, tc.expectedPolicy, name)
+		}
+	}
+}
+
+func TestGetMaxConcurrent(t *testing.T) {
+	absolute := intstr.FromInt(2)
+	percentage := intstr.FromString("30%")
+
+	testCases := []struct {
+		name            string
+		maxConcurrent   *intstr.IntOrString
+		expectedMax     int
+		expectedLimited bool
+	}{
+		{
+			name:            "without limit",
+			expectedLimited: false,
+		},
+		{
+			name:            "with absolute limit",
+			maxConcurrent:   &absolute,
+			expectedMax:     2,
+			expectedLimited: true,
+		},
+		{
+			name:            "with percentage limit",
+			maxConcurrent:   &percentage,
+			expectedMax:     3,
+			expectedLimited: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		policy := mrtesting.NewMachineRemediationPolicy("policy", nil)
+		policy.Spec.MaxConcurrent = tc.maxConcurrent
+
+		max, limited, err := GetMaxConcurrent(policy, 10)
+		if err != nil {
+			t.Errorf("Test case: %s. Expected no error, got: %v", tc.name, err)
+		}
+		if max != tc.expectedMax || limited != tc.expectedLimited {
+			t.Errorf("Test case: %s. Expected %d, %t, got: %d, %t", tc.name, tc.expectedMax, tc.expectedLimited, max, limited)
+		}
+	}
+}
+
+func TestGetDisallowedType(t *testing.T) {
+	rebootOnly := []mrv1.RemediationStrategy{{Type: mrv1.RemediationTypeReboot}}
+
+	mrWithStrategies := mrtesting.NewMachineRemediation("mr", "machine", "", "")
+	mrWithStrategies.Spec.Strategies = []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+
+	testCases := []struct {
+		name               string
+		strategies         []mrv1.RemediationStrategy
+		machineRemediation *mrv1.MachineRemediation
+		expected           mrv1.RemediationType
+	}{
+		{
+			name:               "with allowed type",
+			strategies:         rebootOnly,
+			machineRemediation: mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, ""),
+			expected:           "",
+		},
+		{
+			name:               "with disallowed strategy",
+			strategies:         rebootOnly,
+			machineRemediation: mrWithStrategies,
+			expected:           mrv1.RemediationTypeRecreate,
+		},
+		{
+			name:               "with policy without strategies",
+			machineRemediation: mrWithStrategies,
+			expected:           "",
+		},
+	}
+
+	for _, tc := range testCases {
+		policy := mrtesting.NewMachineRemediationPolicy("policy", tc.strategies)
+		if remediationType := GetDisallowedType(policy, tc.machineRemediation); remediationType != tc.expected {
+			t.Errorf("Test case: %s. Expected type: %q, got: %q", tc.name, tc.expected, remediationType)
+		}
+	}
+}
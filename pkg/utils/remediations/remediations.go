@@ -0,0 +1,47 @@
+package remediations
+
+import (
+	"context"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// GetActiveMachineRemediations returns machine remediations that did not reach the final state
+func GetActiveMachineRemediations(c client.Reader) ([]mrv1.MachineRemediation, error) {
+	mrs := &mrv1.MachineRemediationList{}
+	if err := c.List(context.TODO(), mrs); err != nil {
+		return nil, err
+	}
+
+	active := []mrv1.MachineRemediation{}
+	for _, mr := range mrs.Items {
+		if mr.Status.EndTime == nil {
+			active = append(active, mr)
+		}
+	}
+	return active, nil
+}
+
+// GetRequests returns reconcile requests of machine remediations of the machine, or of the bare metal host
+// under the namespace/name key, the recreate keeps the host key under the machine remediation, so the remediation
+// matches the host after the deletion of its machine, the machine can be nil and the key can be empty
+func GetRequests(mrs []mrv1.MachineRemediation, machine *mapiv1.Machine, bmhKey string) []reconcile.Request {
+	requests := []reconcile.Request{}
+	for _, mr := range mrs {
+		matchesMachine := machine != nil && mr.Namespace == machine.Namespace && mr.Spec.MachineName == machine.Name
+		matchesHost := bmhKey != "" && mr.Annotations[consts.AnnotationBareMetalHost] == bmhKey
+		if matchesMachine || matchesHost {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: mr.Namespace, Name: mr.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,95 @@
+package remediations
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1.AddToScheme(scheme.Scheme)
+}
+
+func TestGetActiveMachineRemediations(t *testing.T) {
+	mrSucceeded := mrtesting.NewMachineRemediation("mrSucceeded", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStateSucceeded)
+	mrSucceeded.Status.EndTime = &metav1.Time{Time: time.Now()}
+
+	c := fake.NewFakeClient(
+		mrtesting.NewMachineRemediation("mrPowerOff", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff),
+		mrSucceeded,
+	)
+
+	mrs, err := GetActiveMachineRemediations(c)
+	if err != nil {
+		t.Fatalf("Expected: no error, got: %v", err)
+	}
+	if len(mrs) != 1 || mrs[0].Name != "mrPowerOff" {
+		t.Errorf("Expected: only the machine remediation mrPowerOff, got: %v", mrs)
+	}
+}
+
+func TestGetRequests(t *testing.T) {
+	mrMachine := mrtesting.NewMachineRemediation("mrMachine", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff)
+	mrRecreate := mrtesting.NewMachineRemediation("mrRecreate", "deletedMachine", mrv1.RemediationTypeRecreate, mrv1.RemediationStateProvisioning)
+	mrRecreate.Annotations = map[string]string{consts.AnnotationBareMetalHost: consts.NamespaceOpenshiftMachineAPI + "/recreatedHost"}
+	mrOther := mrtesting.NewMachineRemediation("mrOther", "otherMachine", mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	mrs := []mrv1.MachineRemediation{*mrMachine, *mrRecreate, *mrOther}
+
+	machineOtherNamespace := mrtesting.NewMachine("machine", "node", "host")
+	machineOtherNamespace.Namespace = "other"
+
+	testCases := []struct {
+		name             string
+		machine          *mapiv1.Machine
+		bmhKey           string
+		expectedRequests []string
+	}{
+		{
+			name:             "with machine",
+			machine:          mrtesting.NewMachine("machine", "node", "host"),
+			bmhKey:           consts.NamespaceOpenshiftMachineAPI + "/host",
+			expectedRequests: []string{"mrMachine"},
+		},
+		{
+			name:             "with new machine of the recreated host",
+			machine:          mrtesting.NewMachine("newMachine", "", "recreatedHost"),
+			bmhKey:           consts.NamespaceOpenshiftMachineAPI + "/recreatedHost",
+			expectedRequests: []string{"mrRecreate"},
+		},
+		{
+			name:             "with recreated host without machine",
+			bmhKey:           consts.NamespaceOpenshiftMachineAPI + "/recreatedHost",
+			expectedRequests: []string{"mrRecreate"},
+		},
+		{
+			name:             "with machine under other namespace",
+			machine:          machineOtherNamespace,
+			expectedRequests: []string{},
+		},
+		{
+			name:             "without machine and host",
+			expectedRequests: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		names := []string{}
+		for _, request := range GetRequests(mrs, tc.machine, tc.bmhKey) {
+			names = append(names, request.Name)
+		}
+		if !reflect.DeepEqual(names, tc.expectedRequests) {
+			t.Errorf("Test case: %s. Expected requests: %v, got: %v", tc.name, tc.expectedRequests, names)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package strategies
+
+import (
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetStrategies returns the ordered list of remediation strategies of the machine remediation,
+// the machine remediation without strategies has the single strategy with the type and timeouts of the spec
+func GetStrategies(mr *mrv1.MachineRemediation) []mrv1.RemediationStrategy {
+	if len(mr.Spec.Strategies) != 0 {
+		return mr.Spec.Strategies
+	}
+	return []mrv1.RemediationStrategy{
+		{
+			Type:     mr.Spec.Type,
+			Timeouts: mr.Spec.Timeouts,
+		},
+	}
+}
+
+// GetCurrentStrategy returns the remediation strategy of the last attempt
+func GetCurrentStrategy(mr *mrv1.MachineRemediation) mrv1.RemediationStrategy {
+	strategies := GetStrategies(mr)
+	index := len(mr.Status.Attempts) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(strategies) {
+		index = len(strategies) - 1
+	}
+	return strategies[index]
+}
+
+// GetNextStrategy returns the remediation strategy that follows the strategy of the last attempt,
+// or nil when the last attempt applied the last strategy
+func GetNextStrategy(mr *mrv1.MachineRemediation) *mrv1.RemediationStrategy {
+	strategies := GetStrategies(mr)
+	if len(mr.Status.Attempts) >= len(strategies) {
+		return nil
+	}
+	return &strategies[len(mr.Status.Attempts)]
+}
+
+// GetCurrentAttempt returns the last remediation attempt, or nil when the machine remediation does not have attempts
+func GetCurrentAttempt(mr *mrv1.MachineRemediation) *mrv1.RemediationAttempt {
+	if len(mr.Status.Attempts) == 0 {
+		return nil
+	}
+	return &mr.Status.Attempts[len(mr.Status.Attempts)-1]
+}
+
+// GetAttemptStartTime returns the start time of the last remediation attempt, objects that were created
+// before the controller started to track attempts fall back to the remediation start time
+func GetAttemptStartTime(mr *mrv1.MachineRemediation) *metav1.Time {
+	attempt := GetCurrentAttempt(mr)
+	if attempt != nil && attempt.StartTime != nil {
+		return attempt.StartTime
+	}
+	return mr.Status.StartTime
+}
+
+// StartAttempt adds the new remediation attempt with the strategy type
+func StartAttempt(mr *mrv1.MachineRemediation, strategy mrv1.RemediationStrategy, startTime metav1.Time) {
+	mr.Status.Attempts = append(mr.Status.Attempts, mrv1.RemediationAttempt{
+		Type:      strategy.Type,
+		StartTime: &startTime,
+	})
+}
+
+// FinishAttempt records the final state of the machine remediation under the last remediation attempt,
+// it returns false when the machine remediation does not have an unfinished attempt
+func FinishAttempt(mr *mrv1.MachineRemediation, endTime metav1.Time) bool {
+	attempt := GetCurrentAttempt(mr)
+	if attempt == nil || attempt.EndTime != nil {
+		return false
+	}
+
+	attempt.State = mr.Status.State
+	attempt.Reason = mr.Status.Reason
+	attempt.Message = mr.Status.Message
+	if mr.Status.EndTime != nil {
+		endTime = *mr.Status.EndTime
+	}
+	attempt.EndTime = &endTime
+	return true
+}
@@ -0,0 +1,85 @@
+package strategies
+
+import (
+	"testing"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetStrategies(t *testing.T) {
+	withoutStrategies := mrtesting.NewMachineRemediation("withoutStrategies", "machine", mrv1.RemediationTypeReboot, "")
+
+	withStrategies := mrtesting.NewMachineRemediation("withStrategies", "machine", "", mrv1.RemediationStateStarted)
+	withStrategies.Spec.Strategies = []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+	StartAttempt(withStrategies, withStrategies.Spec.Strategies[0], metav1.Now())
+
+	withLastAttempt := withStrategies.DeepCopy()
+	withLastAttempt.Name = "withLastAttempt"
+	StartAttempt(withLastAttempt, withStrategies.Spec.Strategies[1], metav1.Now())
+	StartAttempt(withLastAttempt, withStrategies.Spec.Strategies[2], metav1.Now())
+
+	testCases := []struct {
+		machineRemediation *mrv1.MachineRemediation
+		expectedCurrent    mrv1.RemediationType
+		expectedNext       mrv1.RemediationType
+	}{
+		{
+			machineRemediation: withoutStrategies,
+			expectedCurrent:    mrv1.RemediationTypeReboot,
+			expectedNext:       mrv1.RemediationTypeReboot,
+		},
+		{
+			machineRemediation: withStrategies,
+			expectedCurrent:    mrv1.RemediationTypeReboot,
+			expectedNext:       mrv1.RemediationTypeReboot,
+		},
+		{
+			machineRemediation: withLastAttempt,
+			expectedCurrent:    mrv1.RemediationTypeRecreate,
+			expectedNext:       "",
+		},
+	}
+
+	for _, tc := range testCases {
+		if current := GetCurrentStrategy(tc.machineRemediation); current.Type != tc.expectedCurrent {
+			t.Errorf("Test case: %s. Expected current strategy: %q, got: %q", tc.machineRemediation.Name, tc.expectedCurrent, current.Type)
+		}
+
+		var next mrv1.RemediationType
+		if strategy := GetNextStrategy(tc.machineRemediation); strategy != nil {
+			next = strategy.Type
+		}
+		if next != tc.expectedNext {
+			t.Errorf("Test case: %s. Expected next strategy: %q, got: %q", tc.machineRemediation.Name, tc.expectedNext, next)
+		}
+	}
+}
+
+func TestFinishAttempt(t *testing.T) {
+	mr := mrtesting.NewMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot, mrv1.RemediationStateFailed)
+	mr.Status.Reason = mrv1.RemediationReasonTimedOut
+	if FinishAttempt(mr, metav1.Now()) {
+		t.Errorf("Expected no finished attempt for the machine remediation without attempts")
+	}
+
+	StartAttempt(mr, GetCurrentStrategy(mr), *mr.Status.StartTime)
+	if !FinishAttempt(mr, metav1.Now()) {
+		t.Errorf("Expected finished attempt for the machine remediation with the started attempt")
+	}
+
+	attempt := GetCurrentAttempt(mr)
+	if attempt.State != mrv1.RemediationStateFailed || attempt.Reason != mrv1.RemediationReasonTimedOut || attempt.EndTime == nil {
+		t.Errorf("Expected the failed attempt with the timed out reason, got: %+v", attempt)
+	}
+
+	if FinishAttempt(mr, metav1.Now()) {
+		t.Errorf("Expected no finished attempt for the machine remediation with the finished attempt")
+	}
+}
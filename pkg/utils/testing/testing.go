@@ -8,12 +8,11 @@ import (
 
 	bmov1 "github.com/metal3-io/baremetal-operator/pkg/apis/metal3/v1alpha1"
 	osconfigv1 "github.com/openshift/api/config/v1"
-	maov1 "github.com/openshift/machine-api-operator/pkg/apis/healthchecking/v1beta1"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	"kubevirt.io/machine-remediation/pkg/consts"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
@@ -40,8 +39,8 @@ func NewSelectorFooBar() *metav1.LabelSelector {
 }
 
 // NewMachineHealthCheck returns new MachineHealthCheck object that can be used for testing
-func NewMachineHealthCheck(name string) *maov1.MachineHealthCheck {
-	return &maov1.MachineHealthCheck{
+func NewMachineHealthCheck(name string) *mrv1.MachineHealthCheck {
+	return &mrv1.MachineHealthCheck{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: consts.NamespaceOpenshiftMachineAPI,
@@ -49,10 +48,10 @@ func NewMachineHealthCheck(name string) *maov1.MachineHealthCheck {
 		TypeMeta: metav1.TypeMeta{
 			Kind: "MachineHealthCheck",
 		},
-		Spec: maov1.MachineHealthCheckSpec{
+		Spec: mrv1.MachineHealthCheckSpec{
 			Selector: *NewSelectorFooBar(),
 		},
-		Status: maov1.MachineHealthCheckStatus{},
+		Status: mrv1.MachineHealthCheckStatus{},
 	}
 }
 
@@ -135,6 +134,53 @@ func NewMachineRemediation(name string, machineName string, remediationType mrv1
 	}
 }
 
+// NewMachineRemediationPolicy returns new machine remediation policy object with the foo:bar selector,
+// that can be used for testing
+func NewMachineRemediationPolicy(name string, strategies []mrv1.RemediationStrategy) *mrv1.MachineRemediationPolicy {
+	return &mrv1.MachineRemediationPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "MachineRemediationPolicy"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+		},
+		Spec: mrv1.MachineRemediationPolicySpec{
+			Selector:   *NewSelectorFooBar(),
+			Strategies: strategies,
+		},
+	}
+}
+
+// NewMachineDisruptionBudget returns new machine disruption budget object with the foo:bar selector,
+// that can be used for testing
+func NewMachineDisruptionBudget(name string, minAvailable *int32, maxUnavailable *int32) *mrv1.MachineDisruptionBudget {
+	return &mrv1.MachineDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{Kind: "MachineDisruptionBudget"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: consts.NamespaceOpenshiftMachineAPI,
+		},
+		Spec: mrv1.MachineDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector:       NewSelectorFooBar(),
+		},
+	}
+}
+
+// NewPoweredOnMachineRemediation returns new machine remediation object in the power on state,
+// with the host that powered on at the specific time
+func NewPoweredOnMachineRemediation(name string, machineName string, poweredOnTime time.Time) *mrv1.MachineRemediation {
+	mr := NewMachineRemediation(name, machineName, mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn)
+	mr.Status.Conditions = []mrv1.MachineRemediationCondition{
+		{
+			Type:               mrv1.MachineRemediationConditionPoweredOn,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: metav1.Time{Time: poweredOnTime},
+		},
+	}
+	return mr
+}
+
 // NewNode returns new node object that can be used for testing
 func NewNode(name string, ready bool, machineName string) *corev1.Node {
 	nodeReadyStatus := corev1.ConditionTrue
@@ -0,0 +1,183 @@
+package timeouts
+
+import (
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/utils/conditions"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultReboot contains the default timeout of the whole reboot remediation
+	DefaultReboot = 30 * time.Minute
+	// DefaultRecreate contains the default timeout of the whole recreate remediation
+	DefaultRecreate = 60 * time.Minute
+	// DefaultPowerOff contains the default timeout of the power off confirmation
+	DefaultPowerOff = 5 * time.Minute
+	// DefaultBoot contains the default timeout of the host boot
+	DefaultBoot = 15 * time.Minute
+	// DefaultNodeReady contains the default timeout of the node readiness after the host boot
+	DefaultNodeReady = 10 * time.Minute
+)
+
+// Phase contains the name of the remediation phase that has the timeout
+type Phase string
+
+const (
+	// PhaseTotal contains the phase that covers the whole remediation
+	PhaseTotal Phase = "total"
+	// PhasePowerOff contains the phase when the remediator waits for the power off confirmation
+	PhasePowerOff Phase = "power off"
+	// PhaseBoot contains the phase when the remediator waits for the host to power on
+	PhaseBoot Phase = "boot"
+	// PhaseNodeReady contains the phase when the remediator waits for the node of the powered on host to be ready
+	PhaseNodeReady Phase = "node ready"
+)
+
+// Defaults contains timeouts that the controller uses when the machine remediation does not specify them.
+// The zero value disables the timeout. The drain timeout extends the enabled reboot timeout.
+type Defaults struct {
+	Reboot    time.Duration
+	Recreate  time.Duration
+	PowerOff  time.Duration
+	Boot      time.Duration
+	NodeReady time.Duration
+	Drain     time.Duration
+}
+
+// NewDefaults returns new Defaults object with the default timeouts
+func NewDefaults() *Defaults {
+	return &Defaults{
+		Reboot:    DefaultReboot,
+		Recreate:  DefaultRecreate,
+		PowerOff:  DefaultPowerOff,
+		Boot:      DefaultBoot,
+		NodeReady: DefaultNodeReady,
+	}
+}
+
+// WithDrain returns the copy of default timeouts with the drain timeout.
+// The machine remediation policy of the machine can override the default drain timeout.
+func (d *Defaults) WithDrain(drain time.Duration) *Defaults {
+	withDrain := *d
+	withDrain.Drain = drain
+	return &withDrain
+}
+
+// Expired returns the phase of the machine remediation that runs longer than its timeout, or an empty phase.
+// The total timeout is measured from the start time of the current attempt, phase timeouts of the reboot are measured
+// from the time when the remediation moved to the phase.
+func (d *Defaults) Expired(machineRemediation *mrv1.MachineRemediation, now time.Time) Phase {
+	if d.TotalExpired(machineRemediation, now) {
+		return PhaseTotal
+	}
+
+	specTimeouts := getSpecTimeouts(machineRemediation)
+
+	// the recreate does not have phases that depend on the host power state
+	if strategies.GetCurrentStrategy(machineRemediation).Type != mrv1.RemediationTypeReboot {
+		return ""
+	}
+
+	stateTransitionTime := GetStateTransitionTime(machineRemediation)
+	switch machineRemediation.Status.State {
+	case mrv1.RemediationStatePowerOff:
+		if isExpired(stateTransitionTime, getTimeout(specTimeouts.PowerOff, d.PowerOff), now) {
+			return PhasePowerOff
+		}
+	case mrv1.RemediationStatePowerOn:
+		if !conditions.MachineRemediationHasCondition(machineRemediation, mrv1.MachineRemediationConditionPoweredOn, corev1.ConditionTrue) {
+			if isExpired(stateTransitionTime, getTimeout(specTimeouts.Boot, d.Boot), now) {
+				return PhaseBoot
+			}
+			return ""
+		}
+
+		poweredOn := conditions.GetMachineRemediationCondition(machineRemediation, mrv1.MachineRemediationConditionPoweredOn)
+		if isExpired(&poweredOn.LastTransitionTime, getTimeout(specTimeouts.NodeReady, d.NodeReady), now) {
+			return PhaseNodeReady
+		}
+	}
+	return ""
+}
+
+// TotalExpired returns true when the machine remediation runs longer than its total timeout
+func (d *Defaults) TotalExpired(machineRemediation *mrv1.MachineRemediation, now time.Time) bool {
+	total := d.getTotal(strategies.GetCurrentStrategy(machineRemediation).Type)
+	return isExpired(strategies.GetAttemptStartTime(machineRemediation), getTimeout(getSpecTimeouts(machineRemediation).Total, total), now)
+}
+
+// Fill returns timeouts of the remediation type, with default timeouts in place of timeouts that are not specified,
+// phase timeouts are filled only for the reboot
+func (d *Defaults) Fill(remediationType mrv1.RemediationType, specified *mrv1.RemediationTimeouts) *mrv1.RemediationTimeouts {
+	filled := &mrv1.RemediationTimeouts{}
+	if specified != nil {
+		filled = specified.DeepCopy()
+	}
+
+	fillTimeout(&filled.Total, d.getTotal(remediationType))
+
+	if remediationType == mrv1.RemediationTypeReboot {
+		fillTimeout(&filled.PowerOff, d.PowerOff)
+		fillTimeout(&filled.Boot, d.Boot)
+		fillTimeout(&filled.NodeReady, d.NodeReady)
+	}
+	return filled
+}
+
+// getTotal returns the default total timeout of the remediation type.
+// The drain before the power off extends the reboot timeout.
+func (d *Defaults) getTotal(remediationType mrv1.RemediationType) time.Duration {
+	if remediationType == mrv1.RemediationTypeRecreate {
+		return d.Recreate
+	}
+	if d.Reboot == 0 {
+		return 0
+	}
+	return d.Reboot + d.Drain
+}
+
+// GetStateTransitionTime returns the time when the machine remediation moved to the current state,
+// objects that were created before the controller started to track the transition time fall back to the start time
+func GetStateTransitionTime(machineRemediation *mrv1.MachineRemediation) *metav1.Time {
+	if machineRemediation.Status.StateTransitionTime != nil {
+		return machineRemediation.Status.StateTransitionTime
+	}
+	return machineRemediation.Status.StartTime
+}
+
+// getSpecTimeouts returns timeouts specified under the current strategy of the machine remediation
+func getSpecTimeouts(machineRemediation *mrv1.MachineRemediation) mrv1.RemediationTimeouts {
+	strategy := strategies.GetCurrentStrategy(machineRemediation)
+	if strategy.Timeouts == nil {
+		return mrv1.RemediationTimeouts{}
+	}
+	return *strategy.Timeouts
+}
+
+// getTimeout returns the timeout specified under the machine remediation or the default one
+func getTimeout(timeout *metav1.Duration, defaultTimeout time.Duration) time.Duration {
+	if timeout != nil {
+		return timeout.Duration
+	}
+	return defaultTimeout
+}
+
+// fillTimeout sets the timeout to the default one when the timeout is not specified
+func fillTimeout(timeout **metav1.Duration, defaultTimeout time.Duration) {
+	if *timeout == nil {
+		*timeout = &metav1.Duration{Duration: defaultTimeout}
+	}
+}
+
+// isExpired returns true when the timeout passed since the time, the zero timeout never expires
+func isExpired(since *metav1.Time, timeout time.Duration, now time.Time) bool {
+	if since == nil || timeout == 0 {
+		return false
+	}
+	return since.Time.Add(timeout).Before(now)
+}
@@ -0,0 +1,181 @@
+package timeouts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newMachineRemediation(
+	remediationType mrv1.RemediationType,
+	state mrv1.RemediationState,
+	startedBefore time.Duration,
+	transitionedBefore time.Duration,
+	specTimeouts *mrv1.RemediationTimeouts,
+) *mrv1.MachineRemediation {
+	mr := mrtesting.NewMachineRemediation("mr", "machine", remediationType, state)
+	mr.Status.StartTime = &metav1.Time{Time: time.Now().Add(-startedBefore)}
+	mr.Status.StateTransitionTime = &metav1.Time{Time: time.Now().Add(-transitionedBefore)}
+	mr.Spec.Timeouts = specTimeouts
+	return mr
+}
+
+// newEscalatedMachineRemediation returns the machine remediation that started 40 minutes ago with the reboot,
+// and escalated to the recreate 5 minutes ago
+func newEscalatedMachineRemediation() *mrv1.MachineRemediation {
+	mr := newMachineRemediation("", mrv1.RemediationStatePowerOff, 40*time.Minute, 5*time.Minute, nil)
+	mr.Spec.Strategies = []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate, Timeouts: &mrv1.RemediationTimeouts{Total: &metav1.Duration{Duration: 10 * time.Minute}}},
+	}
+	mr.Status.Attempts = []mrv1.RemediationAttempt{
+		{Type: mrv1.RemediationTypeReboot, StartTime: mr.Status.StartTime},
+		{Type: mrv1.RemediationTypeRecreate, StartTime: mr.Status.StateTransitionTime},
+	}
+	return mr
+}
+
+func TestExpired(t *testing.T) {
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		drain              time.Duration
+		expected           Phase
+	}{
+		{
+			name:               "with reboot in the power off state that did not time out",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff, 20*time.Minute, time.Minute, nil),
+			expected:           "",
+		},
+		{
+			name:               "with reboot in the power off state that timed out",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOff, 6*time.Minute, 6*time.Minute, nil),
+			expected:           PhasePowerOff,
+		},
+		{
+			name:               "with reboot that booted longer than the default timeout with the specified boot timeout",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn, 20*time.Minute, 16*time.Minute, &mrv1.RemediationTimeouts{Boot: &metav1.Duration{Duration: 20 * time.Minute}}),
+			expected:           "",
+		},
+		{
+			name:               "with reboot in the power on state that timed out on boot",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn, 20*time.Minute, 16*time.Minute, nil),
+			expected:           PhaseBoot,
+		},
+		{
+			name:               "with reboot that timed out on total",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn, 31*time.Minute, time.Minute, nil),
+			expected:           PhaseTotal,
+		},
+		{
+			name:               "with reboot that drained longer than the total timeout without the drain",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn, 31*time.Minute, time.Minute, nil),
+			drain:              10 * time.Minute,
+			expected:           "",
+		},
+		{
+			name:               "with reboot that has disabled total timeout",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeReboot, mrv1.RemediationStatePowerOn, 31*time.Minute, time.Minute, &mrv1.RemediationTimeouts{Total: &metav1.Duration{}}),
+			expected:           "",
+		},
+		{
+			name:               "with recreate in the power off state longer than the power off timeout",
+			machineRemediation: newMachineRemediation(mrv1.RemediationTypeRecreate, mrv1.RemediationStatePowerOff, 31*time.Minute, 31*time.Minute, nil),
+			expected:           "",
+		},
+		{
+			name:               "with recreate strategy that escalated from the reboot",
+			machineRemediation: newEscalatedMachineRemediation(),
+			expected:           "",
+		},
+		{
+			name:               "with reboot that timed out on node ready",
+			machineRemediation: mrtesting.NewPoweredOnMachineRemediation("mr", "machine", time.Now().Add(-11*time.Minute)),
+			expected:           PhaseNodeReady,
+		},
+		{
+			name:               "with reboot that waits for the node ready",
+			machineRemediation: mrtesting.NewPoweredOnMachineRemediation("mr", "machine", time.Now().Add(-time.Minute)),
+			expected:           "",
+		},
+	}
+
+	defaults := NewDefaults()
+	for _, tc := range testCases {
+		if phase := defaults.WithDrain(tc.drain).Expired(tc.machineRemediation, time.Now()); phase != tc.expected {
+			t.Errorf("%s failed, expected expired phase: %q, got: %q", tc.name, tc.expected, phase)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	minute := &metav1.Duration{Duration: time.Minute}
+	defaults := NewDefaults()
+
+	testCases := []struct {
+		name            string
+		remediationType mrv1.RemediationType
+		drain           time.Duration
+		specified       *mrv1.RemediationTimeouts
+		expected        *mrv1.RemediationTimeouts
+	}{
+		{
+			name:            "with reboot with drain",
+			remediationType: mrv1.RemediationTypeReboot,
+			drain:           time.Minute,
+			expected: &mrv1.RemediationTimeouts{
+				Total:     &metav1.Duration{Duration: defaults.Reboot + time.Minute},
+				PowerOff:  &metav1.Duration{Duration: defaults.PowerOff},
+				Boot:      &metav1.Duration{Duration: defaults.Boot},
+				NodeReady: &metav1.Duration{Duration: defaults.NodeReady},
+			},
+		},
+		{
+			name:            "with recreate with drain",
+			remediationType: mrv1.RemediationTypeRecreate,
+			drain:           time.Minute,
+			expected: &mrv1.RemediationTimeouts{
+				Total: &metav1.Duration{Duration: defaults.Recreate},
+			},
+		},
+		{
+			name:            "with reboot without specified timeouts",
+			remediationType: mrv1.RemediationTypeReboot,
+			expected: &mrv1.RemediationTimeouts{
+				Total:     &metav1.Duration{Duration: defaults.Reboot},
+				PowerOff:  &metav1.Duration{Duration: defaults.PowerOff},
+				Boot:      &metav1.Duration{Duration: defaults.Boot},
+				NodeReady: &metav1.Duration{Duration: defaults.NodeReady},
+			},
+		},
+		{
+			name:            "with reboot that has specified timeouts",
+			remediationType: mrv1.RemediationTypeReboot,
+			specified:       &mrv1.RemediationTimeouts{Total: minute, Boot: minute},
+			expected: &mrv1.RemediationTimeouts{
+				Total:     minute,
+				PowerOff:  &metav1.Duration{Duration: defaults.PowerOff},
+				Boot:      minute,
+				NodeReady: &metav1.Duration{Duration: defaults.NodeReady},
+			},
+		},
+		{
+			name:            "with recreate without specified timeouts",
+			remediationType: mrv1.RemediationTypeRecreate,
+			expected: &mrv1.RemediationTimeouts{
+				Total: &metav1.Duration{Duration: defaults.Recreate},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		if filled := defaults.WithDrain(tc.drain).Fill(tc.remediationType, tc.specified); !reflect.DeepEqual(filled, tc.expected) {
+			t.Errorf("%s failed, expected timeouts: %+v, got: %+v", tc.name, tc.expected, filled)
+		}
+	}
+}
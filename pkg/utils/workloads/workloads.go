@@ -0,0 +1,92 @@
+package workloads
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Releaser releases workloads of the fenced node, without the deletion of the node object,
+// so stateful workloads can fail over to other nodes
+type Releaser struct {
+	client client.Client
+	reader client.Reader
+}
+
+// NewReleaser returns new Releaser object
+func NewReleaser(mgr manager.Manager) *Releaser {
+	return &Releaser{
+		client: mgr.GetClient(),
+		// pods and volume attachments are read directly from the API server, to avoid the cache of all of them
+		reader: mgr.GetAPIReader(),
+	}
+}
+
+// NewFakeReleaser returns new Releaser object with provided client, it should be used only for tests
+func NewFakeReleaser(c client.Client) *Releaser {
+	return &Releaser{
+		client: c,
+		reader: c,
+	}
+}
+
+// DeletePods force deletes all pods bound to the node and returns the number of deleted pods,
+// it should be called only when the node has power off state
+func (r *Releaser) DeletePods(node *corev1.Node) (int, error) {
+	pods := &corev1.PodList{}
+	if err := r.reader.List(context.TODO(), pods, client.MatchingField("spec.nodeName", node.Name)); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+
+		glog.V(4).Infof("Force delete pod %s/%s of node %q", pod.Namespace, pod.Name, node.Name)
+		if err := r.client.Delete(context.TODO(), pod, client.GracePeriodSeconds(0)); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DeleteVolumeAttachments deletes all volume attachments that point to the node and returns the number of deleted attachments,
+// it should be called only when the node has power off state
+func (r *Releaser) DeleteVolumeAttachments(node *corev1.Node) (int, error) {
+	volumeAttachments := &storagev1.VolumeAttachmentList{}
+	if err := r.reader.List(context.TODO(), volumeAttachments); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range volumeAttachments.Items {
+		volumeAttachment := &volumeAttachments.Items[i]
+		if volumeAttachment.Spec.NodeName != node.Name {
+			continue
+		}
+
+		glog.V(4).Infof("Delete volume attachment %q of node %q", volumeAttachment.Name, node.Name)
+		if err := r.client.Delete(context.TODO(), volumeAttachment); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
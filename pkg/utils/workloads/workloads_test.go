@@ -0,0 +1,161 @@
+package workloads
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const nodeName = "node"
+
+func newNode() *corev1.Node {
+	return &corev1.Node{
+		TypeMeta:   metav1.TypeMeta{Kind: "Node"},
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+	}
+}
+
+func newPod(name string, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+func newVolumeAttachment(name string, nodeName string) *storagev1.VolumeAttachment {
+	return &storagev1.VolumeAttachment{
+		TypeMeta:   metav1.TypeMeta{Kind: "VolumeAttachment"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			NodeName: nodeName,
+		},
+	}
+}
+
+func TestDeletePods(t *testing.T) {
+	podTerminating := newPod("terminating", nodeName)
+	podTerminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	testCases := []struct {
+		name            string
+		pods            []runtime.Object
+		expectedDeleted int
+		expectedPods    []string
+	}{
+		{
+			name:            "with node without pods",
+			pods:            []runtime.Object{},
+			expectedDeleted: 0,
+			expectedPods:    []string{},
+		},
+		{
+			name:            "with pods under the node",
+			pods:            []runtime.Object{newPod("pod1", nodeName), podTerminating},
+			expectedDeleted: 2,
+			expectedPods:    []string{},
+		},
+		{
+			name:            "with pods under other node",
+			pods:            []runtime.Object{newPod("pod1", nodeName), newPod("pod2", "other")},
+			expectedDeleted: 1,
+			expectedPods:    []string{"pod2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		fakeClient := fake.NewFakeClient(tc.pods...)
+		releaser := NewFakeReleaser(fakeClient)
+
+		deleted, err := releaser.DeletePods(newNode())
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if deleted != tc.expectedDeleted {
+			t.Errorf("%s failed, expected deleted pods: %d, got: %d", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		pods := &corev1.PodList{}
+		if err := fakeClient.List(context.TODO(), pods); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		podNames := []string{}
+		for _, pod := range pods.Items {
+			podNames = append(podNames, pod.Name)
+		}
+		sort.Strings(podNames)
+		if !reflect.DeepEqual(podNames, tc.expectedPods) {
+			t.Errorf("%s failed, expected pods: %v, got: %v", tc.name, tc.expectedPods, podNames)
+		}
+	}
+}
+
+func TestDeleteVolumeAttachments(t *testing.T) {
+	testCases := []struct {
+		name                      string
+		volumeAttachments         []runtime.Object
+		expectedDeleted           int
+		expectedVolumeAttachments []string
+	}{
+		{
+			name:                      "with node without volume attachments",
+			volumeAttachments:         []runtime.Object{},
+			expectedDeleted:           0,
+			expectedVolumeAttachments: []string{},
+		},
+		{
+			name: "with volume attachments under the node and other node",
+			volumeAttachments: []runtime.Object{
+				newVolumeAttachment("va1", nodeName),
+				newVolumeAttachment("va2", nodeName),
+				newVolumeAttachment("va3", "other"),
+			},
+			expectedDeleted:           2,
+			expectedVolumeAttachments: []string{"va3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		fakeClient := fake.NewFakeClient(tc.volumeAttachments...)
+		releaser := NewFakeReleaser(fakeClient)
+
+		deleted, err := releaser.DeleteVolumeAttachments(newNode())
+		if err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		if deleted != tc.expectedDeleted {
+			t.Errorf("%s failed, expected deleted volume attachments: %d, got: %d", tc.name, tc.expectedDeleted, deleted)
+		}
+
+		volumeAttachments := &storagev1.VolumeAttachmentList{}
+		if err := fakeClient.List(context.TODO(), volumeAttachments); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		names := []string{}
+		for _, volumeAttachment := range volumeAttachments.Items {
+			names = append(names, volumeAttachment.Name)
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, tc.expectedVolumeAttachments) {
+			t.Errorf("%s failed, expected volume attachments: %v, got: %v", tc.name, tc.expectedVolumeAttachments, names)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"encoding/json"
+
+	mrv1alpha1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// decodeMachineRemediation decodes the MachineRemediation object of the admission request
+// and converts it to the hub version
+func decodeMachineRemediation(decoder *admission.Decoder, req admission.Request) (*mrv1.MachineRemediation, error) {
+	mr := &mrv1.MachineRemediation{}
+	if req.Kind.Version != mrv1alpha1.SchemeGroupVersion.Version {
+		if err := decoder.Decode(req, mr); err != nil {
+			return nil, err
+		}
+		return mr, nil
+	}
+
+	spoke := &mrv1alpha1.MachineRemediation{}
+	if err := decoder.Decode(req, spoke); err != nil {
+		return nil, err
+	}
+	if err := spoke.ConvertTo(mr); err != nil {
+		return nil, err
+	}
+	return mr, nil
+}
+
+// encodeMachineRemediation encodes the MachineRemediation object in the version of the admission request
+func encodeMachineRemediation(req admission.Request, mr *mrv1.MachineRemediation) ([]byte, error) {
+	var obj runtime.Object = mr
+	if req.Kind.Version == mrv1alpha1.SchemeGroupVersion.Version {
+		spoke := &mrv1alpha1.MachineRemediation{}
+		if err := spoke.ConvertFrom(mr); err != nil {
+			return nil, err
+		}
+		obj = spoke
+	}
+
+	// the conversion does not keep the type meta, so set it to the one of the request
+	obj.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   req.Kind.Group,
+		Version: req.Kind.Version,
+		Kind:    req.Kind.Kind,
+	})
+	return json.Marshal(obj)
+}
@@ -0,0 +1,177 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	"kubevirt.io/machine-remediation/pkg/machineapi"
+	"kubevirt.io/machine-remediation/pkg/utils/nodes"
+	"kubevirt.io/machine-remediation/pkg/utils/policies"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ admission.Handler = &MachineRemediationDefaulter{}
+
+// MachineRemediationDefaulter fills defaults of MachineRemediation objects on the creation
+type MachineRemediationDefaulter struct {
+	client   client.Client
+	decoder  *admission.Decoder
+	timeouts *timeouts.Defaults
+}
+
+// NewMachineRemediationDefaulter returns new MachineRemediationDefaulter object
+func NewMachineRemediationDefaulter(c client.Client, defaultTimeouts *timeouts.Defaults) *MachineRemediationDefaulter {
+	return &MachineRemediationDefaulter{
+		client:   c,
+		timeouts: defaultTimeouts,
+	}
+}
+
+// InjectDecoder injects the decoder of admission requests
+func (d *MachineRemediationDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle fills the requester, the machine name of the Cluster API external remediation, the default strategy
+// and timeouts and the node metadata of the MachineRemediation object of the admission request
+func (d *MachineRemediationDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1beta1.Create {
+		return admission.Allowed("")
+	}
+
+	mr, err := decodeMachineRemediation(d.decoder, req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// the requester is always overwritten, so it can not be faked by the creator of the object
+	if mr.Annotations == nil {
+		mr.Annotations = map[string]string{}
+	}
+	mr.Annotations[consts.AnnotationRequestedBy] = req.UserInfo.Username
+
+	// the MachineHealthCheck creates the machine remediation from the template without the machine name,
+	// but with the owner reference of the unhealthy Cluster API machine
+	if mr.Spec.MachineName == "" {
+		if owner := machineapi.GetMachineOwner(mr); owner != nil {
+			mr.Spec.MachineName = owner.Name
+		}
+	}
+
+	machine, err := d.getMachine(mr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if err := d.setDefaultStrategies(mr, machine); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if err := d.saveNodeMetadata(mr, machine); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	data, err := encodeMachineRemediation(req, mr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, data)
+}
+
+// getMachine returns the machine of the machine remediation, or nil when the machine does not exist
+func (d *MachineRemediationDefaulter) getMachine(mr *mrv1.MachineRemediation) (*mapiv1.Machine, error) {
+	machine := &mapiv1.Machine{}
+	key := types.NamespacedName{
+		Namespace: mr.Namespace,
+		Name:      mr.Spec.MachineName,
+	}
+	if err := d.client.Get(context.TODO(), key, machine); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return machine, nil
+}
+
+// setDefaultStrategies sets the policy strategies, or the reboot type, when the machine remediation has neither.
+// It fills default timeouts of the remediation type or of each strategy. The drain timeout of the policy
+// extends the reboot timeout instead of the default drain timeout.
+func (d *MachineRemediationDefaulter) setDefaultStrategies(mr *mrv1.MachineRemediation, machine *mapiv1.Machine) error {
+	var policy *mrv1.MachineRemediationPolicy
+	if machine != nil {
+		var err error
+		if policy, err = policies.GetMachinePolicy(d.client, machine); err != nil {
+			return err
+		}
+	}
+
+	if mr.Spec.Type == "" && len(mr.Spec.Strategies) == 0 {
+		if policy != nil && len(policy.Spec.Strategies) != 0 {
+			mr.Spec.Strategies = policy.Spec.Strategies
+		} else {
+			mr.Spec.Type = mrv1.RemediationTypeReboot
+		}
+	}
+
+	defaultTimeouts := d.timeouts
+	if policy != nil && policy.Spec.Drain != nil {
+		defaultTimeouts = d.timeouts.WithDrain(policy.Spec.Drain.Timeout.Duration)
+	}
+
+	if len(mr.Spec.Strategies) == 0 {
+		if isKnownType(mr.Spec.Type) {
+			mr.Spec.Timeouts = defaultTimeouts.Fill(mr.Spec.Type, mr.Spec.Timeouts)
+		}
+		return nil
+	}
+
+	for i := range mr.Spec.Strategies {
+		strategy := &mr.Spec.Strategies[i]
+		if isKnownType(strategy.Type) {
+			strategy.Timeouts = defaultTimeouts.Fill(strategy.Type, strategy.Timeouts)
+		}
+	}
+	return nil
+}
+
+// saveNodeMetadata saves labels, annotations and taints of the machine node, that the machine remediation
+// does not have yet, the missing machine or the machine without the node leave the machine remediation untouched
+func (d *MachineRemediationDefaulter) saveNodeMetadata(mr *mrv1.MachineRemediation, machine *mapiv1.Machine) error {
+	if machine == nil || (mr.Spec.SavedLabels != nil && mr.Spec.SavedAnnotations != nil && mr.Spec.SavedTaints != nil) {
+		return nil
+	}
+
+	node, err := nodes.GetNodeByMachine(d.client, machine)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.Warningf("The machine %q node does not exist, the node metadata is not saved", machine.Name)
+			return nil
+		}
+		return err
+	}
+
+	if mr.Spec.SavedLabels == nil {
+		mr.Spec.SavedLabels = node.Labels
+	}
+	if mr.Spec.SavedAnnotations == nil {
+		mr.Spec.SavedAnnotations = node.Annotations
+	}
+	if mr.Spec.SavedTaints == nil {
+		mr.Spec.SavedTaints = nodes.GetSavedTaints(node)
+	}
+	return nil
+}
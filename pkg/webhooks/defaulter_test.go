@@ -0,0 +1,254 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	mrv1alpha1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+	"kubevirt.io/machine-remediation/pkg/utils/timeouts"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// newFakeDefaulter returns a new MachineRemediationDefaulter with a fake client
+func newFakeDefaulter(initObjects ...runtime.Object) *MachineRemediationDefaulter {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		panic(err)
+	}
+
+	d := NewMachineRemediationDefaulter(fake.NewFakeClient(initObjects...), timeouts.NewDefaults())
+	if err := d.InjectDecoder(decoder); err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// applyPatches applies patches of the admission response to the object of the request
+// and returns the patched machine remediation converted to the hub version
+func applyPatches(t *testing.T, req admission.Request, response admission.Response) *mrv1.MachineRemediation {
+	patches, err := json.Marshal(response.Patches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := jsonpatch.DecodePatch(patches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := patch.Apply(req.Object.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patchedReq := req
+	patchedReq.Object = runtime.RawExtension{Raw: data}
+	mr, err := decodeMachineRemediation(decoder, patchedReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mr
+}
+
+func TestDefaulterHandle(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	node := mrtesting.NewNode("node", false, "machine")
+	node.Labels = mrtesting.FooBar()
+	node.Spec.Taints = []corev1.Taint{
+		{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "node.kubernetes.io/unreachable", Effect: corev1.TaintEffectNoExecute},
+	}
+	machineWithoutNode := mrtesting.NewMachine("machineWithoutNode", "missingNode", "")
+	machineWithPolicy := mrtesting.NewMachine("machineWithPolicy", "missingNode", "")
+	machineWithPolicy.Labels = map[string]string{"pool": "storage"}
+
+	policy := mrtesting.NewMachineRemediationPolicy("policy", []mrv1.RemediationStrategy{{Type: mrv1.RemediationTypeRecreate}})
+	policy.Spec.Selector = *mrtesting.NewSelector(machineWithPolicy.Labels)
+
+	machineWithDrainPolicy := mrtesting.NewMachine("machineWithDrainPolicy", "missingNode", "")
+	machineWithDrainPolicy.Labels = map[string]string{"pool": "compute"}
+	drainPolicy := mrtesting.NewMachineRemediationPolicy("drainPolicy", nil)
+	drainPolicy.Spec.Selector = *mrtesting.NewSelector(machineWithDrainPolicy.Labels)
+	drainPolicy.Spec.Drain = &mrv1.DrainPolicy{Timeout: metav1.Duration{Duration: 20 * time.Minute}}
+
+	defaults := timeouts.NewDefaults()
+	rebootTimeouts := defaults.Fill(mrv1.RemediationTypeReboot, nil)
+	recreateTimeouts := defaults.Fill(mrv1.RemediationTypeRecreate, nil)
+
+	mrWithSavedLabels := newMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot)
+	mrWithSavedLabels.Spec.SavedLabels = map[string]string{"saved": "label"}
+
+	mrFakeRequester := newMachineRemediation("mr", "machine", mrv1.RemediationTypeRecreate)
+	mrFakeRequester.Annotations = map[string]string{consts.AnnotationRequestedBy: "admin"}
+
+	mrWithStrategies := newMachineRemediation("mr", "machine", "")
+	mrWithStrategies.Spec.Strategies = []mrv1.RemediationStrategy{
+		{
+			Type:     mrv1.RemediationTypeReboot,
+			Timeouts: &mrv1.RemediationTimeouts{Total: &metav1.Duration{Duration: time.Minute}},
+		},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+	strategyRebootTimeouts := defaults.Fill(mrv1.RemediationTypeReboot, mrWithStrategies.Spec.Strategies[0].Timeouts)
+
+	mrFromTemplate := newMachineRemediation("machine", "", mrv1.RemediationTypeReboot)
+	mrFromTemplate.OwnerReferences = []metav1.OwnerReference{{APIVersion: "cluster.x-k8s.io/v1alpha3", Kind: "Machine", Name: "machine", UID: "1"}}
+
+	mrv1alpha1WithoutType := &mrv1alpha1.MachineRemediation{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: mrv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "MachineRemediation",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "mrv1alpha1", Namespace: consts.NamespaceOpenshiftMachineAPI},
+		Spec: mrv1alpha1.MachineRemediationSpec{
+			MachineName: "machine",
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		request             admission.Request
+		expectedType        mrv1.RemediationType
+		expectedTimeouts    *mrv1.RemediationTimeouts
+		expectedStrategies  []mrv1.RemediationStrategy
+		expectedLabels      map[string]string
+		expectedTaintsCount int
+	}{
+		{
+			name:                "with machine remediation without saved metadata",
+			request:             newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot), "user"),
+			expectedType:        mrv1.RemediationTypeReboot,
+			expectedTimeouts:    rebootTimeouts,
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:                "with machine remediation that has saved labels",
+			request:             newRequest(admissionv1beta1.Create, mrWithSavedLabels, "user"),
+			expectedType:        mrv1.RemediationTypeReboot,
+			expectedTimeouts:    rebootTimeouts,
+			expectedLabels:      map[string]string{"saved": "label"},
+			expectedTaintsCount: 1,
+		},
+		{
+			name:                "with requester annotation provided by the user",
+			request:             newRequest(admissionv1beta1.Create, mrFakeRequester, "user"),
+			expectedType:        mrv1.RemediationTypeRecreate,
+			expectedTimeouts:    recreateTimeouts,
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:         "with strategies",
+			request:      newRequest(admissionv1beta1.Create, mrWithStrategies, "user"),
+			expectedType: "",
+			expectedStrategies: []mrv1.RemediationStrategy{
+				{Type: mrv1.RemediationTypeReboot, Timeouts: strategyRebootTimeouts},
+				{Type: mrv1.RemediationTypeRecreate, Timeouts: recreateTimeouts},
+			},
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:                "with v1alpha1 machine remediation without type",
+			request:             newRequest(admissionv1beta1.Create, mrv1alpha1WithoutType, "user"),
+			expectedType:        mrv1.RemediationTypeReboot,
+			expectedTimeouts:    rebootTimeouts,
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:                "with machine remediation created from the template by the MachineHealthCheck",
+			request:             newRequest(admissionv1beta1.Create, mrFromTemplate, "system:serviceaccount:capi-system:capi-manager"),
+			expectedType:        mrv1.RemediationTypeReboot,
+			expectedTimeouts:    rebootTimeouts,
+			expectedLabels:      mrtesting.FooBar(),
+			expectedTaintsCount: 1,
+		},
+		{
+			name:               "with machine selected by the policy",
+			request:            newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machineWithPolicy", ""), "user"),
+			expectedType:       "",
+			expectedStrategies: []mrv1.RemediationStrategy{{Type: mrv1.RemediationTypeRecreate, Timeouts: recreateTimeouts}},
+		},
+		{
+			name:             "with machine selected by the policy with drain",
+			request:          newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machineWithDrainPolicy", mrv1.RemediationTypeReboot), "user"),
+			expectedType:     mrv1.RemediationTypeReboot,
+			expectedTimeouts: defaults.WithDrain(20*time.Minute).Fill(mrv1.RemediationTypeReboot, nil),
+		},
+		{
+			name:             "with machine without node",
+			request:          newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machineWithoutNode", mrv1.RemediationTypeReboot), "user"),
+			expectedType:     mrv1.RemediationTypeReboot,
+			expectedTimeouts: rebootTimeouts,
+		},
+		{
+			name:             "with missing machine",
+			request:          newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "missing", mrv1.RemediationTypeReboot), "user"),
+			expectedType:     mrv1.RemediationTypeReboot,
+			expectedTimeouts: rebootTimeouts,
+		},
+	}
+
+	d := newFakeDefaulter(machine, node, machineWithoutNode, machineWithPolicy, policy, machineWithDrainPolicy, drainPolicy)
+	for _, tc := range testCases {
+		response := d.Handle(context.TODO(), tc.request)
+		if !response.Allowed {
+			t.Errorf("Test case: %s. Expected allowed response, got: %v", tc.name, response.Result)
+			continue
+		}
+
+		mr := applyPatches(t, tc.request, response)
+		if requester := mr.Annotations[consts.AnnotationRequestedBy]; requester != tc.request.UserInfo.Username {
+			t.Errorf("Test case: %s. Expected requester %q, got: %q", tc.name, tc.request.UserInfo.Username, requester)
+		}
+		if mr.Spec.MachineName == "" {
+			t.Errorf("Test case: %s. Expected: the machine name, got: empty", tc.name)
+		}
+		if mr.Spec.Type != tc.expectedType {
+			t.Errorf("Test case: %s. Expected type: %q, got: %q", tc.name, tc.expectedType, mr.Spec.Type)
+		}
+		if !reflect.DeepEqual(mr.Spec.Timeouts, tc.expectedTimeouts) {
+			t.Errorf("Test case: %s. Expected timeouts: %+v, got: %+v", tc.name, tc.expectedTimeouts, mr.Spec.Timeouts)
+		}
+		if !reflect.DeepEqual(mr.Spec.Strategies, tc.expectedStrategies) {
+			t.Errorf("Test case: %s. Expected strategies: %+v, got: %+v", tc.name, tc.expectedStrategies, mr.Spec.Strategies)
+		}
+		if !reflect.DeepEqual(mr.Spec.SavedLabels, tc.expectedLabels) {
+			t.Errorf("Test case: %s. Expected saved labels: %v, got: %v", tc.name, tc.expectedLabels, mr.Spec.SavedLabels)
+		}
+		if len(mr.Spec.SavedTaints) != tc.expectedTaintsCount {
+			t.Errorf("Test case: %s. Expected %d saved taints, got: %v", tc.name, tc.expectedTaintsCount, mr.Spec.SavedTaints)
+		}
+	}
+}
+
+func TestDefaulterHandleUpdate(t *testing.T) {
+	d := newFakeDefaulter()
+	response := d.Handle(context.TODO(), newRequest(admissionv1beta1.Update, newMachineRemediation("mr", "machine", ""), "user"))
+	if !response.Allowed || len(response.Patches) != 0 {
+		t.Errorf("Expected allowed response without patches, got: %+v", response)
+	}
+}
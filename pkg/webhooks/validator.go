@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/utils/machines"
+	"kubevirt.io/machine-remediation/pkg/utils/strategies"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// VerbRemediateControlPlane contains the verb on the machineremediations resource, that authorizes
+	// the requester to create machine remediations of control plane machines
+	VerbRemediateControlPlane = "remediate-control-plane"
+)
+
+var _ admission.Handler = &MachineRemediationValidator{}
+
+// MachineRemediationValidator validates MachineRemediation objects on the creation and the update
+type MachineRemediationValidator struct {
+	client client.Client
+	// reader reads machine remediations directly from the API server, so the remediation
+	// created just before this one counts even before the cache observes it
+	reader  client.Reader
+	decoder *admission.Decoder
+	// serviceAccount contains the user name of the controller service account.
+	// The MachineHealthCheck and node reboot controllers create machine remediations under it.
+	serviceAccount string
+}
+
+// NewMachineRemediationValidator returns new MachineRemediationValidator object.
+// The controller service account remediates control plane machines without the access review.
+func NewMachineRemediationValidator(c client.Client, reader client.Reader, serviceAccount string) *MachineRemediationValidator {
+	return &MachineRemediationValidator{
+		client:         c,
+		reader:         reader,
+		serviceAccount: serviceAccount,
+	}
+}
+
+// InjectDecoder injects the decoder of admission requests
+func (v *MachineRemediationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates the MachineRemediation object of the admission request
+func (v *MachineRemediationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	mr, err := decodeMachineRemediation(v.decoder, req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := validateStrategies(mr); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	// the machine reference of the remediation in progress already passed the validation on the creation
+	if req.Operation != admissionv1beta1.Create {
+		return admission.Allowed("")
+	}
+
+	if mr.Spec.MachineName == "" {
+		return admission.Denied("the machine name is required")
+	}
+
+	if strings.Contains(mr.Spec.MachineName, "/") {
+		return admission.Denied(fmt.Sprintf("the machine %q should be referenced by the name, under the namespace %q of the machine remediation", mr.Spec.MachineName, mr.Namespace))
+	}
+
+	machine := &mapiv1.Machine{}
+	key := types.NamespacedName{
+		Namespace: mr.Namespace,
+		Name:      mr.Spec.MachineName,
+	}
+	if err := v.client.Get(context.TODO(), key, machine); err != nil {
+		if errors.IsNotFound(err) {
+			return admission.Denied(fmt.Sprintf("the machine %q does not exist under the namespace %q", mr.Spec.MachineName, mr.Namespace))
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	activeName, err := v.getActiveMachineRemediation(mr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if activeName != "" {
+		return admission.Denied(fmt.Sprintf("the machine %q already has the machine remediation %q in progress", mr.Spec.MachineName, activeName))
+	}
+
+	if machines.IsControlPlane(machine) {
+		allowed, err := v.isControlPlaneRemediationAllowed(mr, req)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !allowed {
+			return admission.Denied(fmt.Sprintf(
+				"the user %q is not allowed to remediate the control plane machine %q, it requires the %q verb on the machineremediations resource",
+				req.UserInfo.Username,
+				mr.Spec.MachineName,
+				VerbRemediateControlPlane,
+			))
+		}
+	}
+
+	glog.V(4).Infof("MachineRemediation %q of machine %q passed the validation", mr.Name, mr.Spec.MachineName)
+	return admission.Allowed("")
+}
+
+// getActiveMachineRemediation returns the name of other machine remediation of the same machine,
+// that did not reach the final state
+func (v *MachineRemediationValidator) getActiveMachineRemediation(mr *mrv1.MachineRemediation) (string, error) {
+	mrs := &mrv1.MachineRemediationList{}
+	if err := v.reader.List(context.TODO(), mrs, client.InNamespace(mr.Namespace)); err != nil {
+		return "", err
+	}
+
+	for _, other := range mrs.Items {
+		if other.Name == mr.Name || other.Spec.MachineName != mr.Spec.MachineName || other.DeletionTimestamp != nil {
+			continue
+		}
+
+		if other.Status.State != mrv1.RemediationStateSucceeded && other.Status.State != mrv1.RemediationStateFailed {
+			return other.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// isControlPlaneRemediationAllowed returns true when the requester can remediate control plane machines.
+// The controller service account is always allowed.
+func (v *MachineRemediationValidator) isControlPlaneRemediationAllowed(mr *mrv1.MachineRemediation, req admission.Request) (bool, error) {
+	if v.serviceAccount != "" && req.UserInfo.Username == v.serviceAccount {
+		return true, nil
+	}
+
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range req.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   req.UserInfo.Username,
+			Groups: req.UserInfo.Groups,
+			UID:    req.UserInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: mr.Namespace,
+				Verb:      VerbRemediateControlPlane,
+				Group:     mrv1.SchemeGroupVersion.Group,
+				Resource:  "machineremediations",
+				Name:      mr.Name,
+			},
+		},
+	}
+	if err := v.client.Create(context.TODO(), sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// validateStrategies returns an error when the machine remediation does not have strategies,
+// or has a strategy with the unknown remediation type
+func validateStrategies(mr *mrv1.MachineRemediation) error {
+	if mr.Spec.Type == "" && len(mr.Spec.Strategies) == 0 {
+		return fmt.Errorf("the remediation type or strategies are required")
+	}
+
+	if mr.Spec.Type != "" && !isKnownType(mr.Spec.Type) {
+		return fmt.Errorf("unknown remediation type %q", mr.Spec.Type)
+	}
+
+	for _, strategy := range strategies.GetStrategies(mr) {
+		if !isKnownType(strategy.Type) {
+			return fmt.Errorf("unknown remediation type %q of the strategy", strategy.Type)
+		}
+	}
+	return nil
+}
+
+// isKnownType returns true when the remediation type is supported by the controller
+func isKnownType(remediationType mrv1.RemediationType) bool {
+	return remediationType == mrv1.RemediationTypeReboot || remediationType == mrv1.RemediationTypeRecreate
+}
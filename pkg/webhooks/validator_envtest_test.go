@@ -0,0 +1,260 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// machineCRD contains the minimal Machine CRD, the validating webhook only reads machines
+var machineCRD = &apiextensionsv1beta1.CustomResourceDefinition{
+	ObjectMeta: metav1.ObjectMeta{Name: "machines.machine.openshift.io"},
+	Spec: apiextensionsv1beta1.CustomResourceDefinitionSpec{
+		Group:   mapiv1.SchemeGroupVersion.Group,
+		Version: mapiv1.SchemeGroupVersion.Version,
+		Scope:   apiextensionsv1beta1.NamespaceScoped,
+		Names: apiextensionsv1beta1.CustomResourceDefinitionNames{
+			Kind:   "Machine",
+			Plural: "machines",
+		},
+	},
+}
+
+// hasEnvtestAssets returns true when binaries of the envtest control plane are available
+func hasEnvtestAssets() bool {
+	assets := os.Getenv("KUBEBUILDER_ASSETS")
+	if assets == "" {
+		assets = "/usr/local/kubebuilder/bin"
+	}
+	_, err := os.Stat(filepath.Join(assets, "kube-apiserver"))
+	return err == nil
+}
+
+// writeServingCert writes the self-signed serving certificate of the local address under the directory,
+// and returns the certificate in the PEM format
+func writeServingCert(dir string) ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0600); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0600); err != nil {
+		return nil, err
+	}
+	return certPEM, nil
+}
+
+// getFreePort returns the free local port
+func getFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func TestValidatingWebhookEnvtest(t *testing.T) {
+	if !hasEnvtestAssets() {
+		t.Skip("envtest binaries are not available, set KUBEBUILDER_ASSETS to run the test")
+	}
+
+	// the default admission control of envtest disables admission webhooks
+	var apiServerFlags []string
+	for _, flag := range envtest.DefaultKubeAPIServerFlags {
+		if !strings.HasPrefix(flag, "--admission-control") {
+			apiServerFlags = append(apiServerFlags, flag)
+		}
+	}
+	apiServerFlags = append(apiServerFlags, "--enable-admission-plugins=ValidatingAdmissionWebhook")
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:  []string{filepath.Join("..", "..", "manifests", "generated", "crds")},
+		CRDs:               []*apiextensionsv1beta1.CustomResourceDefinition{machineCRD},
+		KubeAPIServerFlags: apiServerFlags,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("failed to start envtest: %v", err)
+	}
+	defer env.Stop()
+
+	certDir, err := ioutil.TempDir("", "machine-remediation-webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(certDir)
+
+	caBundle, err := writeServingCert(certDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port, err := getFreePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mgr, err := manager.New(cfg, manager.Options{
+		Scheme:             scheme.Scheme,
+		MetricsBindAddress: "0",
+		Host:               "127.0.0.1",
+		Port:               port,
+		CertDir:            certDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr.GetWebhookServer().Register(ValidatingWebhookPath, &webhook.Admission{
+		Handler: NewMachineRemediationValidator(mgr.GetClient(), mgr.GetAPIReader(), ""),
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		if err := mgr.Start(stop); err != nil {
+			t.Errorf("failed to start manager: %v", err)
+		}
+	}()
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// point the webhook configuration to the local webhook server
+	config := newValidatingWebhookConfiguration(types.NamespacedName{}, nil)
+	url := fmt.Sprintf("https://127.0.0.1:%d%s", port, ValidatingWebhookPath)
+	config.Webhooks[0].ClientConfig = admissionregistrationv1beta1.WebhookClientConfig{
+		URL:      &url,
+		CABundle: caBundle,
+	}
+	if err := c.Create(context.TODO(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: consts.NamespaceOpenshiftMachineAPI}}
+	if err := c.Create(context.TODO(), namespace); err != nil {
+		t.Fatal(err)
+	}
+
+	machine := mrtesting.NewMachine("machine", "", "")
+	masterMachine := mrtesting.NewMachine("master", "", "")
+	masterMachine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+	for _, m := range []*mapiv1.Machine{machine, masterMachine} {
+		if err := c.Create(context.TODO(), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// wait until the API server calls the webhook, the machine remediation of the missing machine is rejected
+	missing := newMachineRemediation("missing", "missing", mrv1.RemediationTypeReboot)
+	if err := wait.PollImmediate(time.Second, time.Minute, func() (bool, error) {
+		if err := c.Create(context.TODO(), missing.DeepCopy()); err != nil {
+			return true, nil
+		}
+		return false, c.Delete(context.TODO(), missing.DeepCopy())
+	}); err != nil {
+		t.Fatalf("expected the machine remediation of the missing machine to be rejected, got: %v", err)
+	}
+
+	testCases := []struct {
+		name               string
+		machineRemediation *mrv1.MachineRemediation
+		expected           bool
+	}{
+		{
+			name:               "with unknown type",
+			machineRemediation: newMachineRemediation("unknown", "machine", "reinstall"),
+			expected:           false,
+		},
+		{
+			name:               "with cross-namespace machine reference",
+			machineRemediation: newMachineRemediation("cross", "default/machine", mrv1.RemediationTypeReboot),
+			expected:           false,
+		},
+		{
+			name:               "with valid machine remediation",
+			machineRemediation: newMachineRemediation("valid", "machine", mrv1.RemediationTypeReboot),
+			expected:           true,
+		},
+		{
+			name:               "with active machine remediation of the same machine",
+			machineRemediation: newMachineRemediation("duplicate", "machine", mrv1.RemediationTypeRecreate),
+			expected:           false,
+		},
+		{
+			name:               "with control plane machine and authorized user",
+			machineRemediation: newMachineRemediation("master", "master", mrv1.RemediationTypeReboot),
+			expected:           true,
+		},
+	}
+
+	for _, tc := range testCases {
+		err := c.Create(context.TODO(), tc.machineRemediation)
+		if (err == nil) != tc.expected {
+			t.Errorf("Test case: %s. Expected allowed: %t, got error: %v", tc.name, tc.expected, err)
+		}
+
+		// the duplicate check reads machine remediations from the cache of the manager
+		if err == nil {
+			key := types.NamespacedName{Namespace: tc.machineRemediation.Namespace, Name: tc.machineRemediation.Name}
+			if err := wait.PollImmediate(100*time.Millisecond, 10*time.Second, func() (bool, error) {
+				return mgr.GetClient().Get(context.TODO(), key, &mrv1.MachineRemediation{}) == nil, nil
+			}); err != nil {
+				t.Errorf("Test case: %s. Expected the machine remediation under the cache, got: %v", tc.name, err)
+			}
+		}
+	}
+}
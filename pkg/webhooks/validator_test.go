@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mrv1alpha1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+	"kubevirt.io/machine-remediation/pkg/consts"
+	mrtesting "kubevirt.io/machine-remediation/pkg/utils/testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func init() {
+	// Add types to scheme
+	mrv1alpha1.AddToScheme(scheme.Scheme)
+	mrv1.AddToScheme(scheme.Scheme)
+	mapiv1.AddToScheme(scheme.Scheme)
+}
+
+// fakeAccessReviewClient allows or denies subject access reviews of users
+type fakeAccessReviewClient struct {
+	client.Client
+	allowedUsers []string
+}
+
+func (c *fakeAccessReviewClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	sar, ok := obj.(*authorizationv1.SubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	for _, user := range c.allowedUsers {
+		if sar.Spec.User == user && sar.Spec.ResourceAttributes.Verb == VerbRemediateControlPlane {
+			sar.Status.Allowed = true
+		}
+	}
+	return nil
+}
+
+// newFakeValidator returns a new MachineRemediationValidator with a fake client
+func newFakeValidator(allowedUsers []string, initObjects ...runtime.Object) *MachineRemediationValidator {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		panic(err)
+	}
+
+	fakeClient := fake.NewFakeClient(initObjects...)
+	v := NewMachineRemediationValidator(&fakeAccessReviewClient{
+		Client:       fakeClient,
+		allowedUsers: allowedUsers,
+	}, fakeClient, "system:serviceaccount:kube-system:machine-remediation")
+	if err := v.InjectDecoder(decoder); err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// newRequest returns the admission request with the object and the user
+func newRequest(operation admissionv1beta1.Operation, obj runtime.Object, user string) admission.Request {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return admission.Request{
+		AdmissionRequest: admissionv1beta1.AdmissionRequest{
+			Operation: operation,
+			Kind: metav1.GroupVersionKind{
+				Group:   gvk.Group,
+				Version: gvk.Version,
+				Kind:    gvk.Kind,
+			},
+			Object:   runtime.RawExtension{Raw: data},
+			UserInfo: authenticationv1.UserInfo{Username: user},
+		},
+	}
+}
+
+// newMachineRemediation returns the new machine remediation with the v1beta1 API version
+func newMachineRemediation(name string, machineName string, remediationType mrv1.RemediationType) *mrv1.MachineRemediation {
+	mr := mrtesting.NewMachineRemediation(name, machineName, remediationType, "")
+	mr.APIVersion = mrv1.SchemeGroupVersion.String()
+	mr.Status = mrv1.MachineRemediationStatus{}
+	return mr
+}
+
+func TestHandle(t *testing.T) {
+	machine := mrtesting.NewMachine("machine", "node", "")
+	masterMachine := mrtesting.NewMachine("master", "masterNode", "")
+	masterMachine.Labels[consts.MachineRoleLabel] = consts.MachineRoleMaster
+
+	mrActive := newMachineRemediation("mrActive", "activeMachine", mrv1.RemediationTypeReboot)
+	mrActive.Status.State = mrv1.RemediationStatePowerOff
+	mrSucceeded := newMachineRemediation("mrSucceeded", "machine", mrv1.RemediationTypeReboot)
+	mrSucceeded.Status.State = mrv1.RemediationStateSucceeded
+	activeMachine := mrtesting.NewMachine("activeMachine", "activeNode", "")
+
+	mrWithStrategies := newMachineRemediation("mrWithStrategies", "machine", "")
+	mrWithStrategies.Spec.Strategies = []mrv1.RemediationStrategy{
+		{Type: mrv1.RemediationTypeReboot},
+		{Type: mrv1.RemediationTypeRecreate},
+	}
+	mrWithUnknownStrategy := mrWithStrategies.DeepCopy()
+	mrWithUnknownStrategy.Spec.Strategies[1].Type = "reinstall"
+
+	mrv1alpha1Unknown := &mrv1alpha1.MachineRemediation{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: mrv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "MachineRemediation",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "mrv1alpha1", Namespace: consts.NamespaceOpenshiftMachineAPI},
+		Spec: mrv1alpha1.MachineRemediationSpec{
+			Type:        "reinstall",
+			MachineName: "machine",
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		request  admission.Request
+		expected bool
+	}{
+		{
+			name:     "with valid machine remediation",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machine", mrv1.RemediationTypeReboot), "user"),
+			expected: true,
+		},
+		{
+			name:     "with valid strategies",
+			request:  newRequest(admissionv1beta1.Create, mrWithStrategies, "user"),
+			expected: true,
+		},
+		{
+			name:     "with unknown type",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machine", "reinstall"), "user"),
+			expected: false,
+		},
+		{
+			name:     "with unknown v1alpha1 type",
+			request:  newRequest(admissionv1beta1.Create, mrv1alpha1Unknown, "user"),
+			expected: false,
+		},
+		{
+			name:     "with unknown strategy type",
+			request:  newRequest(admissionv1beta1.Create, mrWithUnknownStrategy, "user"),
+			expected: false,
+		},
+		{
+			name:     "with unknown strategy type on update",
+			request:  newRequest(admissionv1beta1.Update, mrWithUnknownStrategy, "user"),
+			expected: false,
+		},
+		{
+			name:     "without type",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "machine", ""), "user"),
+			expected: false,
+		},
+		{
+			name:     "with missing machine",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "missing", mrv1.RemediationTypeReboot), "user"),
+			expected: false,
+		},
+		{
+			name:     "with cross-namespace machine reference",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "default/machine", mrv1.RemediationTypeReboot), "user"),
+			expected: false,
+		},
+		{
+			name:     "with active machine remediation of the same machine",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "activeMachine", mrv1.RemediationTypeReboot), "user"),
+			expected: false,
+		},
+		{
+			name:     "with update of the active machine remediation",
+			request:  newRequest(admissionv1beta1.Update, mrActive, "user"),
+			expected: true,
+		},
+		{
+			name:     "with control plane machine and unauthorized user",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "master", mrv1.RemediationTypeReboot), "user"),
+			expected: false,
+		},
+		{
+			name:     "with control plane machine and authorized user",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "master", mrv1.RemediationTypeReboot), "admin"),
+			expected: true,
+		},
+		{
+			name:     "with control plane machine and controller service account",
+			request:  newRequest(admissionv1beta1.Create, newMachineRemediation("mr", "master", mrv1.RemediationTypeReboot), "system:serviceaccount:kube-system:machine-remediation"),
+			expected: true,
+		},
+	}
+
+	v := newFakeValidator([]string{"admin"}, machine, masterMachine, activeMachine, mrActive, mrSucceeded)
+	for _, tc := range testCases {
+		response := v.Handle(context.TODO(), tc.request)
+		if response.Allowed != tc.expected {
+			t.Errorf("Test case: %s. Expected allowed: %t, got: %t, with result: %v", tc.name, tc.expected, response.Allowed, response.Result)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/golang/glog"
+
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ValidatingWebhookPath contains the path that the MachineRemediation validating webhook serves
+	ValidatingWebhookPath = "/validate-machineremediation"
+	// ValidatingWebhookConfigurationName contains the name of the validating webhook configuration
+	// of the MachineRemediation objects
+	ValidatingWebhookConfigurationName = "machine-remediation"
+	// annotationInjectCABundle contains the annotation that makes the OpenShift service CA operator
+	// to inject the CA bundle into client configs of the webhook configuration
+	annotationInjectCABundle = "service.beta.openshift.io/inject-cabundle"
+	// validatingWebhookName contains the name of the MachineRemediation validating webhook
+	validatingWebhookName = "validating.machineremediations.machineremediation.kubevirt.io"
+	// MutatingWebhookPath contains the path that the MachineRemediation mutating webhook serves
+	MutatingWebhookPath = "/mutate-machineremediation"
+	// MutatingWebhookConfigurationName contains the name of the mutating webhook configuration
+	// of the MachineRemediation objects
+	MutatingWebhookConfigurationName = "machine-remediation"
+	// mutatingWebhookName contains the name of the MachineRemediation mutating webhook
+	mutatingWebhookName = "mutating.machineremediations.machineremediation.kubevirt.io"
+)
+
+// EnsureValidatingWebhook creates or updates the validating webhook configuration, that makes the API server
+// to validate MachineRemediation objects by the webhook behind the service
+func EnsureValidatingWebhook(c client.Client, service types.NamespacedName) error {
+	config := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{}
+	key := client.ObjectKey{Name: ValidatingWebhookConfigurationName}
+	if err := c.Get(context.TODO(), key, config); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		config = newValidatingWebhookConfiguration(service, nil)
+		glog.Infof("Create the validating webhook configuration %q with service %q", config.Name, service)
+		return c.Create(context.TODO(), config)
+	}
+
+	// keep the CA bundle that was injected by the service CA operator
+	var caBundle []byte
+	if len(config.Webhooks) != 0 {
+		caBundle = config.Webhooks[0].ClientConfig.CABundle
+	}
+
+	// Copy the validating webhook configuration object to prevent modification of the original one
+	configCopy := config.DeepCopy()
+	expected := newValidatingWebhookConfiguration(service, caBundle)
+	if configCopy.Annotations == nil {
+		configCopy.Annotations = map[string]string{}
+	}
+	configCopy.Annotations[annotationInjectCABundle] = "true"
+	configCopy.Webhooks = expected.Webhooks
+	if reflect.DeepEqual(config, configCopy) {
+		return nil
+	}
+
+	glog.Infof("Update the validating webhook configuration %q with service %q", config.Name, service)
+	return c.Update(context.TODO(), configCopy)
+}
+
+// newValidatingWebhookConfiguration returns the validating webhook configuration of MachineRemediation objects
+func newValidatingWebhookConfiguration(service types.NamespacedName, caBundle []byte) *admissionregistrationv1beta1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1beta1.Fail
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	return &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ValidatingWebhookConfigurationName,
+			Annotations: map[string]string{
+				annotationInjectCABundle: "true",
+			},
+		},
+		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{
+			{
+				Name: validatingWebhookName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Namespace: service.Namespace,
+						Name:      service.Name,
+						Path:      pointer.StringPtr(ValidatingWebhookPath),
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+							admissionregistrationv1beta1.Update,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{mrv1.SchemeGroupVersion.Group},
+							APIVersions: []string{"*"},
+							Resources:   []string{"machineremediations"},
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1beta1"},
+			},
+		},
+	}
+}
+
+// EnsureMutatingWebhook creates or updates the mutating webhook configuration, that makes the API server
+// to fill defaults of MachineRemediation objects by the webhook behind the service
+func EnsureMutatingWebhook(c client.Client, service types.NamespacedName) error {
+	config := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+	key := client.ObjectKey{Name: MutatingWebhookConfigurationName}
+	if err := c.Get(context.TODO(), key, config); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		config = newMutatingWebhookConfiguration(service, nil)
+		glog.Infof("Create the mutating webhook configuration %q with service %q", config.Name, service)
+		return c.Create(context.TODO(), config)
+	}
+
+	// keep the CA bundle that was injected by the service CA operator
+	var caBundle []byte
+	if len(config.Webhooks) != 0 {
+		caBundle = config.Webhooks[0].ClientConfig.CABundle
+	}
+
+	// Copy the mutating webhook configuration object to prevent modification of the original one
+	configCopy := config.DeepCopy()
+	expected := newMutatingWebhookConfiguration(service, caBundle)
+	if configCopy.Annotations == nil {
+		configCopy.Annotations = map[string]string{}
+	}
+	configCopy.Annotations[annotationInjectCABundle] = "true"
+	configCopy.Webhooks = expected.Webhooks
+	if reflect.DeepEqual(config, configCopy) {
+		return nil
+	}
+
+	glog.Infof("Update the mutating webhook configuration %q with service %q", config.Name, service)
+	return c.Update(context.TODO(), configCopy)
+}
+
+// newMutatingWebhookConfiguration returns the mutating webhook configuration of MachineRemediation objects
+func newMutatingWebhookConfiguration(service types.NamespacedName, caBundle []byte) *admissionregistrationv1beta1.MutatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1beta1.Fail
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	return &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: MutatingWebhookConfigurationName,
+			Annotations: map[string]string{
+				annotationInjectCABundle: "true",
+			},
+		},
+		Webhooks: []admissionregistrationv1beta1.MutatingWebhook{
+			{
+				Name: mutatingWebhookName,
+				ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+					Service: &admissionregistrationv1beta1.ServiceReference{
+						Namespace: service.Namespace,
+						Name:      service.Name,
+						Path:      pointer.StringPtr(MutatingWebhookPath),
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admissionregistrationv1beta1.RuleWithOperations{
+					{
+						Operations: []admissionregistrationv1beta1.OperationType{
+							admissionregistrationv1beta1.Create,
+						},
+						Rule: admissionregistrationv1beta1.Rule{
+							APIGroups:   []string{mrv1.SchemeGroupVersion.Group},
+							APIVersions: []string{"*"},
+							Resources:   []string{"machineremediations"},
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1beta1"},
+			},
+		},
+	}
+}
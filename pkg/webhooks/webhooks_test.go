@@ -0,0 +1,124 @@
+package webhooks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestEnsureValidatingWebhook(t *testing.T) {
+	service := types.NamespacedName{Namespace: "default", Name: "webhook"}
+
+	injected := newValidatingWebhookConfiguration(types.NamespacedName{Namespace: "old", Name: "webhook"}, []byte("ca"))
+	injected.Annotations = nil
+
+	testCases := []struct {
+		name             string
+		objects          []runtime.Object
+		expectedCABundle []byte
+	}{
+		{
+			name:             "without validating webhook configuration",
+			objects:          []runtime.Object{},
+			expectedCABundle: nil,
+		},
+		{
+			name:             "with validating webhook configuration that has injected CA bundle",
+			objects:          []runtime.Object{injected},
+			expectedCABundle: []byte("ca"),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(tc.objects...)
+		if err := EnsureValidatingWebhook(c, service); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		config := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: ValidatingWebhookConfigurationName}, config); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+			continue
+		}
+
+		if config.Annotations[annotationInjectCABundle] != "true" {
+			t.Errorf("%s failed, expected validating webhook configuration to have %q annotation", tc.name, annotationInjectCABundle)
+		}
+
+		if len(config.Webhooks) != 1 {
+			t.Errorf("%s failed, expected one webhook, got: %d", tc.name, len(config.Webhooks))
+			continue
+		}
+
+		clientConfig := config.Webhooks[0].ClientConfig
+		if clientConfig.Service == nil || clientConfig.Service.Name != service.Name || clientConfig.Service.Namespace != service.Namespace {
+			t.Errorf("%s failed, expected service %q, got: %v", tc.name, service, clientConfig.Service)
+		}
+
+		if !reflect.DeepEqual(clientConfig.CABundle, tc.expectedCABundle) {
+			t.Errorf("%s failed, expected CA bundle: %q, got: %q", tc.name, tc.expectedCABundle, clientConfig.CABundle)
+		}
+	}
+}
+
+func TestEnsureMutatingWebhook(t *testing.T) {
+	service := types.NamespacedName{Namespace: "default", Name: "webhook"}
+
+	injected := newMutatingWebhookConfiguration(types.NamespacedName{Namespace: "old", Name: "webhook"}, []byte("ca"))
+	injected.Annotations = nil
+
+	testCases := []struct {
+		name             string
+		objects          []runtime.Object
+		expectedCABundle []byte
+	}{
+		{
+			name:             "without mutating webhook configuration",
+			objects:          []runtime.Object{},
+			expectedCABundle: nil,
+		},
+		{
+			name:             "with mutating webhook configuration that has injected CA bundle",
+			objects:          []runtime.Object{injected},
+			expectedCABundle: []byte("ca"),
+		},
+	}
+
+	for _, tc := range testCases {
+		c := fake.NewFakeClient(tc.objects...)
+		if err := EnsureMutatingWebhook(c, service); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+		}
+
+		config := &admissionregistrationv1beta1.MutatingWebhookConfiguration{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Name: MutatingWebhookConfigurationName}, config); err != nil {
+			t.Errorf("%s failed, expected no error, got: %v", tc.name, err)
+			continue
+		}
+
+		if config.Annotations[annotationInjectCABundle] != "true" {
+			t.Errorf("%s failed, expected mutating webhook configuration to have %q annotation", tc.name, annotationInjectCABundle)
+		}
+
+		if len(config.Webhooks) != 1 {
+			t.Errorf("%s failed, expected one webhook, got: %d", tc.name, len(config.Webhooks))
+			continue
+		}
+
+		clientConfig := config.Webhooks[0].ClientConfig
+		if clientConfig.Service == nil || clientConfig.Service.Name != service.Name || *clientConfig.Service.Path != MutatingWebhookPath {
+			t.Errorf("%s failed, expected service %q with path %q, got: %v", tc.name, service, MutatingWebhookPath, clientConfig.Service)
+		}
+
+		if !reflect.DeepEqual(clientConfig.CABundle, tc.expectedCABundle) {
+			t.Errorf("%s failed, expected CA bundle: %q, got: %q", tc.name, tc.expectedCABundle, clientConfig.CABundle)
+		}
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 	"k8s.io/client-go/kubernetes/scheme"
 
-	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1alpha1"
+	mrv1 "kubevirt.io/machine-remediation/pkg/apis/machineremediation/v1beta1"
 	testsutils "kubevirt.io/machine-remediation/tests/utils"
 
 	mapiv1 "sigs.k8s.io/cluster-api/pkg/apis/machine/v1beta1"
@@ -68,6 +68,10 @@ func main() {
 		}
 		deploy := components.NewDeployment(deployData)
 		utils.MarshallObject(deploy, os.Stdout)
+
+		// create service for the machine-remediation webhook server
+		svc := components.NewWebhookService(*resourceType, *namespace)
+		utils.MarshallObject(svc, os.Stdout)
 	default:
 		panic(fmt.Errorf("unknown resource type %s", *resourceType))
 	}